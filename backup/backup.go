@@ -0,0 +1,477 @@
+// Package backup snapshots a Ghost site into a directory or a .tar.gz file and restores
+// snapshots on the same or another site.
+//
+// A backup holds posts, pages, tags, members, newsletters, tiers, settings, redirects, routes.yaml,
+// the active theme and every image the content references from the site. manifest.json records
+// the format version, so newer releases can still read old backups.
+//
+// Restore matches existing content by slug (members by email) and only creates what is missing,
+// so running it again after a failure continues where it stopped. IDs of tags, newsletters and
+// tiers are remapped to the ones on the target, image and site URLs are rewritten.
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/siteimage"
+)
+
+// FormatVersion is the version of the backup layout written by Create
+const FormatVersion = 1
+
+// Files of a backup
+const (
+	ManifestFile    = "manifest.json"
+	PostsFile       = "posts.json"
+	PagesFile       = "pages.json"
+	TagsFile        = "tags.json"
+	MembersFile     = "members.json"
+	NewslettersFile = "newsletters.json"
+	TiersFile       = "tiers.json"
+	SettingsFile    = "settings.json"
+	RedirectsFile   = "redirects.json"
+	RoutesFile      = "routes.yaml"
+	ImagesFile      = "images.json" // maps image URLs to files in ImagesDir
+	ImagesDir       = "images"
+	ThemeDir        = "theme"
+)
+
+// Manifest describes a backup
+type Manifest struct {
+	Format       int       `json:"format"`
+	CreatedAt    time.Time `json:"created_at"`
+	Site         string    `json:"site"` // URL of the backed up site
+	GhostVersion string    `json:"ghost_version"`
+	// Theme is the active theme, stored as ThemeDir/<name>.zip
+	Theme  string         `json:"theme,omitempty"`
+	Counts map[string]int `json:"counts"`
+	// MissingImages are referenced images that couldn't be downloaded, by URL
+	MissingImages map[string]string `json:"missing_images,omitempty"`
+}
+
+// Source is the part of *ghost.Ghost Create needs
+type Source interface {
+	Capabilities() (ghost.Capabilities, error)
+	AdminGetSite() (ghost.Site, error)
+	AdminGetPosts() (ghost.Posts, error)
+	AdminGetPages() (ghost.Pages, error)
+	AdminGetTags() (ghost.Tags, error)
+	AdminGetMembers() (ghost.Members, error)
+	AdminGetNewsletters() (ghost.Newsletters, error)
+	AdminGetTiers() (ghost.Tiers, error)
+	AdminGetSettings() (ghost.AdminSettings, error)
+	AdminGetRedirects(ctx context.Context) ([]ghost.Redirect, error)
+	AdminDownloadRoutes(ctx context.Context, w io.Writer) error
+	AdminGetThemes() (ghost.Themes, error)
+	AdminDownloadTheme(ctx context.Context, name string, w io.Writer) error
+}
+
+type options struct {
+	members   bool
+	images    bool
+	theme     bool
+	fetcher   ghost.Fetcher
+	statePath string
+	authors   map[string]string
+}
+
+type Option func(o *options)
+
+// WithMembers turns backing up and restoring members on or off, on by default
+func WithMembers(enabled bool) Option {
+	return func(o *options) {
+		o.members = enabled
+	}
+}
+
+// WithImages turns backing up and restoring images on or off, on by default.
+// Without images, restored content keeps pointing to the images of the backed up site.
+func WithImages(enabled bool) Option {
+	return func(o *options) {
+		o.images = enabled
+	}
+}
+
+// WithTheme turns backing up and restoring the active theme on or off, on by default
+func WithTheme(enabled bool) Option {
+	return func(o *options) {
+		o.theme = enabled
+	}
+}
+
+// WithFetcher overrides how Create downloads images, by default they are fetched over HTTP
+func WithFetcher(fetcher ghost.Fetcher) Option {
+	return func(o *options) {
+		o.fetcher = fetcher
+	}
+}
+
+// WithStateFile overrides where Restore remembers uploaded images, by default next to the
+// backup in "<backup>.restore.json"
+func WithStateFile(path string) Option {
+	return func(o *options) {
+		o.statePath = path
+	}
+}
+
+// WithAuthors maps author emails of the backup to staff emails on the target. Staff users can't
+// be created with the Admin API, so authors must exist on the target; unmapped authors keep their email.
+func WithAuthors(emails map[string]string) Option {
+	return func(o *options) {
+		o.authors = emails
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{members: true, images: true, theme: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.fetcher == nil {
+		o.fetcher = ghost.HTTPFetcher(&http.Client{Timeout: 5 * time.Minute})
+	}
+	return o
+}
+
+// Create writes a backup of src to dest, a new or empty directory, or a file ending in
+// .tar.gz or .tgz. Images that can't be downloaded are listed in the manifest instead of
+// failing the backup.
+func Create(ctx context.Context, src Source, dest string, opts ...Option) (Manifest, error) {
+	o := newOptions(opts)
+
+	dir := dest
+	if isTarball(dest) {
+		tmp, err := os.MkdirTemp("", "ghost-backup-")
+		if err != nil {
+			return Manifest{}, err
+		}
+		defer os.RemoveAll(tmp)
+		dir = tmp
+	} else if err := emptyDir(dest); err != nil {
+		return Manifest{}, err
+	}
+
+	b := &backup{ctx: ctx, src: src, dir: dir, opts: o, images: map[string]string{}}
+	manifest, err := b.run()
+	if err != nil {
+		return manifest, err
+	}
+	if isTarball(dest) {
+		if err := packTarball(dir, dest); err != nil {
+			return manifest, err
+		}
+	}
+	return manifest, nil
+}
+
+// ReadManifest reads the manifest of the backup in the directory or tarball at path
+func ReadManifest(path string) (Manifest, error) {
+	dir, cleanup, err := openBackup(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	defer cleanup()
+	return readManifest(dir)
+}
+
+type backup struct {
+	ctx      context.Context
+	src      Source
+	dir      string
+	opts     options
+	manifest Manifest
+	siteURL  string
+	// images maps referenced image URLs of the site to their path below ImagesDir
+	images map[string]string
+}
+
+func (b *backup) run() (Manifest, error) {
+	capabilities, err := b.src.Capabilities()
+	if err != nil {
+		return Manifest{}, err
+	}
+	site, err := b.src.AdminGetSite()
+	if err != nil {
+		return Manifest{}, err
+	}
+	b.siteURL = strings.TrimSuffix(site.URL, "/") + "/"
+	b.manifest = Manifest{
+		Format:       FormatVersion,
+		CreatedAt:    time.Now().UTC(),
+		Site:         b.siteURL,
+		GhostVersion: capabilities.Version.String(),
+		Counts:       map[string]int{},
+	}
+
+	posts, err := b.src.AdminGetPosts()
+	if err != nil {
+		return b.manifest, fmt.Errorf("posts: %w", err)
+	}
+	for _, post := range posts.Posts {
+		urls, err := post.ImageURLs()
+		if err != nil {
+			return b.manifest, fmt.Errorf("post %s: %w", post.Slug, err)
+		}
+		b.reference(urls...)
+	}
+	if err := b.write(PostsFile, posts.Posts, len(posts.Posts)); err != nil {
+		return b.manifest, err
+	}
+
+	pages, err := b.src.AdminGetPages()
+	if err != nil {
+		return b.manifest, fmt.Errorf("pages: %w", err)
+	}
+	for _, page := range pages.Pages {
+		urls, err := page.ImageURLs()
+		if err != nil {
+			return b.manifest, fmt.Errorf("page %s: %w", page.Slug, err)
+		}
+		b.reference(urls...)
+	}
+	if err := b.write(PagesFile, pages.Pages, len(pages.Pages)); err != nil {
+		return b.manifest, err
+	}
+
+	tags, err := b.src.AdminGetTags()
+	if err != nil {
+		return b.manifest, fmt.Errorf("tags: %w", err)
+	}
+	for _, tag := range tags.Tags {
+		b.reference(tag.FeatureImage, tag.TwitterImage)
+	}
+	if err := b.write(TagsFile, tags.Tags, len(tags.Tags)); err != nil {
+		return b.manifest, err
+	}
+
+	if capabilities.Newsletters {
+		newsletters, err := b.src.AdminGetNewsletters()
+		if err != nil {
+			return b.manifest, fmt.Errorf("newsletters: %w", err)
+		}
+		for _, newsletter := range newsletters.Newsletters {
+			b.reference(newsletter.HeaderImage)
+		}
+		if err := b.write(NewslettersFile, newsletters.Newsletters, len(newsletters.Newsletters)); err != nil {
+			return b.manifest, err
+		}
+	}
+	if capabilities.Tiers {
+		tiers, err := b.src.AdminGetTiers()
+		if err != nil {
+			return b.manifest, fmt.Errorf("tiers: %w", err)
+		}
+		if err := b.write(TiersFile, tiers.Tiers, len(tiers.Tiers)); err != nil {
+			return b.manifest, err
+		}
+	}
+	if b.opts.members {
+		members, err := b.src.AdminGetMembers()
+		if err != nil {
+			return b.manifest, fmt.Errorf("members: %w", err)
+		}
+		if err := b.write(MembersFile, members.Members, len(members.Members)); err != nil {
+			return b.manifest, err
+		}
+	}
+
+	settings, err := b.src.AdminGetSettings()
+	if err != nil {
+		return b.manifest, fmt.Errorf("settings: %w", err)
+	}
+	b.reference(settings.Logo, settings.Icon, settings.CoverImage, settings.OGImage, settings.TwitterImage)
+	if err := b.write(SettingsFile, settingsFile{AdminSettings: settings, Other: settings.Other}, -1); err != nil {
+		return b.manifest, err
+	}
+
+	redirects, err := b.src.AdminGetRedirects(b.ctx)
+	if err != nil {
+		return b.manifest, fmt.Errorf("redirects: %w", err)
+	}
+	data, err := ghost.MarshalRedirects(redirects)
+	if err != nil {
+		return b.manifest, err
+	}
+	if err := b.writeFile(RedirectsFile, data); err != nil {
+		return b.manifest, err
+	}
+	b.manifest.Counts["redirects"] = len(redirects)
+
+	var routes bytes.Buffer
+	if err := b.src.AdminDownloadRoutes(b.ctx, &routes); err != nil {
+		return b.manifest, fmt.Errorf("routes: %w", err)
+	}
+	if err := b.writeFile(RoutesFile, routes.Bytes()); err != nil {
+		return b.manifest, err
+	}
+
+	if b.opts.theme {
+		if err := b.theme(); err != nil {
+			return b.manifest, fmt.Errorf("theme: %w", err)
+		}
+	}
+	if b.opts.images {
+		if err := b.downloadImages(); err != nil {
+			return b.manifest, err
+		}
+	}
+
+	data, err = json.MarshalIndent(b.manifest, "", "  ")
+	if err != nil {
+		return b.manifest, err
+	}
+	return b.manifest, b.writeFile(ManifestFile, data)
+}
+
+// settingsFile keeps the untyped settings next to the typed ones
+type settingsFile struct {
+	ghost.AdminSettings
+	Other map[string]json.RawMessage `json:"other,omitempty"`
+}
+
+// write stores v as JSON and counts its entries in the manifest
+func (b *backup) write(name string, v interface{}, count int) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	if count >= 0 {
+		b.manifest.Counts[strings.TrimSuffix(name, ".json")] = count
+	}
+	return b.writeFile(name, data)
+}
+
+func (b *backup) writeFile(name string, data []byte) error {
+	file := filepath.Join(b.dir, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(file, data, 0644)
+}
+
+// reference remembers the images hosted by the site, resized variants share the file of the original
+func (b *backup) reference(urls ...string) {
+	for _, rawURL := range urls {
+		if file, _, ok := siteimage.File(b.siteURL, rawURL); ok {
+			b.images[rawURL] = file
+		}
+	}
+}
+
+func (b *backup) downloadImages() error {
+	downloaded := map[string]error{}
+	index := map[string]string{}
+	for rawURL, file := range b.images {
+		if err := b.ctx.Err(); err != nil {
+			return err
+		}
+		err, done := downloaded[file]
+		if !done {
+			err = b.downloadImage(file)
+			downloaded[file] = err
+		}
+		if err != nil {
+			if b.manifest.MissingImages == nil {
+				b.manifest.MissingImages = map[string]string{}
+			}
+			b.manifest.MissingImages[rawURL] = err.Error()
+			continue
+		}
+		index[rawURL] = file
+	}
+	var files int
+	for _, err := range downloaded {
+		if err == nil {
+			files++
+		}
+	}
+	b.manifest.Counts["images"] = files
+	return b.write(ImagesFile, index, -1)
+}
+
+// downloadImage fetches the original of the image stored at file below the site's content/images
+func (b *backup) downloadImage(file string) error {
+	body, _, err := b.opts.fetcher.Fetch(b.ctx, b.siteURL+siteimage.Path+file)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	target := filepath.Join(b.dir, ImagesDir, filepath.FromSlash(file))
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	out, err := os.Create(target)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+func (b *backup) theme() error {
+	themes, err := b.src.AdminGetThemes()
+	if err != nil {
+		return err
+	}
+	for _, theme := range themes.Themes {
+		if !theme.Active {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := b.src.AdminDownloadTheme(b.ctx, theme.Name, &buf); err != nil {
+			return err
+		}
+		b.manifest.Theme = theme.Name
+		return b.writeFile(path.Join(ThemeDir, theme.Name+".zip"), buf.Bytes())
+	}
+	return nil
+}
+
+func readManifest(dir string) (Manifest, error) {
+	var manifest Manifest
+	data, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, fmt.Errorf("%s is not a backup: %s is missing", dir, ManifestFile)
+	}
+	if err != nil {
+		return manifest, err
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return manifest, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	if manifest.Format < 1 || manifest.Format > FormatVersion {
+		return manifest, fmt.Errorf("backup format %d is not supported, this version reads formats 1 to %d", manifest.Format, FormatVersion)
+	}
+	return manifest, nil
+}
+
+// emptyDir creates dir or makes sure it is empty, so backups never mix
+func emptyDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return os.MkdirAll(dir, 0755)
+	}
+	if err != nil {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("backup directory %s is not empty", dir)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package backup
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/fakesite"
+)
+
+var (
+	_ Source = (*ghost.Ghost)(nil)
+	_ Target = (*ghost.Ghost)(nil)
+)
+
+func newSourceSite() *fakesite.Site {
+	src := fakesite.New("https://old.example.com")
+	src.Images["https://old.example.com/content/images/2023/01/cat.jpg"] = []byte("cat")
+	src.Images["https://old.example.com/content/images/2023/01/logo.png"] = []byte("logo")
+
+	news := ghost.Tag{Id: fakesite.NextID(), Name: "News", Slug: "news"}
+	src.Tags = []ghost.Tag{news}
+	src.Newsletters = []ghost.Newsletter{{Id: fakesite.NextID(), Name: "Weekly", Slug: "weekly"}}
+	gold := ghost.Tier{Id: fakesite.NextID(), Name: "Gold", Slug: "gold", Type: "paid", MonthlyPrice: 500, Currency: "usd"}
+	src.Tiers = append(src.Tiers, gold)
+	src.Posts = []ghost.Post{{
+		ID:    fakesite.NextID(),
+		Slug:  "hello",
+		Title: "Hello",
+		Lexical: `{"root":{"children":[` +
+			`{"type":"image","version":1,"src":"https://old.example.com/content/images/2023/01/cat.jpg"},` +
+			`{"type":"html","version":1,"html":"<img src=\"https://old.example.com/content/images/size/w600/2023/01/cat.jpg\"><a href=\"https://old.example.com/about/\">about</a>"}` +
+			`],"direction":null,"format":"","indent":0,"type":"root","version":1}}`,
+		HTML:         "<p>rendered</p>",
+		FeatureImage: "https://old.example.com/content/images/2023/01/gone.jpg",
+		Status:       "published",
+		PublishedAt:  "2023-01-02T03:04:05.000Z",
+		Tags:         []ghost.Tag{news},
+		Authors:      []ghost.Author{{ID: "author-1", Email: "old@example.com"}},
+	}}
+	src.Pages = []ghost.Page{{ID: fakesite.NextID(), Slug: "about", Title: "About", HTML: "<p>About</p>"}}
+	src.Members = []ghost.Member{
+		{Id: fakesite.NextID(), Email: "reader@example.com", Name: "Reader", Newsletters: []ghost.Newsletter{src.Newsletters[0]}},
+		{Id: fakesite.NextID(), Email: "vip@example.com", Status: string(ghost.MemberStatusComped), Tiers: []ghost.Tier{gold}},
+	}
+	src.Settings = ghost.AdminSettings{
+		Title:      "Old",
+		Logo:       "https://old.example.com/content/images/2023/01/logo.png",
+		Navigation: []ghost.NavigationItem{{Label: "About", URL: "https://old.example.com/about/"}},
+	}
+	src.Redirects = []ghost.Redirect{{From: "^/old/?$", To: "/hello/", Permanent: true}}
+	src.Routes = "routes:\n  /feed/: feed\ncollections:\n  /:\n    permalink: /{slug}/\n"
+	src.Themes["mytheme"] = []byte("theme zip")
+	src.ActiveTheme = "mytheme"
+	return src
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	src := newSourceSite()
+	archive := filepath.Join(t.TempDir(), "site.tar.gz")
+
+	manifest, err := Create(ctx, src, archive, WithFetcher(src.Fetcher()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Format != FormatVersion || manifest.Theme != "mytheme" || manifest.Counts["posts"] != 1 || manifest.Counts["images"] != 2 {
+		t.Fatalf("unexpected manifest %+v", manifest)
+	}
+	if _, missing := manifest.MissingImages["https://old.example.com/content/images/2023/01/gone.jpg"]; !missing {
+		t.Fatalf("gone.jpg should be missing: %v", manifest.MissingImages)
+	}
+	if read, err := ReadManifest(archive); err != nil || read.Site != "https://old.example.com/" {
+		t.Fatalf("ReadManifest = %+v, %v", read, err)
+	}
+
+	dst := fakesite.New("https://new.example.com")
+	report, err := Restore(ctx, dst, archive, WithAuthors(map[string]string{"old@example.com": "new@example.com"}))
+	if err != nil {
+		t.Fatalf("%v: %v", err, report.Failed())
+	}
+
+	if dst.Uploads != 2 {
+		t.Errorf("uploaded %d images, want 2", dst.Uploads)
+	}
+	post := dst.Posts[0]
+	for _, want := range []string{
+		`"src":"https://new.example.com/content/images/2024/05/`,
+		`src=\"https://new.example.com/content/images/size/w600/2024/05/`,
+		`href=\"https://new.example.com/about/\"`,
+	} {
+		if !strings.Contains(post.Lexical, want) {
+			t.Errorf("lexical lacks %s: %s", want, post.Lexical)
+		}
+	}
+	if post.HTML != "" || post.ID == src.Posts[0].ID || post.PublishedAt != src.Posts[0].PublishedAt || post.Status != "published" {
+		t.Errorf("unexpected post %+v", post)
+	}
+	if post.FeatureImage != src.Posts[0].FeatureImage {
+		t.Errorf("missing image should keep its URL, got %s", post.FeatureImage)
+	}
+	if len(post.Tags) != 1 || post.Tags[0].Id != dst.Tags[0].Id || post.Tags[0].Id == src.Tags[0].Id {
+		t.Errorf("tag not remapped: %+v", post.Tags)
+	}
+	if len(post.Authors) != 1 || post.Authors[0] != (ghost.Author{Email: "new@example.com"}) {
+		t.Errorf("unexpected authors %+v", post.Authors)
+	}
+	if len(dst.Members) != 2 || dst.Members[0].Newsletters[0].Id != dst.Newsletters[0].Id {
+		t.Errorf("newsletter not remapped: %+v", dst.Members)
+	}
+	if dst.Members[1].StatusOf() != ghost.MemberStatusComped || dst.Members[1].Tiers[0].Id != dst.Tiers[1].Id {
+		t.Errorf("comped tier not restored: %+v", dst.Members[1])
+	}
+	if !strings.HasPrefix(dst.Settings.Logo, "https://new.example.com/content/images/2024/05/") ||
+		dst.Settings.Navigation[0].URL != "https://new.example.com/about/" {
+		t.Errorf("settings not rewritten: %+v", dst.Settings)
+	}
+	if dst.ActiveTheme != "mytheme" || dst.Routes != src.Routes || len(dst.Redirects) != 1 {
+		t.Errorf("theme %s, routes %q, redirects %v", dst.ActiveTheme, dst.Routes, dst.Redirects)
+	}
+
+	// a second run finds everything in place
+	report, err = Restore(ctx, dst, archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, item := range report.Items {
+		if item.Action != Skipped {
+			t.Errorf("second run: %s", item)
+		}
+	}
+	if dst.Uploads != 2 || len(dst.Posts) != 1 || len(dst.Tags) != 1 || len(dst.Members) != 2 {
+		t.Errorf("second run created content: %d uploads, %d posts, %d tags, %d members", dst.Uploads, len(dst.Posts), len(dst.Tags), len(dst.Members))
+	}
+}
+
+func TestCreateRefusesNonEmptyDirectory(t *testing.T) {
+	src := newSourceSite()
+	dir := t.TempDir()
+	if _, err := Create(context.Background(), src, dir, WithFetcher(src.Fetcher()), WithMembers(false)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Create(context.Background(), src, dir, WithFetcher(src.Fetcher())); err == nil {
+		t.Fatal("expected an error for a non-empty directory")
+	}
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := manifest.Counts["members"]; ok {
+		t.Errorf("members were backed up: %v", manifest.Counts)
+	}
+}
+
+func TestReadManifestRejectsNewerFormat(t *testing.T) {
+	dir := t.TempDir()
+	b := &backup{dir: dir}
+	if err := b.writeFile(ManifestFile, []byte(`{"format": 99}`)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(dir); err == nil || !strings.Contains(err.Error(), "format 99") {
+		t.Fatalf("expected a format error, got %v", err)
+	}
+}
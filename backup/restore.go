@@ -0,0 +1,731 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/siteimage"
+)
+
+// Target is the part of *ghost.Ghost Restore needs
+type Target interface {
+	Capabilities() (ghost.Capabilities, error)
+	AdminGetSite() (ghost.Site, error)
+	AdminGetPosts() (ghost.Posts, error)
+	AdminCreatePost(post ghost.Post) (ghost.Posts, error)
+	AdminGetPages() (ghost.Pages, error)
+	AdminCreatePage(page ghost.Page) (ghost.Pages, error)
+	AdminGetTags() (ghost.Tags, error)
+	AdminCreateTags(tags ghost.NewTags) error
+	AdminGetMembers() (ghost.Members, error)
+	AdminCreateMember(member ghost.NewMember) (ghost.Members, error)
+	AdminGrantComplimentaryTier(memberId, tierId string, expiry *time.Time) (ghost.Members, error)
+	AdminGetNewsletters() (ghost.Newsletters, error)
+	AdminCreateNewsletter(newsletter ghost.Newsletter) (ghost.Newsletters, error)
+	AdminGetTiers() (ghost.Tiers, error)
+	AdminCreateTier(tier ghost.Tier) (ghost.Tiers, error)
+	AdminGetSettings() (ghost.AdminSettings, error)
+	AdminUpdateSettings(settings ghost.AdminSettings, keys ...string) (ghost.AdminSettings, error)
+	AdminGetRedirects(ctx context.Context) ([]ghost.Redirect, error)
+	AdminUploadRedirects(ctx context.Context, redirects []ghost.Redirect) error
+	AdminDownloadRoutes(ctx context.Context, w io.Writer) error
+	AdminUploadRoutesFile(ctx context.Context, r io.Reader) error
+	AdminGetThemes() (ghost.Themes, error)
+	AdminUploadTheme(ctx context.Context, name string, r io.Reader) (ghost.Theme, error)
+	AdminActivateTheme(ctx context.Context, name string) (ghost.Theme, error)
+	AdminUploadImageReader(ctx context.Context, name string, r io.Reader, opts ghost.ImageUploadOptions) (ghost.Image, error)
+}
+
+type Action string
+
+const (
+	Created Action = "created"
+	Updated Action = "updated"
+	// Skipped items are already on the target
+	Skipped Action = "skipped"
+	Failed  Action = "failed"
+)
+
+// Item is the result of restoring one entry of the backup
+type Item struct {
+	Kind   string // "image", "newsletter", "tier", "tag", "post", "page", "member", "settings", "redirects", "theme" or "routes"
+	Key    string // file, slug, email or name
+	Action Action
+	Err    error
+}
+
+func (i Item) String() string {
+	if i.Err != nil {
+		return fmt.Sprintf("%-7s %-10s %s: %v", i.Action, i.Kind, i.Key, i.Err)
+	}
+	return fmt.Sprintf("%-7s %-10s %s", i.Action, i.Kind, i.Key)
+}
+
+type Report struct {
+	Manifest Manifest
+	Items    []Item
+}
+
+// Failed returns the items that couldn't be restored
+func (r *Report) Failed() []Item {
+	var failed []Item
+	for _, item := range r.Items {
+		if item.Action == Failed {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Print writes one line per item that was not skipped
+func (r *Report) Print(w io.Writer) error {
+	var skipped int
+	for _, item := range r.Items {
+		if item.Action == Skipped {
+			skipped++
+			continue
+		}
+		if _, err := fmt.Fprintln(w, item); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d items already on the site\n", skipped)
+	return err
+}
+
+// Restore recreates the backup at src, a directory or tarball written by Create, on dst.
+// Content that exists on dst is left alone: posts, pages, tags, newsletters and tiers are matched
+// by slug, members by email and the theme by name. Settings, redirects and routes.yaml are
+// replaced if they differ. Paid subscriptions live in Stripe and are not restored, comped members
+// get their complimentary tiers back.
+//
+// Failed items are recorded in the report and don't stop the restore, running it again retries them.
+// The error is set if the backup can't be read, the target can't be listed or items failed.
+func Restore(ctx context.Context, dst Target, src string, opts ...Option) (*Report, error) {
+	o := newOptions(opts)
+	if o.statePath == "" {
+		o.statePath = strings.TrimSuffix(filepath.Clean(src), string(filepath.Separator)) + ".restore.json"
+	}
+
+	dir, cleanup, err := openBackup(src)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanup()
+	manifest, err := readManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, err := dst.Capabilities()
+	if err != nil {
+		return nil, err
+	}
+	site, err := dst.AdminGetSite()
+	if err != nil {
+		return nil, err
+	}
+	st, err := loadState(o.statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &restorer{
+		ctx:          ctx,
+		dst:          dst,
+		dir:          dir,
+		opts:         o,
+		capabilities: capabilities,
+		manifest:     manifest,
+		report:       &Report{Manifest: manifest},
+		siteURL:      strings.TrimSuffix(site.URL, "/") + "/",
+		uploads:      st.site(strings.TrimSuffix(site.URL, "/") + "/"),
+		urls:         map[string]string{},
+		tags:         map[string]string{},
+		newsletters:  map[string]string{},
+		tiers:        map[string]string{},
+	}
+
+	// images first so content can point to the uploads, tiers and newsletters before members
+	steps := []func() error{
+		r.restoreImages, r.restoreNewsletters, r.restoreTiers, r.restoreTags, r.restorePosts,
+		r.restorePages, r.restoreMembers, r.restoreSettings, r.restoreRedirects, r.restoreTheme, r.restoreRoutes,
+	}
+	for _, step := range steps {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if err = step(); err != nil {
+			break
+		}
+	}
+	if saveErr := st.save(o.statePath); err == nil {
+		err = saveErr
+	}
+	if err != nil {
+		return r.report, err
+	}
+	if failed := len(r.report.Failed()); failed > 0 {
+		return r.report, fmt.Errorf("%d of %d items failed", failed, len(r.report.Items))
+	}
+	return r.report, nil
+}
+
+type restorer struct {
+	ctx          context.Context
+	dst          Target
+	dir          string
+	opts         options
+	capabilities ghost.Capabilities
+	manifest     Manifest
+	report       *Report
+	siteURL      string
+	uploads      *siteState
+	// urls maps image URLs of the backed up site to their uploads on the target
+	urls    map[string]string
+	rewrite func(string) string
+	// tags, newsletters and tiers map IDs of the backup to IDs on the target
+	tags        map[string]string
+	newsletters map[string]string
+	tiers       map[string]string
+}
+
+func (r *restorer) add(kind, key string, action Action, err error) {
+	if err != nil {
+		action = Failed
+	}
+	r.report.Items = append(r.report.Items, Item{Kind: kind, Key: key, Action: action, Err: err})
+}
+
+// read decodes a JSON file of the backup, found is false for files left out by options
+func (r *restorer) read(name string, v interface{}) (found bool, err error) {
+	data, err := os.ReadFile(filepath.Join(r.dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	return true, nil
+}
+
+func (r *restorer) restoreImages() error {
+	defer func() {
+		r.rewrite = ghost.SiteURLRewriter(r.manifest.Site, r.siteURL, r.urls)
+	}()
+
+	var index map[string]string
+	found, err := r.read(ImagesFile, &index)
+	if err != nil || !found || !r.opts.images {
+		return err
+	}
+
+	unique := map[string]bool{}
+	for _, file := range index {
+		unique[file] = true
+	}
+	var files []string
+	for file := range unique {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if _, ok := r.uploads.Images[file]; ok {
+			r.add("image", file, Skipped, nil)
+			continue
+		}
+		image, err := r.uploadImage(file)
+		if err == nil {
+			r.uploads.Images[file] = image.URL
+		}
+		r.add("image", file, Created, err)
+	}
+
+	for rawURL, file := range index {
+		newURL, ok := r.uploads.Images[file]
+		if !ok {
+			continue
+		}
+		_, size, _ := siteimage.File(r.manifest.Site, rawURL)
+		r.urls[rawURL] = siteimage.Resized(newURL, size)
+	}
+	return nil
+}
+
+func (r *restorer) uploadImage(file string) (ghost.Image, error) {
+	in, err := os.Open(filepath.Join(r.dir, ImagesDir, filepath.FromSlash(file)))
+	if err != nil {
+		return ghost.Image{}, err
+	}
+	defer in.Close()
+	return r.dst.AdminUploadImageReader(r.ctx, path.Base(file), in, ghost.ImageUploadOptions{Ref: file})
+}
+
+func (r *restorer) restoreNewsletters() error {
+	var newsletters []ghost.Newsletter
+	found, err := r.read(NewslettersFile, &newsletters)
+	if err != nil || !found {
+		return err
+	}
+	if !r.capabilities.Newsletters {
+		for _, newsletter := range newsletters {
+			r.add("newsletter", newsletter.Slug, Failed, fmt.Errorf("%w: newsletters need Ghost 5", ghost.ErrUnsupported))
+		}
+		return nil
+	}
+
+	existing, err := r.dst.AdminGetNewsletters()
+	if err != nil {
+		return fmt.Errorf("newsletters: %w", err)
+	}
+	bySlug := map[string]string{}
+	for _, newsletter := range existing.Newsletters {
+		bySlug[newsletter.Slug] = newsletter.Id
+	}
+
+	for _, newsletter := range newsletters {
+		if id, ok := bySlug[newsletter.Slug]; ok {
+			r.newsletters[newsletter.Id] = id
+			r.add("newsletter", newsletter.Slug, Skipped, nil)
+			continue
+		}
+		newsletter.HeaderImage = r.rewrite(newsletter.HeaderImage)
+		created, err := r.dst.AdminCreateNewsletter(newsletter)
+		if err == nil && len(created.Newsletters) > 0 {
+			r.newsletters[newsletter.Id] = created.Newsletters[0].Id
+		}
+		r.add("newsletter", newsletter.Slug, Created, err)
+	}
+	return nil
+}
+
+func (r *restorer) restoreTiers() error {
+	var tiers []ghost.Tier
+	found, err := r.read(TiersFile, &tiers)
+	if err != nil || !found {
+		return err
+	}
+	if !r.capabilities.Tiers {
+		for _, tier := range tiers {
+			r.add("tier", tier.Slug, Failed, fmt.Errorf("%w: tiers need Ghost 5", ghost.ErrUnsupported))
+		}
+		return nil
+	}
+
+	existing, err := r.dst.AdminGetTiers()
+	if err != nil {
+		return fmt.Errorf("tiers: %w", err)
+	}
+	bySlug := map[string]string{}
+	var free string
+	for _, tier := range existing.Tiers {
+		bySlug[tier.Slug] = tier.Id
+		if tier.Type == "free" {
+			free = tier.Id
+		}
+	}
+
+	for _, tier := range tiers {
+		id, ok := bySlug[tier.Slug]
+		// every site has exactly one free tier
+		if tier.Type == "free" && free != "" {
+			id, ok = free, true
+		}
+		if ok {
+			r.tiers[tier.Id] = id
+			r.add("tier", tier.Slug, Skipped, nil)
+			continue
+		}
+		created, err := r.dst.AdminCreateTier(tier)
+		if err == nil && len(created.Tiers) > 0 {
+			r.tiers[tier.Id] = created.Tiers[0].Id
+		}
+		r.add("tier", tier.Slug, Created, err)
+	}
+	return nil
+}
+
+func (r *restorer) restoreTags() error {
+	var tags []ghost.Tag
+	found, err := r.read(TagsFile, &tags)
+	if err != nil || !found {
+		return err
+	}
+	existing, err := r.dst.AdminGetTags()
+	if err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	bySlug := map[string]string{}
+	for _, tag := range existing.Tags {
+		bySlug[tag.Slug] = tag.Id
+	}
+
+	var created int
+	for _, tag := range tags {
+		if id, ok := bySlug[tag.Slug]; ok {
+			r.tags[tag.Id] = id
+			r.add("tag", tag.Slug, Skipped, nil)
+			continue
+		}
+		err := r.dst.AdminCreateTags(ghost.NewTags{Tags: []ghost.NewTag{{
+			CreatedAt:          tag.CreatedAt,
+			UpdatedAt:          tag.UpdatedAt,
+			Name:               tag.Name,
+			Slug:               tag.Slug,
+			Description:        tag.Description,
+			FeatureImage:       r.rewrite(tag.FeatureImage),
+			MetaTitle:          tag.MetaTitle,
+			MetaDescription:    tag.MetaDescription,
+			Visibility:         tag.Visibility,
+			TwitterImage:       r.rewrite(tag.TwitterImage),
+			TwitterTitle:       tag.TwitterTitle,
+			TwitterDescription: tag.TwitterDescription,
+			CodeInjectionHead:  tag.CodeInjectionHead,
+			CodeInjectionFoot:  tag.CodeInjectionFoot,
+			CanonicalURL:       r.rewrite(tag.CanonicalURL),
+			AccentColor:        tag.AccentColor,
+		}}})
+		if err == nil {
+			created++
+		}
+		r.add("tag", tag.Slug, Created, err)
+	}
+	if created == 0 {
+		return nil
+	}
+
+	// creating tags doesn't return them, the new IDs come from the list
+	existing, err = r.dst.AdminGetTags()
+	if err != nil {
+		return fmt.Errorf("tags: %w", err)
+	}
+	for _, tag := range existing.Tags {
+		bySlug[tag.Slug] = tag.Id
+	}
+	for _, tag := range tags {
+		if id, ok := bySlug[tag.Slug]; ok {
+			r.tags[tag.Id] = id
+		}
+	}
+	return nil
+}
+
+// tagRefs points tags to their IDs on the target, unknown tags are created by Ghost from their name
+func (r *restorer) tagRefs(tags []ghost.Tag) []ghost.Tag {
+	var refs []ghost.Tag
+	for _, tag := range tags {
+		if id, ok := r.tags[tag.Id]; ok {
+			refs = append(refs, ghost.Tag{Id: id})
+			continue
+		}
+		refs = append(refs, ghost.Tag{Name: tag.Name, Slug: tag.Slug})
+	}
+	return refs
+}
+
+func (r *restorer) restorePosts() error {
+	var posts []ghost.Post
+	found, err := r.read(PostsFile, &posts)
+	if err != nil || !found {
+		return err
+	}
+	existing, err := r.dst.AdminGetPosts()
+	if err != nil {
+		return fmt.Errorf("posts: %w", err)
+	}
+	slugs := map[string]bool{}
+	for _, post := range existing.Posts {
+		slugs[post.Slug] = true
+	}
+
+	for _, post := range posts {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if slugs[post.Slug] {
+			r.add("post", post.Slug, Skipped, nil)
+			continue
+		}
+		_, err := r.dst.AdminCreatePost(r.post(post))
+		r.add("post", post.Slug, Created, err)
+	}
+	return nil
+}
+
+// post prepares a post of the backup for creation on the target
+func (r *restorer) post(post ghost.Post) ghost.Post {
+	post.ID, post.UUID, post.URL, post.Excerpt, post.CommentID, post.UpdatedAt = "", "", "", "", "", ""
+	post.PostRevisions = nil
+	post.Lexical, post.MobileDoc, post.HTML = r.content(post.Lexical, post.MobileDoc, post.HTML)
+	post.FeatureImage = r.rewrite(post.FeatureImage)
+	post.OGImage = r.rewrite(post.OGImage)
+	post.TwitterImage = r.rewrite(post.TwitterImage)
+	post.CanonicalURL = r.rewrite(post.CanonicalURL)
+	post.Tags = r.tagRefs(post.Tags)
+
+	var authors []ghost.Author
+	for _, author := range post.Authors {
+		email := author.Email
+		if mapped, ok := r.opts.authors[email]; ok {
+			email = mapped
+		}
+		authors = append(authors, ghost.Author{Email: email})
+	}
+	post.Authors = authors
+	return post
+}
+
+// content keeps a single format, Ghost rejects Lexical and Mobiledoc together and
+// would convert the HTML instead of using either
+func (r *restorer) content(lexical, mobiledoc, html string) (string, string, string) {
+	switch {
+	case lexical != "":
+		return r.rewrite(lexical), "", ""
+	case mobiledoc != "":
+		return "", r.rewrite(mobiledoc), ""
+	default:
+		return "", "", r.rewrite(html)
+	}
+}
+
+func (r *restorer) restorePages() error {
+	var pages []ghost.Page
+	found, err := r.read(PagesFile, &pages)
+	if err != nil || !found {
+		return err
+	}
+	existing, err := r.dst.AdminGetPages()
+	if err != nil {
+		return fmt.Errorf("pages: %w", err)
+	}
+	slugs := map[string]bool{}
+	for _, page := range existing.Pages {
+		slugs[page.Slug] = true
+	}
+
+	for _, page := range pages {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if slugs[page.Slug] {
+			r.add("page", page.Slug, Skipped, nil)
+			continue
+		}
+		page.ID, page.UUID, page.URL, page.Excerpt, page.CommentID, page.UpdatedAt = "", "", "", "", "", ""
+		page.Lexical, page.MobileDoc, page.HTML = r.content(page.Lexical, page.MobileDoc, page.HTML)
+		page.FeatureImage = r.rewrite(page.FeatureImage)
+		page.OGImage = r.rewrite(page.OGImage)
+		page.TwitterImage = r.rewrite(page.TwitterImage)
+		page.Tags = r.tagRefs(page.Tags)
+		_, err := r.dst.AdminCreatePage(page)
+		r.add("page", page.Slug, Created, err)
+	}
+	return nil
+}
+
+func (r *restorer) restoreMembers() error {
+	if !r.opts.members {
+		return nil
+	}
+	var members []ghost.Member
+	found, err := r.read(MembersFile, &members)
+	if err != nil || !found {
+		return err
+	}
+	existing, err := r.dst.AdminGetMembers()
+	if err != nil {
+		return fmt.Errorf("members: %w", err)
+	}
+	emails := map[string]bool{}
+	for _, member := range existing.Members {
+		emails[strings.ToLower(member.Email)] = true
+	}
+
+	for _, member := range members {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		if emails[strings.ToLower(member.Email)] {
+			r.add("member", member.Email, Skipped, nil)
+			continue
+		}
+		r.add("member", member.Email, Created, r.createMember(member))
+	}
+	return nil
+}
+
+func (r *restorer) createMember(member ghost.Member) error {
+	newMember := ghost.NewMember{Name: member.Name, Email: member.Email}
+	if note, ok := member.Note.(string); ok {
+		newMember.Note = note
+	}
+	for _, label := range member.Labels {
+		newMember.Labels = append(newMember.Labels, label.Name)
+	}
+	for _, newsletter := range member.Newsletters {
+		if id, ok := r.newsletters[newsletter.Id]; ok {
+			newMember.Newsletters = append(newMember.Newsletters, ghost.NewsletterRef{Id: id})
+		}
+	}
+	if len(newMember.Newsletters) == 0 {
+		// without newsletters on the target, the old subscribed flag is all there is
+		subscribed := member.Subscribed && !r.capabilities.Newsletters
+		newMember.Subscribed = &subscribed
+	}
+
+	created, err := r.dst.AdminCreateMember(newMember)
+	if err != nil || member.StatusOf() != ghost.MemberStatusComped || len(created.Members) == 0 {
+		return err
+	}
+	for _, tier := range member.Tiers {
+		id, ok := r.tiers[tier.Id]
+		if !ok {
+			continue
+		}
+		if _, err := r.dst.AdminGrantComplimentaryTier(created.Members[0].Id, id, tier.ExpiryAt); err != nil {
+			return fmt.Errorf("member created, but granting tier %s failed: %w", tier.Slug, err)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreSettings() error {
+	var file settingsFile
+	found, err := r.read(SettingsFile, &file)
+	if err != nil || !found {
+		return err
+	}
+	current, err := r.dst.AdminGetSettings()
+	if err != nil {
+		return fmt.Errorf("settings: %w", err)
+	}
+
+	// only the typed settings, the others include keys and addresses of the backed up site
+	desired := file.AdminSettings
+	desired.Other = nil
+	for _, field := range []*string{&desired.Logo, &desired.Icon, &desired.CoverImage, &desired.OGImage, &desired.TwitterImage} {
+		*field = r.rewrite(*field)
+	}
+	for _, navigation := range [][]ghost.NavigationItem{desired.Navigation, desired.SecondaryNavigation} {
+		for i := range navigation {
+			navigation[i].URL = r.rewrite(navigation[i].URL)
+		}
+	}
+
+	keys, err := desired.Changed(current)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		r.add("settings", "", Skipped, nil)
+		return nil
+	}
+	_, err = r.dst.AdminUpdateSettings(desired, keys...)
+	r.add("settings", strings.Join(keys, ","), Updated, err)
+	return nil
+}
+
+func (r *restorer) restoreRedirects() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, RedirectsFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	redirects, err := ghost.ParseRedirects(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", RedirectsFile, err)
+	}
+	for i := range redirects {
+		redirects[i].To = r.rewrite(redirects[i].To)
+	}
+
+	current, err := r.dst.AdminGetRedirects(r.ctx)
+	if err != nil {
+		return fmt.Errorf("redirects: %w", err)
+	}
+	if len(current) == len(redirects) && (len(current) == 0 || reflect.DeepEqual(current, redirects)) {
+		r.add("redirects", RedirectsFile, Skipped, nil)
+		return nil
+	}
+	r.add("redirects", RedirectsFile, Updated, r.dst.AdminUploadRedirects(r.ctx, redirects))
+	return nil
+}
+
+func (r *restorer) restoreTheme() error {
+	if !r.opts.theme || r.manifest.Theme == "" {
+		return nil
+	}
+	themes, err := r.dst.AdminGetThemes()
+	if err != nil {
+		return fmt.Errorf("themes: %w", err)
+	}
+	var installed, active bool
+	for _, theme := range themes.Themes {
+		if theme.Name == r.manifest.Theme {
+			installed, active = true, theme.Active
+		}
+	}
+	if active {
+		r.add("theme", r.manifest.Theme, Skipped, nil)
+		return nil
+	}
+
+	action := Updated
+	if !installed {
+		action = Created
+		in, err := os.Open(filepath.Join(r.dir, ThemeDir, r.manifest.Theme+".zip"))
+		if err != nil {
+			return err
+		}
+		_, err = r.dst.AdminUploadTheme(r.ctx, r.manifest.Theme+".zip", in)
+		_ = in.Close()
+		if err != nil {
+			r.add("theme", r.manifest.Theme, Failed, err)
+			return nil
+		}
+	}
+	_, err = r.dst.AdminActivateTheme(r.ctx, r.manifest.Theme)
+	r.add("theme", r.manifest.Theme, action, err)
+	return nil
+}
+
+func (r *restorer) restoreRoutes() error {
+	data, err := os.ReadFile(filepath.Join(r.dir, RoutesFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var current bytes.Buffer
+	if err := r.dst.AdminDownloadRoutes(r.ctx, &current); err != nil {
+		return fmt.Errorf("routes: %w", err)
+	}
+	if bytes.Equal(bytes.TrimSpace(current.Bytes()), bytes.TrimSpace(data)) {
+		r.add("routes", RoutesFile, Skipped, nil)
+		return nil
+	}
+	r.add("routes", RoutesFile, Updated, r.dst.AdminUploadRoutesFile(r.ctx, bytes.NewReader(data)))
+	return nil
+}
@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// state remembers the images uploaded by earlier restores, so re-runs don't upload them again
+type state struct {
+	// Sites holds the uploads by target site URL, one backup may be restored on several sites
+	Sites map[string]*siteState `json:"sites"`
+}
+
+type siteState struct {
+	Images map[string]string `json:"images"` // file below ImagesDir -> URL on the site
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{Sites: map[string]*siteState{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Sites == nil {
+		s.Sites = map[string]*siteState{}
+	}
+	return s, nil
+}
+
+func (s *state) site(url string) *siteState {
+	site, ok := s.Sites[url]
+	if !ok || site == nil {
+		site = &siteState{}
+		s.Sites[url] = site
+	}
+	if site.Images == nil {
+		site.Images = map[string]string{}
+	}
+	return site
+}
+
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
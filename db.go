@@ -0,0 +1,396 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Export is Ghost's JSON export as downloaded from Labs or the db endpoint
+type Export struct {
+	DB []ExportDB `json:"db"`
+}
+
+type ExportDB struct {
+	Meta ExportMeta `json:"meta"`
+	Data ExportData `json:"data"`
+}
+
+type ExportMeta struct {
+	ExportedOn int64  `json:"exported_on"` // unix milliseconds
+	Version    string `json:"version"`     // Ghost version, e.g. "5.82.0"
+}
+
+// ExportData are the exported tables. The common ones are typed, all others are kept as is
+// in Other by table name, so an export survives a round trip through Go. Typed columns a row
+// was read without stay absent when it is written back.
+type ExportData struct {
+	Posts        []ExportPost
+	Tags         []ExportTag
+	PostsTags    []ExportPostTag
+	Users        []ExportUser
+	PostsAuthors []ExportPostAuthor
+	Settings     []ExportSetting
+	Other        map[string]json.RawMessage
+}
+
+// ExportColumns keeps the columns of an exported row that have no typed field
+type ExportColumns map[string]json.RawMessage
+
+// ExportBool is a boolean column. SQLite exports write booleans as true and false, MySQL
+// exports as 1 and 0, the value is written back the way it was read.
+type ExportBool struct {
+	Value   bool
+	Numeric bool // read as 0 or 1
+	Null    bool // read as null, written as null regardless of Value
+}
+
+func (b *ExportBool) UnmarshalJSON(data []byte) error {
+	switch string(data) {
+	case "true", "false":
+		*b = ExportBool{Value: string(data) == "true"}
+	case "1", "0":
+		*b = ExportBool{Value: string(data) == "1", Numeric: true}
+	case "null":
+		*b = ExportBool{Null: true}
+	default:
+		return fmt.Errorf("invalid boolean %s", data)
+	}
+	return nil
+}
+
+func (b ExportBool) MarshalJSON() ([]byte, error) {
+	if b.Null {
+		return []byte("null"), nil
+	}
+	if !b.Numeric {
+		return json.Marshal(b.Value)
+	}
+	if b.Value {
+		return []byte("1"), nil
+	}
+	return []byte("0"), nil
+}
+
+// ExportPost is a row of the posts table, pages are posts of type "page"
+type ExportPost struct {
+	ID            string        `json:"id"`
+	UUID          string        `json:"uuid"`
+	Title         string        `json:"title"`
+	Slug          string        `json:"slug"`
+	Type          string        `json:"type"` // "post" or "page"
+	Status        string        `json:"status"`
+	Visibility    string        `json:"visibility"`
+	Featured      ExportBool    `json:"featured"`
+	Lexical       *string       `json:"lexical"`
+	MobileDoc     *string       `json:"mobiledoc"`
+	HTML          *string       `json:"html"`
+	FeatureImage  *string       `json:"feature_image"`
+	CustomExcerpt *string       `json:"custom_excerpt"`
+	CanonicalURL  *string       `json:"canonical_url"`
+	CreatedAt     string        `json:"created_at"`
+	UpdatedAt     *string       `json:"updated_at"`
+	PublishedAt   *string       `json:"published_at"`
+	Columns       ExportColumns `json:"-"`
+	absent        map[string]bool
+}
+
+type ExportTag struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Slug         string        `json:"slug"`
+	Description  *string       `json:"description"`
+	FeatureImage *string       `json:"feature_image"`
+	Visibility   string        `json:"visibility"` // "public" or "internal" for #tags
+	CreatedAt    string        `json:"created_at"`
+	UpdatedAt    *string       `json:"updated_at"`
+	Columns      ExportColumns `json:"-"`
+	absent       map[string]bool
+}
+
+type ExportPostTag struct {
+	ID        string        `json:"id"`
+	PostID    string        `json:"post_id"`
+	TagID     string        `json:"tag_id"`
+	SortOrder int           `json:"sort_order"`
+	Columns   ExportColumns `json:"-"`
+	absent    map[string]bool
+}
+
+type ExportUser struct {
+	ID           string        `json:"id"`
+	Name         string        `json:"name"`
+	Slug         string        `json:"slug"`
+	Email        string        `json:"email"`
+	Status       string        `json:"status"`
+	ProfileImage *string       `json:"profile_image"`
+	Bio          *string       `json:"bio"`
+	CreatedAt    string        `json:"created_at"`
+	UpdatedAt    *string       `json:"updated_at"`
+	Columns      ExportColumns `json:"-"`
+	absent       map[string]bool
+}
+
+type ExportPostAuthor struct {
+	ID        string        `json:"id"`
+	PostID    string        `json:"post_id"`
+	AuthorID  string        `json:"author_id"`
+	SortOrder int           `json:"sort_order"`
+	Columns   ExportColumns `json:"-"`
+	absent    map[string]bool
+}
+
+type ExportSetting struct {
+	ID      string        `json:"id"`
+	Group   string        `json:"group"`
+	Key     string        `json:"key"`
+	Value   *string       `json:"value"`
+	Type    string        `json:"type"`
+	Columns ExportColumns `json:"-"`
+	absent  map[string]bool
+}
+
+// ImportResult is Ghost's answer to an import, entries with problems were skipped or changed
+type ImportResult struct {
+	Problems []ImportProblem `json:"problems"`
+}
+
+type ImportProblem struct {
+	Message string          `json:"message"`
+	Help    string          `json:"help"`    // the kind of entry, e.g. "Tag"
+	Context string          `json:"context"` // the entry as JSON
+	Err     json.RawMessage `json:"err,omitempty"`
+}
+
+func (p ImportProblem) String() string {
+	if p.Help == "" {
+		return p.Message
+	}
+	return p.Help + ": " + p.Message
+}
+
+type importResponse struct {
+	DB []ImportResult `json:"db"`
+}
+
+// ParseExport reads a JSON export
+func ParseExport(r io.Reader) (Export, error) {
+	var export Export
+	if err := json.NewDecoder(r).Decode(&export); err != nil {
+		return Export{}, err
+	}
+	if len(export.DB) == 0 {
+		return Export{}, fmt.Errorf("export contains no database")
+	}
+	return export, nil
+}
+
+// AdminExportDB streams the JSON export of all content to w
+func (g *Ghost) AdminExportDB(ctx context.Context, w io.Writer) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/db/", g.url)
+	return g.download(ctx, url, w)
+}
+
+// AdminGetExport downloads and parses the JSON export
+func (g *Ghost) AdminGetExport(ctx context.Context) (Export, error) {
+	var buf bytes.Buffer
+	if err := g.AdminExportDB(ctx, &buf); err != nil {
+		return Export{}, err
+	}
+	return ParseExport(&buf)
+}
+
+// AdminImportDB imports a JSON export or a zip with the export and its images. Ghost adds the
+// content to the site, large imports may need an HTTP client with a longer timeout.
+func (g *Ghost) AdminImportDB(ctx context.Context, name string, r io.Reader) (ImportResult, error) {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/db/", g.url)
+
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+	case ".zip":
+		contentType = "application/zip"
+	default:
+		return ImportResult{}, fmt.Errorf("import file %s must be .json or .zip", name)
+	}
+
+	var response importResponse
+	file := uploadFile{field: "importfile", name: name, contentType: contentType, r: r}
+	if err := g.upload(ctx, url, []uploadFile{file}, nil, &response); err != nil {
+		return ImportResult{}, err
+	}
+	var result ImportResult
+	for _, db := range response.DB {
+		result.Problems = append(result.Problems, db.Problems...)
+	}
+	return result, nil
+}
+
+// AdminImportExport imports export, e.g. after transforming a downloaded export
+func (g *Ghost) AdminImportExport(ctx context.Context, export Export) (ImportResult, error) {
+	data, err := json.Marshal(export)
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return g.AdminImportDB(ctx, "ghost-import.json", bytes.NewReader(data))
+}
+
+var exportTables = []string{"posts", "tags", "posts_tags", "users", "posts_authors", "settings"}
+
+func (d *ExportData) UnmarshalJSON(data []byte) error {
+	var tables map[string]json.RawMessage
+	if err := json.Unmarshal(data, &tables); err != nil {
+		return err
+	}
+	typed := map[string]interface{}{
+		"posts": &d.Posts, "tags": &d.Tags, "posts_tags": &d.PostsTags,
+		"users": &d.Users, "posts_authors": &d.PostsAuthors, "settings": &d.Settings,
+	}
+	d.Other = map[string]json.RawMessage{}
+	for name, rows := range tables {
+		target, ok := typed[name]
+		if !ok {
+			d.Other[name] = rows
+			continue
+		}
+		if err := json.Unmarshal(rows, target); err != nil {
+			return fmt.Errorf("table %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func (d ExportData) MarshalJSON() ([]byte, error) {
+	tables := map[string]json.RawMessage{}
+	for name, rows := range d.Other {
+		tables[name] = rows
+	}
+	for i, rows := range []interface{}{d.Posts, d.Tags, d.PostsTags, d.Users, d.PostsAuthors, d.Settings} {
+		encoded, err := json.Marshal(rows)
+		if err != nil {
+			return nil, err
+		}
+		// nil tables were not in the export
+		if string(encoded) != "null" {
+			tables[exportTables[i]] = encoded
+		}
+	}
+	return json.Marshal(tables)
+}
+
+// unmarshalRow decodes the typed columns into row and keeps the others in columns.
+// Typed columns the data lacks are recorded in absent, so they aren't written back.
+func unmarshalRow(data []byte, row interface{}, columns *ExportColumns, absent *map[string]bool) error {
+	if err := json.Unmarshal(data, row); err != nil {
+		return err
+	}
+	known, err := marshalRowColumns(row)
+	if err != nil {
+		return err
+	}
+	var all ExportColumns
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for name := range known {
+		if _, ok := all[name]; !ok {
+			if *absent == nil {
+				*absent = map[string]bool{}
+			}
+			(*absent)[name] = true
+		}
+		delete(all, name)
+	}
+	if len(all) > 0 {
+		*columns = all
+	}
+	return nil
+}
+
+// marshalRow encodes the typed columns of row that were not absent together with the kept columns
+func marshalRow(row interface{}, columns ExportColumns, absent map[string]bool) ([]byte, error) {
+	known, err := marshalRowColumns(row)
+	if err != nil {
+		return nil, err
+	}
+	for name := range absent {
+		delete(known, name)
+	}
+	for name, value := range columns {
+		if _, ok := known[name]; !ok {
+			known[name] = value
+		}
+	}
+	return json.Marshal(known)
+}
+
+func marshalRowColumns(row interface{}) (ExportColumns, error) {
+	data, err := json.Marshal(row)
+	if err != nil {
+		return nil, err
+	}
+	var columns ExportColumns
+	err = json.Unmarshal(data, &columns)
+	return columns, err
+}
+
+// The row types are aliased to marshal the typed columns without recursing
+
+type exportPost ExportPost
+type exportTag ExportTag
+type exportPostTag ExportPostTag
+type exportUser ExportUser
+type exportPostAuthor ExportPostAuthor
+type exportSetting ExportSetting
+
+func (p *ExportPost) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportPost)(p), &p.Columns, &p.absent)
+}
+
+func (p ExportPost) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportPost(p), p.Columns, p.absent)
+}
+
+func (t *ExportTag) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportTag)(t), &t.Columns, &t.absent)
+}
+
+func (t ExportTag) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportTag(t), t.Columns, t.absent)
+}
+
+func (pt *ExportPostTag) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportPostTag)(pt), &pt.Columns, &pt.absent)
+}
+
+func (pt ExportPostTag) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportPostTag(pt), pt.Columns, pt.absent)
+}
+
+func (u *ExportUser) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportUser)(u), &u.Columns, &u.absent)
+}
+
+func (u ExportUser) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportUser(u), u.Columns, u.absent)
+}
+
+func (pa *ExportPostAuthor) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportPostAuthor)(pa), &pa.Columns, &pa.absent)
+}
+
+func (pa ExportPostAuthor) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportPostAuthor(pa), pa.Columns, pa.absent)
+}
+
+func (s *ExportSetting) UnmarshalJSON(data []byte) error {
+	return unmarshalRow(data, (*exportSetting)(s), &s.Columns, &s.absent)
+}
+
+func (s ExportSetting) MarshalJSON() ([]byte, error) {
+	return marshalRow(exportSetting(s), s.Columns, s.absent)
+}
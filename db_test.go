@@ -0,0 +1,120 @@
+package ghost
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseExport(t *testing.T) {
+	tests := []struct {
+		file    string
+		numeric bool
+	}{
+		{"sqlite.json", false},
+		{"mysql.json", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "export", test.file))
+			if err != nil {
+				t.Fatalf("Cannot read export: %s", err)
+			}
+			export, err := ParseExport(bytes.NewReader(data))
+			if err != nil {
+				t.Fatalf("Cannot parse export: %s", err)
+			}
+			db := export.DB[0]
+			if db.Meta.Version != "5.82.0" || len(db.Data.Posts) != 2 {
+				t.Fatalf("Unexpected export: %+v", db)
+			}
+
+			launch, about := db.Data.Posts[0], db.Data.Posts[1]
+			if launch.Featured != (ExportBool{Value: true, Numeric: test.numeric}) || about.Featured != (ExportBool{Numeric: test.numeric}) {
+				t.Fatalf("Unexpected featured: %+v, %+v", launch.Featured, about.Featured)
+			}
+			if launch.Lexical == nil || launch.MobileDoc != nil || about.UpdatedAt != nil || about.Type != "page" {
+				t.Fatalf("Unexpected posts: %+v", db.Data.Posts)
+			}
+
+			// columns and tables without a typed field are kept
+			for _, column := range []string{"comment_id", "plaintext", "email_recipient_filter", "newsletter_id"} {
+				if _, ok := launch.Columns[column]; !ok {
+					t.Fatalf("Column %s missing: %+v", column, launch.Columns)
+				}
+			}
+			if len(about.Columns) != 0 {
+				t.Fatalf("Unexpected columns: %+v", about.Columns)
+			}
+			if len(db.Data.Other) != 2 || db.Data.Other["posts_meta"] == nil || db.Data.Other["newsletters"] == nil {
+				t.Fatalf("Unexpected other tables: %+v", db.Data.Other)
+			}
+			if db.Data.Tags != nil || db.Data.Settings != nil {
+				t.Fatalf("Expected no tags and settings: %+v", db.Data)
+			}
+
+			encoded, err := json.Marshal(export)
+			if err != nil {
+				t.Fatalf("Cannot marshal export: %s", err)
+			}
+			if !jsonEqual(encoded, data) {
+				t.Fatalf("Export changed after round trip:\n%s", encoded)
+			}
+		})
+	}
+}
+
+func TestExportBool(t *testing.T) {
+	for _, value := range []string{"true", "false", "1", "0", "null"} {
+		var b ExportBool
+		if err := json.Unmarshal([]byte(value), &b); err != nil {
+			t.Fatalf("Cannot parse %s: %s", value, err)
+		}
+		encoded, err := json.Marshal(b)
+		if err != nil || string(encoded) != value {
+			t.Fatalf("Expected %s, got %s (%v)", value, encoded, err)
+		}
+	}
+
+	var b ExportBool
+	if err := json.Unmarshal([]byte(`"yes"`), &b); err == nil {
+		t.Fatalf("Expected an error, got %+v", b)
+	}
+}
+
+func TestExportRowRoundTrip(t *testing.T) {
+	rows := []string{
+		`{"id":"1","featured":null,"title":"x"}`,
+		`{"id":"2","featured":1,"status":"draft","comment_id":"2"}`,
+		`{}`,
+	}
+	for _, row := range rows {
+		var post ExportPost
+		if err := json.Unmarshal([]byte(row), &post); err != nil {
+			t.Fatalf("Cannot parse %s: %s", row, err)
+		}
+		encoded, err := json.Marshal(post)
+		if err != nil {
+			t.Fatalf("Cannot marshal %s: %s", row, err)
+		}
+		if !jsonEqual(encoded, []byte(row)) {
+			t.Fatalf("Expected %s, got %s", row, encoded)
+		}
+	}
+
+	// rows built in Go have all typed columns
+	encoded, err := json.Marshal(ExportPost{ID: "3", Featured: ExportBool{Value: true}})
+	if err != nil {
+		t.Fatalf("Cannot marshal post: %s", err)
+	}
+	var columns map[string]json.RawMessage
+	if err := json.Unmarshal(encoded, &columns); err != nil {
+		t.Fatalf("Cannot parse %s: %s", encoded, err)
+	}
+	if string(columns["featured"]) != "true" || string(columns["slug"]) != `""` || string(columns["published_at"]) != "null" {
+		t.Fatalf("Unexpected columns: %s", encoded)
+	}
+}
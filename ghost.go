@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -22,6 +23,9 @@ type Ghost struct {
 	jwtTokenExpiration time.Time
 	url                string
 	client             *http.Client
+	versionMu          sync.Mutex
+	version            *Version // nil until detected or set
+	slugRedirects      bool
 }
 
 // New creates new instance of ghost API client
@@ -162,3 +166,62 @@ func (g *Ghost) postJson(url string, data []byte, target interface{}) error {
 
 	return nil
 }
+
+func (g *Ghost) putJson(url string, data []byte, target interface{}) error {
+	if err := g.checkAndRenewJWT(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPut, url, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Ghost"+" "+g.jwtToken)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			fmt.Printf("Error closing body: %v\n", err)
+		}
+	}(resp.Body)
+
+	err = parsePostResponse(resp, err, target)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (g *Ghost) deleteRequest(url string) error {
+	if err := g.checkAndRenewJWT(); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Ghost"+" "+g.jwtToken)
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	content, _ := io.ReadAll(resp.Body)
+	responseBody := string(content)
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, responseBody)
+	}
+	return nil
+}
@@ -1,14 +1,11 @@
 package ghost
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"mime/multipart"
-	"net/http"
-	"net/textproto"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
@@ -17,6 +14,25 @@ type ImageResponse struct {
 }
 type Image struct {
 	URL string
+	Ref string
+}
+
+// ImagePurpose tells Ghost which size and format checks to apply to an uploaded image
+type ImagePurpose string
+
+const (
+	ImagePurposeImage        ImagePurpose = "image"
+	ImagePurposeProfileImage ImagePurpose = "profile_image"
+	ImagePurposeIcon         ImagePurpose = "icon" // site icon, square png/ico
+)
+
+type ImageUploadOptions struct {
+	// ContentType of the image, detected from the name or the content if empty
+	ContentType string
+	// Purpose defaults to ImagePurposeImage
+	Purpose ImagePurpose
+	// Ref is returned unchanged in the response, e.g. to match uploads with their source
+	Ref string
 }
 
 var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
@@ -25,92 +41,49 @@ func escapeQuotes(s string) string {
 	return quoteEscaper.Replace(s)
 }
 
-// AdminUploadImage - Creates a new file upload http request
+// AdminUploadImage uploads the image file at path and returns its URL
 func (g *Ghost) AdminUploadImage(path string) (imageURL string, err error) {
-	const paramName = "file"
-	var uri = fmt.Sprintf("%s/ghost/api/v3/admin/images/upload/", g.url)
-
-	params := map[string]string{
-		"purpose": "image",
-		"ref":     path,
-	}
 	file, err := os.Open(path)
 	if err != nil {
 		return "", err
 	}
-	fileContents, err := io.ReadAll(file)
-	if err != nil {
-		return "", err
-	}
-	fi, err := file.Stat()
-	if err != nil {
-		return "", err
-	}
-	if err := file.Close(); err != nil {
-		return "", err
-	}
-	body := new(bytes.Buffer)
-	writer := multipart.NewWriter(body)
-
-	h := make(textproto.MIMEHeader)
-	h.Set("Content-Type", "image/jpeg")
-	h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
-		escapeQuotes(paramName), escapeQuotes(fi.Name())))
-	part, err := writer.CreatePart(h)
-	if err != nil {
-		return "", err
-	}
-	_, err = part.Write(fileContents)
-	if err != nil {
-		return "", err
-	}
-
-	for key, val := range params {
-		_ = writer.WriteField(key, val)
-	}
+	defer func() {
+		if err := file.Close(); err != nil {
+			fmt.Printf("cannot close file: %v\n", err)
+		}
+	}()
 
-	err = writer.Close()
+	image, err := g.AdminUploadImageReader(context.Background(), filepath.Base(path), file, ImageUploadOptions{Ref: path})
 	if err != nil {
 		return "", err
 	}
+	return image.URL, nil
+}
 
-	if err := g.checkAndRenewJWT(); err != nil {
-		return "", err
-	}
-
-	request, err := http.NewRequest("POST", uri, body)
-	if err != nil {
-		return "", err
-	}
-	request.Header.Set("Content-Type", writer.FormDataContentType())
-	request.Header.Set("Authorization", "Ghost"+" "+g.jwtToken)
+// AdminUploadImageReader streams an image named name from r to Ghost
+func (g *Ghost) AdminUploadImageReader(ctx context.Context, name string, r io.Reader, opts ImageUploadOptions) (Image, error) {
+	var uri = fmt.Sprintf("%s/ghost/api/v3/admin/images/upload/", g.url)
 
-	client := &http.Client{}
-	response, err := client.Do(request)
+	file, err := newUploadFile("file", name, opts.ContentType, r)
 	if err != nil {
-		return "", err
+		return Image{}, err
 	}
-	defer func() {
-		if err := response.Body.Close(); err != nil {
-			fmt.Printf("cannot close body reader: %v\n", err)
-		}
-	}()
 
-	content, err := io.ReadAll(response.Body)
-	if err != nil {
-		return "", err
+	purpose := opts.Purpose
+	if purpose == "" {
+		purpose = ImagePurposeImage
 	}
-
-	responseBody := string(content[:])
-	if response.StatusCode != http.StatusCreated {
-		return "", fmt.Errorf("wrong http code: %d (%q)",
-			response.StatusCode, responseBody)
+	fields := map[string]string{"purpose": string(purpose)}
+	if opts.Ref != "" {
+		fields["ref"] = opts.Ref
 	}
 
 	var images ImageResponse
-	if err := json.Unmarshal(content[:], &images); err != nil {
-		return "", err
+	if err := g.upload(ctx, uri, []uploadFile{file}, fields, &images); err != nil {
+		return Image{}, err
 	}
-
-	return images.Images[0].URL, nil
+	if len(images.Images) == 0 {
+		return Image{}, fmt.Errorf("no image in upload response")
+	}
+	return images.Images[0], nil
 }
@@ -0,0 +1,107 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x02\x00\x00\x00")
+
+// countingTransport counts the requests of the client passed to New
+type countingTransport struct {
+	requests int
+}
+
+func (c *countingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func TestAdminUploadImageReader(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        ImageUploadOptions
+		contentType string
+		purpose     string
+	}{
+		{"logo", ImageUploadOptions{Purpose: ImagePurposeIcon, Ref: "assets/logo"}, "image/png", "icon"},
+		{"logo.jpg", ImageUploadOptions{}, "image/jpeg", "image"},
+		{"logo", ImageUploadOptions{ContentType: "image/webp"}, "image/webp", "image"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name+" "+test.contentType, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/ghost/api/v3/admin/images/upload/" {
+					t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+				}
+				if err := r.ParseMultipartForm(1 << 20); err != nil {
+					t.Errorf("Cannot parse form: %s", err)
+					return
+				}
+				if r.FormValue("purpose") != test.purpose || r.FormValue("ref") != test.opts.Ref {
+					t.Errorf("Unexpected fields: %v", r.MultipartForm.Value)
+				}
+				file, header, err := r.FormFile("file")
+				if err != nil {
+					t.Errorf("Missing file: %s", err)
+					return
+				}
+				if header.Filename != test.name || header.Header.Get("Content-Type") != test.contentType {
+					t.Errorf("Unexpected file %s of type %s", header.Filename, header.Header.Get("Content-Type"))
+				}
+				if data, _ := io.ReadAll(file); !bytes.Equal(data, pngHeader) {
+					t.Errorf("Unexpected file content %q", data)
+				}
+				_, _ = io.WriteString(w, `{"images":[{"url":"https://example.com/content/images/logo.png","ref":"assets/logo"}]}`)
+			}))
+			defer server.Close()
+
+			transport := &countingTransport{}
+			g := New(server.URL, "", testAdminKey, &http.Client{Transport: transport})
+			image, err := g.AdminUploadImageReader(context.Background(), test.name, bytes.NewReader(pngHeader), test.opts)
+			if err != nil {
+				t.Fatalf("Cannot upload image: %s", err)
+			}
+			if image.URL != "https://example.com/content/images/logo.png" {
+				t.Fatalf("Unexpected image: %+v", image)
+			}
+			if transport.requests != 1 {
+				t.Fatalf("Expected 1 request through the client, got %d", transport.requests)
+			}
+		})
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		contentType string
+	}{
+		{"photo.png", "not sniffed", "image/png"},
+		{"photo.JPG", "not sniffed", "image/jpeg"},
+		{"icon", string(pngHeader), "image/png"},
+		{"notes", "plain text", "text/plain"},
+		{"empty", "", "text/plain"},
+	}
+
+	for _, test := range tests {
+		contentType, r, err := detectContentType(test.name, strings.NewReader(test.content))
+		if err != nil {
+			t.Fatalf("Cannot detect %s: %s", test.name, err)
+		}
+		if contentType != test.contentType {
+			t.Fatalf("Expected %s for %s, got %s", test.contentType, test.name, contentType)
+		}
+		// the sniffed bytes are not lost
+		if data, _ := io.ReadAll(r); string(data) != test.content {
+			t.Fatalf("Content of %s changed: %q", test.name, data)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n"); got != "" {
+		t.Fatalf("Expected no diff, got %q", got)
+	}
+
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n"
+	b := "1\n2\n3\n4\nfive\n6\n7\n8\n9\n10\n11\n"
+	want := "--- a\n+++ b\n" +
+		"@@ -2,9 +2,10 @@\n" +
+		" 2\n 3\n 4\n-5\n+five\n 6\n 7\n 8\n 9\n 10\n+11\n"
+	if got := Unified("a", "b", a, b); got != want {
+		t.Fatalf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+
+	a = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	b = "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n"
+	want = "--- a\n+++ b\n" +
+		"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+		"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n"
+	if got := Unified("a", "b", a, b); got != want {
+		t.Fatalf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,320 @@
+// Package fakesite is an in-memory Ghost site for testing packages built on the Admin API
+// methods of *ghost.Ghost without a server.
+package fakesite
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"path"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/sklinkert/ghost"
+)
+
+var lastID int64
+
+// NextID returns an ID that is unique across all fake sites
+func NextID() string {
+	return fmt.Sprintf("id-%d", atomic.AddInt64(&lastID, 1))
+}
+
+// Site keeps a Ghost site in memory. Every write of a post or page bumps its updated_at,
+// updates with an outdated updated_at are rejected like Ghost does.
+type Site struct {
+	URL         string // without trailing slash
+	Version     ghost.Version
+	Posts       []ghost.Post
+	Pages       []ghost.Page
+	Tags        []ghost.Tag
+	Users       []ghost.User
+	Members     []ghost.Member
+	Newsletters []ghost.Newsletter
+	Tiers       []ghost.Tier
+	Settings    ghost.AdminSettings
+	Redirects   []ghost.Redirect
+	Routes      string
+	Themes      map[string][]byte // theme zips by name
+	ActiveTheme string
+	Images      map[string][]byte // by URL
+	Uploads     int
+
+	writes int
+}
+
+// New creates a site with a free tier, the default routes and the active casper theme
+func New(url string) *Site {
+	return &Site{
+		URL:         strings.TrimSuffix(url, "/"),
+		Version:     ghost.Version{Major: 5, Minor: 80},
+		Tiers:       []ghost.Tier{{Id: NextID(), Name: "Free", Slug: "free", Type: "free"}},
+		Routes:      "routes:\ncollections:\n  /:\n    permalink: /{slug}/\n",
+		Themes:      map[string][]byte{"casper": []byte("casper zip")},
+		ActiveTheme: "casper",
+		Images:      map[string][]byte{},
+	}
+}
+
+func (s *Site) updatedAt() string {
+	s.writes++
+	return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(s.writes) * time.Second).Format("2006-01-02T15:04:05.000Z")
+}
+
+// SavePost stores the post as if it was edited on the site, a post without ID is added
+func (s *Site) SavePost(post ghost.Post) ghost.Post {
+	post.UpdatedAt = s.updatedAt()
+	for i, existing := range s.Posts {
+		if post.ID != "" && existing.ID == post.ID {
+			s.Posts[i] = post
+			return post
+		}
+	}
+	if post.ID == "" {
+		post.ID = NextID()
+	}
+	s.Posts = append(s.Posts, post)
+	return post
+}
+
+// PostBySlug returns the post with the slug
+func (s *Site) PostBySlug(slug string) (ghost.Post, bool) {
+	for _, post := range s.Posts {
+		if post.Slug == slug {
+			return post, true
+		}
+	}
+	return ghost.Post{}, false
+}
+
+func (s *Site) Capabilities() (ghost.Capabilities, error) {
+	return ghost.CapabilitiesOf(s.Version), nil
+}
+
+func (s *Site) AdminGetSite() (ghost.Site, error) {
+	return ghost.Site{Title: s.Settings.Title, URL: s.URL + "/", Version: fmt.Sprintf("%d.%d", s.Version.Major, s.Version.Minor)}, nil
+}
+
+func (s *Site) AdminGetPosts() (ghost.Posts, error) {
+	return ghost.Posts{Posts: append([]ghost.Post(nil), s.Posts...)}, nil
+}
+
+func (s *Site) AdminGetPost(postId string) (ghost.Posts, error) {
+	for _, post := range s.Posts {
+		if post.ID == postId {
+			return ghost.Posts{Posts: []ghost.Post{post}}, nil
+		}
+	}
+	return ghost.Posts{}, fmt.Errorf("post %s not found", postId)
+}
+
+func (s *Site) AdminCreatePost(post ghost.Post) (ghost.Posts, error) {
+	if post.Lexical != "" && post.HTML != "" {
+		return ghost.Posts{}, fmt.Errorf("html would replace lexical")
+	}
+	post.Tags = s.resolveTags(post.Tags)
+	post.ID = ""
+	return ghost.Posts{Posts: []ghost.Post{s.SavePost(post)}}, nil
+}
+
+func (s *Site) AdminUpdatePost(post ghost.Post, sourceType ghost.SourceType) error {
+	for _, existing := range s.Posts {
+		if existing.ID == post.ID {
+			if existing.UpdatedAt != post.UpdatedAt {
+				return fmt.Errorf("update collision")
+			}
+			post.Tags = s.resolveTags(post.Tags)
+			s.SavePost(post)
+			return nil
+		}
+	}
+	return fmt.Errorf("post %s not found", post.ID)
+}
+
+func (s *Site) AdminDeletePost(postId string) error {
+	for i, post := range s.Posts {
+		if post.ID == postId {
+			s.Posts = append(s.Posts[:i], s.Posts[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("post %s not found", postId)
+}
+
+func (s *Site) AdminGetPages() (ghost.Pages, error) {
+	return ghost.Pages{Pages: append([]ghost.Page(nil), s.Pages...)}, nil
+}
+
+func (s *Site) AdminCreatePage(page ghost.Page) (ghost.Pages, error) {
+	if page.Lexical != "" && page.HTML != "" {
+		return ghost.Pages{}, fmt.Errorf("html would replace lexical")
+	}
+	page.ID = NextID()
+	page.UpdatedAt = s.updatedAt()
+	s.Pages = append(s.Pages, page)
+	return ghost.Pages{Pages: []ghost.Page{page}}, nil
+}
+
+func (s *Site) AdminUpdatePage(page ghost.Page, sourceType ghost.SourceType) error {
+	for i, existing := range s.Pages {
+		if existing.ID == page.ID {
+			if existing.UpdatedAt != page.UpdatedAt {
+				return fmt.Errorf("update collision")
+			}
+			page.UpdatedAt = s.updatedAt()
+			s.Pages[i] = page
+			return nil
+		}
+	}
+	return fmt.Errorf("page %s not found", page.ID)
+}
+
+func (s *Site) AdminGetTags() (ghost.Tags, error) {
+	return ghost.Tags{Tags: append([]ghost.Tag(nil), s.Tags...)}, nil
+}
+
+func (s *Site) AdminCreateTags(tags ghost.NewTags) error {
+	for _, tag := range tags.Tags {
+		s.Tags = append(s.Tags, ghost.Tag{Id: NextID(), Name: tag.Name, Slug: tag.Slug, FeatureImage: tag.FeatureImage})
+	}
+	return nil
+}
+
+// resolveTags replaces tag references by ID with the site's tags
+func (s *Site) resolveTags(tags []ghost.Tag) []ghost.Tag {
+	resolved := append([]ghost.Tag(nil), tags...)
+	for i, tag := range resolved {
+		for _, existing := range s.Tags {
+			if tag.Id != "" && existing.Id == tag.Id {
+				resolved[i] = existing
+			}
+		}
+	}
+	return resolved
+}
+
+func (s *Site) AdminGetUsers() (ghost.Users, error) {
+	return ghost.Users{Users: s.Users}, nil
+}
+
+func (s *Site) AdminGetMembers() (ghost.Members, error) {
+	return ghost.Members{Members: s.Members}, nil
+}
+
+func (s *Site) AdminCreateMember(member ghost.NewMember) (ghost.Members, error) {
+	created := ghost.Member{Id: NextID(), Email: member.Email, Name: member.Name, Status: string(ghost.MemberStatusFree)}
+	for _, ref := range member.Newsletters {
+		created.Newsletters = append(created.Newsletters, ghost.Newsletter{Id: ref.Id})
+	}
+	s.Members = append(s.Members, created)
+	return ghost.Members{Members: []ghost.Member{created}}, nil
+}
+
+func (s *Site) AdminGrantComplimentaryTier(memberId, tierId string, expiry *time.Time) (ghost.Members, error) {
+	for i, member := range s.Members {
+		if member.Id == memberId {
+			s.Members[i].Status = string(ghost.MemberStatusComped)
+			s.Members[i].Tiers = append(s.Members[i].Tiers, ghost.Tier{Id: tierId, ExpiryAt: expiry})
+			return ghost.Members{Members: []ghost.Member{s.Members[i]}}, nil
+		}
+	}
+	return ghost.Members{}, fmt.Errorf("member %s not found", memberId)
+}
+
+func (s *Site) AdminGetNewsletters() (ghost.Newsletters, error) {
+	return ghost.Newsletters{Newsletters: s.Newsletters}, nil
+}
+
+func (s *Site) AdminCreateNewsletter(newsletter ghost.Newsletter) (ghost.Newsletters, error) {
+	newsletter.Id = NextID()
+	s.Newsletters = append(s.Newsletters, newsletter)
+	return ghost.Newsletters{Newsletters: []ghost.Newsletter{newsletter}}, nil
+}
+
+func (s *Site) AdminGetTiers() (ghost.Tiers, error) {
+	return ghost.Tiers{Tiers: s.Tiers}, nil
+}
+
+func (s *Site) AdminCreateTier(tier ghost.Tier) (ghost.Tiers, error) {
+	tier.Id = NextID()
+	s.Tiers = append(s.Tiers, tier)
+	return ghost.Tiers{Tiers: []ghost.Tier{tier}}, nil
+}
+
+func (s *Site) AdminGetSettings() (ghost.AdminSettings, error) {
+	return s.Settings, nil
+}
+
+func (s *Site) AdminUpdateSettings(settings ghost.AdminSettings, keys ...string) (ghost.AdminSettings, error) {
+	settings.Other = s.Settings.Other
+	s.Settings = settings
+	return settings, nil
+}
+
+func (s *Site) AdminGetRedirects(ctx context.Context) ([]ghost.Redirect, error) {
+	return s.Redirects, nil
+}
+
+func (s *Site) AdminUploadRedirects(ctx context.Context, redirects []ghost.Redirect) error {
+	s.Redirects = redirects
+	return nil
+}
+
+func (s *Site) AdminDownloadRoutes(ctx context.Context, w io.Writer) error {
+	_, err := io.WriteString(w, s.Routes)
+	return err
+}
+
+func (s *Site) AdminUploadRoutesFile(ctx context.Context, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	s.Routes = string(data)
+	return err
+}
+
+func (s *Site) AdminGetThemes() (ghost.Themes, error) {
+	var themes ghost.Themes
+	for name := range s.Themes {
+		themes.Themes = append(themes.Themes, ghost.Theme{Name: name, Active: name == s.ActiveTheme})
+	}
+	return themes, nil
+}
+
+func (s *Site) AdminDownloadTheme(ctx context.Context, name string, w io.Writer) error {
+	_, err := w.Write(s.Themes[name])
+	return err
+}
+
+func (s *Site) AdminUploadTheme(ctx context.Context, name string, r io.Reader) (ghost.Theme, error) {
+	data, err := io.ReadAll(r)
+	name = strings.TrimSuffix(name, ".zip")
+	s.Themes[name] = data
+	return ghost.Theme{Name: name}, err
+}
+
+func (s *Site) AdminActivateTheme(ctx context.Context, name string) (ghost.Theme, error) {
+	s.ActiveTheme = name
+	return ghost.Theme{Name: name, Active: true}, nil
+}
+
+// AdminUploadImageReader stores the image at <URL>/content/images/2024/05/<upload number>-<name>
+func (s *Site) AdminUploadImageReader(ctx context.Context, name string, r io.Reader, opts ghost.ImageUploadOptions) (ghost.Image, error) {
+	data, err := io.ReadAll(r)
+	s.Uploads++
+	url := fmt.Sprintf("%s/content/images/2024/05/%d-%s", s.URL, s.Uploads, name)
+	s.Images[url] = data
+	return ghost.Image{URL: url, Ref: opts.Ref}, err
+}
+
+// Fetcher serves the site's images like its web server would
+func (s *Site) Fetcher() ghost.Fetcher {
+	return ghost.FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, string, error) {
+		data, ok := s.Images[url]
+		if !ok {
+			return nil, "", fmt.Errorf("unexpected status code 404")
+		}
+		return io.NopCloser(bytes.NewReader(data)), mime.TypeByExtension(path.Ext(url)), nil
+	})
+}
@@ -0,0 +1,47 @@
+// Package siteimage locates the images a Ghost site stores below content/images.
+package siteimage
+
+import (
+	"path"
+	"strings"
+)
+
+// Path is where Ghost serves uploaded images, relative to the site URL
+const Path = "content/images/"
+
+// File returns the path below content/images of an image of the site at siteURL, which ends
+// with a slash. Resized variants like content/images/size/w600/2024/01/a.jpg return the
+// original's path and their size prefix.
+func File(siteURL, rawURL string) (file, size string, ok bool) {
+	if !strings.HasPrefix(rawURL, siteURL+Path) {
+		return "", "", false
+	}
+	file = strings.TrimPrefix(rawURL, siteURL+Path)
+	if i := strings.IndexAny(file, "?#"); i >= 0 {
+		file = file[:i]
+	}
+	if strings.HasPrefix(file, "size/") {
+		parts := strings.SplitN(file, "/", 3)
+		if len(parts) < 3 {
+			return "", "", false
+		}
+		size, file = parts[0]+"/"+parts[1]+"/", parts[2]
+	}
+	if file == "" || strings.HasSuffix(file, "/") || path.Clean("/"+file) != "/"+file {
+		return "", "", false
+	}
+	return file, size, true
+}
+
+// Resized inserts a size prefix like "size/w600/" into the URL of an uploaded image
+func Resized(imageURL, size string) string {
+	if size == "" {
+		return imageURL
+	}
+	i := strings.Index(imageURL, "/"+Path)
+	if i < 0 {
+		return imageURL
+	}
+	i += len(Path) + 1
+	return imageURL[:i] + size + imageURL[i:]
+}
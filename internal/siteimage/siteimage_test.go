@@ -0,0 +1,30 @@
+package siteimage
+
+import "testing"
+
+func TestFile(t *testing.T) {
+	const site = "https://example.com/blog/"
+	tests := []struct {
+		url, file, size string
+		ok              bool
+	}{
+		{"https://example.com/blog/content/images/2024/01/a.jpg", "2024/01/a.jpg", "", true},
+		{"https://example.com/blog/content/images/size/w600/2024/01/a.jpg?v=1", "2024/01/a.jpg", "size/w600/", true},
+		{"https://example.com/blog/content/images/../../secret", "", "", false},
+		{"https://example.com/blog/content/media/2024/01/a.mp4", "", "", false},
+		{"https://cdn.example.com/a.jpg", "", "", false},
+	}
+	for _, test := range tests {
+		file, size, ok := File(site, test.url)
+		if file != test.file || size != test.size || ok != test.ok {
+			t.Errorf("File(%s) = %q, %q, %v", test.url, file, size, ok)
+		}
+	}
+}
+
+func TestResized(t *testing.T) {
+	got := Resized("https://example.com/content/images/2024/01/a.jpg", "size/w600/")
+	if got != "https://example.com/content/images/size/w600/2024/01/a.jpg" {
+		t.Errorf("Resized = %s", got)
+	}
+}
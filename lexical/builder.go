@@ -0,0 +1,84 @@
+package lexical
+
+import "fmt"
+
+// New creates a document with the given top level nodes
+//
+//	doc := lexical.New(
+//		lexical.NewHeading(2, lexical.NewText("Intro")),
+//		lexical.NewParagraph(
+//			lexical.NewText("Hello "),
+//			lexical.NewText("world", lexical.FormatBold),
+//		),
+//		&lexical.ImageCard{Src: "https://example.com/cat.jpg"},
+//	)
+func New(children ...Node) *Document {
+	doc := &Document{}
+	doc.Root.Direction = DirectionLTR
+	doc.Append(children...)
+	return doc
+}
+
+// Append adds top level nodes to the end of the document
+func (d *Document) Append(children ...Node) *Document {
+	d.Root.Children = append(d.Root.Children, children...)
+	return d
+}
+
+func newElement(children []Node) Element {
+	return Element{Children: children, Direction: DirectionLTR}
+}
+
+func NewParagraph(children ...Node) *Paragraph {
+	return &Paragraph{Element: newElement(children)}
+}
+
+// NewHeading creates a heading of level 1-6
+func NewHeading(level int, children ...Node) *Heading {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	return &Heading{Element: newElement(children), Tag: fmt.Sprintf("h%d", level)}
+}
+
+func NewQuote(children ...Node) *Quote {
+	return &Quote{Element: newElement(children)}
+}
+
+// NewList creates a list, items are usually created with NewListItem
+func NewList(listType ListType, items ...Node) *List {
+	tag := "ul"
+	if listType == ListNumber {
+		tag = "ol"
+	}
+	for i, item := range items {
+		if listItem, ok := item.(*ListItem); ok && listItem.Value == 0 {
+			listItem.Value = i + 1
+		}
+	}
+	return &List{Element: newElement(items), ListType: listType, Start: 1, Tag: tag}
+}
+
+func NewListItem(children ...Node) *ListItem {
+	return &ListItem{Element: newElement(children)}
+}
+
+func NewLink(url string, children ...Node) *Link {
+	return &Link{Element: newElement(children), URL: url}
+}
+
+// NewText creates a text node with the given formats, e.g. NewText("x", FormatBold, FormatItalic)
+func NewText(text string, formats ...Format) *Text {
+	var format Format
+	for _, f := range formats {
+		format |= f
+	}
+	return &Text{Text: text, Format: format, Mode: "normal"}
+}
+
+func NewLineBreak() *LineBreak {
+	return &LineBreak{}
+}
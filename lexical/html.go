@@ -0,0 +1,408 @@
+package lexical
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// RenderFunc renders a single node. Use Renderer.RenderChildren to render nested nodes.
+type RenderFunc func(r *Renderer, node Node, w *bytes.Buffer) error
+
+// Renderer turns documents into the HTML Ghost generates for Lexical content,
+// including the kg-card markup of its cards.
+type Renderer struct {
+	renderers  map[string]RenderFunc
+	headingIDs map[string]int
+}
+
+// NewRenderer creates a renderer with Ghost's default markup for all known nodes
+func NewRenderer() *Renderer {
+	r := &Renderer{renderers: map[string]RenderFunc{
+		TypeParagraph:      renderParagraph,
+		TypeHeading:        renderHeading,
+		TypeQuote:          renderQuote,
+		TypeAside:          renderAside,
+		TypeList:           renderList,
+		TypeListItem:       renderListItem,
+		TypeLink:           renderLink,
+		TypeText:           renderText,
+		TypeLineBreak:      renderLineBreak,
+		TypeImage:          renderImageCard,
+		TypeBookmark:       renderBookmarkCard,
+		TypeCallout:        renderCalloutCard,
+		TypeCodeBlock:      renderCodeBlockCard,
+		TypeHTML:           renderHTMLCard,
+		TypeMarkdown:       renderMarkdownCard,
+		TypeButton:         renderButtonCard,
+		TypeToggle:         renderToggleCard,
+		TypeEmbed:          renderEmbedCard,
+		TypeHorizontalRule: renderHorizontalRule,
+	}}
+	return r
+}
+
+// Register replaces the renderer of a node type, e.g. to customize a card or support an unknown one
+func (r *Renderer) Register(nodeType string, fn RenderFunc) {
+	r.renderers[nodeType] = fn
+}
+
+// Render renders the whole document. It is safe to call concurrently as long as
+// Register is not called at the same time.
+func (r *Renderer) Render(doc *Document) (string, error) {
+	// heading ids are unique per document, so each call works on its own copy
+	render := &Renderer{renderers: r.renderers, headingIDs: map[string]int{}}
+
+	var buf bytes.Buffer
+	if err := render.RenderChildren(doc.Root.Children, &buf); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RenderChildren renders nodes in order. Nodes without a renderer are skipped.
+func (r *Renderer) RenderChildren(nodes Nodes, w *bytes.Buffer) error {
+	for _, node := range nodes {
+		fn, found := r.renderers[node.Type()]
+		if !found {
+			continue
+		}
+		if err := fn(r, node, w); err != nil {
+			return fmt.Errorf("cannot render %s node: %w", node.Type(), err)
+		}
+	}
+	return nil
+}
+
+// RenderHTML renders a document with the default renderer
+func RenderHTML(doc *Document) (string, error) {
+	return NewRenderer().Render(doc)
+}
+
+var attrEscaper = strings.NewReplacer(`&`, "&amp;", `"`, "&quot;", `<`, "&lt;", `>`, "&gt;")
+
+func attr(s string) string {
+	return attrEscaper.Replace(s)
+}
+
+func renderElement(r *Renderer, tag, attrs string, children Nodes, w *bytes.Buffer) error {
+	w.WriteString("<" + tag + attrs + ">")
+	if err := r.RenderChildren(children, w); err != nil {
+		return err
+	}
+	w.WriteString("</" + tag + ">")
+	return nil
+}
+
+func alignment(format string) string {
+	switch format {
+	case "center", "right", "justify":
+		return fmt.Sprintf(` style="text-align: %s;"`, format)
+	}
+	return ""
+}
+
+func renderParagraph(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*Paragraph)
+	if len(n.Children) == 0 {
+		return nil
+	}
+	return renderElement(r, "p", alignment(n.Format), n.Children, w)
+}
+
+func renderHeading(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*Heading)
+	tag := n.Tag
+	if tag == "" {
+		tag = "h2"
+	}
+
+	id := slugify(TextContent(n))
+	if r.headingIDs == nil {
+		r.headingIDs = map[string]int{}
+	}
+	if count := r.headingIDs[id]; count > 0 {
+		r.headingIDs[id] = count + 1
+		id = fmt.Sprintf("%s-%d", id, count)
+	} else {
+		r.headingIDs[id] = 1
+	}
+
+	return renderElement(r, tag, fmt.Sprintf(` id="%s"`, attr(id))+alignment(n.Format), n.Children, w)
+}
+
+// slugify builds heading ids the way Ghost does: lowercase, alphanumerics separated by dashes
+func slugify(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(s) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			dash = false
+			b.WriteRune(c)
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+func renderQuote(r *Renderer, node Node, w *bytes.Buffer) error {
+	return renderElement(r, "blockquote", "", node.(*Quote).Children, w)
+}
+
+func renderAside(r *Renderer, node Node, w *bytes.Buffer) error {
+	return renderElement(r, "blockquote", ` class="kg-blockquote-alt"`, node.(*Aside).Children, w)
+}
+
+func renderList(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*List)
+	tag := n.Tag
+	if tag == "" {
+		tag = "ul"
+		if n.ListType == ListNumber {
+			tag = "ol"
+		}
+	}
+	var attrs string
+	if tag == "ol" && n.Start > 1 {
+		attrs = fmt.Sprintf(` start="%d"`, n.Start)
+	}
+	return renderElement(r, tag, attrs, n.Children, w)
+}
+
+func renderListItem(r *Renderer, node Node, w *bytes.Buffer) error {
+	return renderElement(r, "li", "", node.(*ListItem).Children, w)
+}
+
+func renderLink(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*Link)
+	attrs := fmt.Sprintf(` href="%s"`, attr(n.URL))
+	if n.Rel != "" {
+		attrs += fmt.Sprintf(` rel="%s"`, attr(n.Rel))
+	}
+	if n.Target != "" {
+		attrs += fmt.Sprintf(` target="%s"`, attr(n.Target))
+	}
+	if n.Title != "" {
+		attrs += fmt.Sprintf(` title="%s"`, attr(n.Title))
+	}
+	return renderElement(r, "a", attrs, n.Children, w)
+}
+
+// formatTags are applied from the outside in
+var formatTags = []struct {
+	format Format
+	tag    string
+}{
+	{FormatBold, "strong"},
+	{FormatItalic, "em"},
+	{FormatStrikethrough, "s"},
+	{FormatUnderline, "u"},
+	{FormatCode, "code"},
+	{FormatSubscript, "sub"},
+	{FormatSuperscript, "sup"},
+	{FormatHighlight, "mark"},
+}
+
+func renderText(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*Text)
+	var closing []string
+	for _, ft := range formatTags {
+		if n.Format.Has(ft.format) {
+			w.WriteString("<" + ft.tag + ">")
+			closing = append(closing, "</"+ft.tag+">")
+		}
+	}
+	w.WriteString(html.EscapeString(n.Text))
+	for i := len(closing) - 1; i >= 0; i-- {
+		w.WriteString(closing[i])
+	}
+	return nil
+}
+
+func renderLineBreak(r *Renderer, node Node, w *bytes.Buffer) error {
+	w.WriteString("<br>")
+	return nil
+}
+
+func renderHorizontalRule(r *Renderer, node Node, w *bytes.Buffer) error {
+	w.WriteString("<hr>")
+	return nil
+}
+
+func renderImageCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*ImageCard)
+	if n.Src == "" {
+		return nil
+	}
+
+	classes := "kg-card kg-image-card"
+	if n.CardWidth != "" && n.CardWidth != "regular" {
+		classes += " kg-width-" + n.CardWidth
+	}
+	if n.Caption != "" {
+		classes += " kg-card-hascaption"
+	}
+
+	img := fmt.Sprintf(`<img src="%s" class="kg-image" alt="%s" loading="lazy"`, attr(n.Src), attr(n.Alt))
+	if n.Width > 0 && n.Height > 0 {
+		img += fmt.Sprintf(` width="%d" height="%d"`, n.Width, n.Height)
+	}
+	if n.Title != "" {
+		img += fmt.Sprintf(` title="%s"`, attr(n.Title))
+	}
+	img += ">"
+	if n.Href != "" {
+		img = fmt.Sprintf(`<a href="%s">%s</a>`, attr(n.Href), img)
+	}
+
+	fmt.Fprintf(w, `<figure class="%s">%s`, classes, img)
+	if n.Caption != "" {
+		fmt.Fprintf(w, "<figcaption>%s</figcaption>", n.Caption)
+	}
+	w.WriteString("</figure>")
+	return nil
+}
+
+func renderBookmarkCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*BookmarkCard)
+	if n.URL == "" {
+		return nil
+	}
+
+	classes := "kg-card kg-bookmark-card"
+	if n.Caption != "" {
+		classes += " kg-card-hascaption"
+	}
+
+	m := n.Metadata
+	fmt.Fprintf(w, `<figure class="%s"><a class="kg-bookmark-container" href="%s"><div class="kg-bookmark-content">`, classes, attr(n.URL))
+	fmt.Fprintf(w, `<div class="kg-bookmark-title">%s</div>`, html.EscapeString(m.Title))
+	fmt.Fprintf(w, `<div class="kg-bookmark-description">%s</div>`, html.EscapeString(m.Description))
+	w.WriteString(`<div class="kg-bookmark-metadata">`)
+	if m.Icon != "" {
+		fmt.Fprintf(w, `<img class="kg-bookmark-icon" src="%s" alt="">`, attr(m.Icon))
+	}
+	// Ghost renders the publisher in the author span and vice versa, themes style them accordingly
+	if m.Publisher != "" {
+		fmt.Fprintf(w, `<span class="kg-bookmark-author">%s</span>`, html.EscapeString(m.Publisher))
+	}
+	if m.Author != "" {
+		fmt.Fprintf(w, `<span class="kg-bookmark-publisher">%s</span>`, html.EscapeString(m.Author))
+	}
+	w.WriteString(`</div></div>`)
+	if m.Thumbnail != "" {
+		fmt.Fprintf(w, `<div class="kg-bookmark-thumbnail"><img src="%s" alt=""></div>`, attr(m.Thumbnail))
+	}
+	w.WriteString(`</a>`)
+	if n.Caption != "" {
+		fmt.Fprintf(w, "<figcaption>%s</figcaption>", n.Caption)
+	}
+	w.WriteString("</figure>")
+	return nil
+}
+
+func renderCalloutCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*CalloutCard)
+	color := n.BackgroundColor
+	if color == "" {
+		color = "grey"
+	}
+
+	fmt.Fprintf(w, `<div class="kg-card kg-callout-card kg-callout-card-%s">`, attr(color))
+	if n.CalloutEmoji != "" {
+		fmt.Fprintf(w, `<div class="kg-callout-emoji">%s</div>`, html.EscapeString(n.CalloutEmoji))
+	}
+	fmt.Fprintf(w, `<div class="kg-callout-text">%s</div></div>`, n.CalloutText)
+	return nil
+}
+
+func renderCodeBlockCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*CodeBlockCard)
+
+	code := "<pre><code"
+	if n.Language != "" {
+		code += fmt.Sprintf(` class="language-%s"`, attr(n.Language))
+	}
+	code += ">" + html.EscapeString(n.Code) + "</code></pre>"
+
+	if n.Caption == "" {
+		w.WriteString(code)
+		return nil
+	}
+	fmt.Fprintf(w, `<figure class="kg-card kg-code-card">%s<figcaption>%s</figcaption></figure>`, code, n.Caption)
+	return nil
+}
+
+func renderHTMLCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	fmt.Fprintf(w, "<!--kg-card-begin: html-->\n%s\n<!--kg-card-end: html-->", node.(*HTMLCard).HTML)
+	return nil
+}
+
+// markdown renders like Ghost: raw HTML is kept and headings get an id
+var markdown = goldmark.New(
+	goldmark.WithExtensions(extension.GFM, extension.Footnote),
+	goldmark.WithParserOptions(parser.WithAutoHeadingID()),
+	goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+)
+
+func renderMarkdownCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	w.WriteString("<!--kg-card-begin: markdown-->")
+	if err := markdown.Convert([]byte(node.(*MarkdownCard).Markdown), w); err != nil {
+		return err
+	}
+	w.WriteString("<!--kg-card-end: markdown-->")
+	return nil
+}
+
+func renderButtonCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*ButtonCard)
+	if n.ButtonURL == "" || n.ButtonText == "" {
+		return nil
+	}
+	align := n.Alignment
+	if align == "" {
+		align = "center"
+	}
+	fmt.Fprintf(w, `<div class="kg-card kg-button-card kg-align-%s"><a href="%s" class="kg-btn kg-btn-accent">%s</a></div>`,
+		attr(align), attr(n.ButtonURL), html.EscapeString(n.ButtonText))
+	return nil
+}
+
+const toggleIcon = `<svg id="Regular" xmlns="http://www.w3.org/2000/svg" viewBox="0 0 24 24"><path class="cls-1" d="M23.25,7.311,12.53,18.03a.749.749,0,0,1-1.06,0L.75,7.311"></path></svg>`
+
+func renderToggleCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*ToggleCard)
+	fmt.Fprintf(w, `<div class="kg-card kg-toggle-card" data-kg-toggle-state="close"><div class="kg-toggle-heading">`+
+		`<h4 class="kg-toggle-heading-text">%s</h4><button class="kg-toggle-card-icon" aria-label="Expand toggle to read content">%s</button></div>`+
+		`<div class="kg-toggle-content">%s</div></div>`, n.Heading, toggleIcon, n.Content)
+	return nil
+}
+
+func renderEmbedCard(r *Renderer, node Node, w *bytes.Buffer) error {
+	n := node.(*EmbedCard)
+	if n.HTML == "" {
+		return nil
+	}
+
+	classes := "kg-card kg-embed-card"
+	if n.Caption != "" {
+		classes += " kg-card-hascaption"
+	}
+	fmt.Fprintf(w, `<figure class="%s">%s`, classes, n.HTML)
+	if n.Caption != "" {
+		fmt.Fprintf(w, "<figcaption>%s</figcaption>", n.Caption)
+	}
+	w.WriteString("</figure>")
+	return nil
+}
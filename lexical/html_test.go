@@ -0,0 +1,104 @@
+package lexical
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func TestRenderHTMLGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range inputs {
+		input := input
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			data, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, err := Parse(string(data))
+			if err != nil {
+				t.Fatalf("Cannot parse document: %s", err)
+			}
+			got, err := RenderHTML(doc)
+			if err != nil {
+				t.Fatalf("Cannot render document: %s", err)
+			}
+
+			golden := strings.TrimSuffix(input, ".json") + ".html"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Missing golden file, run with -update: %s", err)
+			}
+			if got != string(want) {
+				t.Fatalf("HTML differs from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestCustomCardRenderer(t *testing.T) {
+	r := NewRenderer()
+	r.Register("paywall", func(r *Renderer, node Node, w *bytes.Buffer) error {
+		w.WriteString("<!--members-only-->")
+		return nil
+	})
+	r.Register(TypeImage, func(r *Renderer, node Node, w *bytes.Buffer) error {
+		w.WriteString(`<img src="` + node.(*ImageCard).Src + `">`)
+		return nil
+	})
+
+	doc, err := Parse(`{"root":{"children":[{"type":"image","src":"a.png"},{"type":"paywall","version":1}],"type":"root"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.Render(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `<img src="a.png"><!--members-only-->`; got != want {
+		t.Fatalf("Got %s, want %s", got, want)
+	}
+}
+
+func TestRenderConcurrently(t *testing.T) {
+	doc, err := Parse(`{"root":{"children":[` +
+		`{"type":"heading","tag":"h2","children":[{"type":"text","text":"Intro"}]},` +
+		`{"type":"heading","tag":"h2","children":[{"type":"text","text":"Intro"}]}` +
+		`],"type":"root"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// duplicate heading ids are counted per document, not per renderer
+	want := `<h2 id="intro">Intro</h2><h2 id="intro-1">Intro</h2>`
+	r := NewRenderer()
+	results := make(chan string, 8)
+	for i := 0; i < cap(results); i++ {
+		go func() {
+			got, err := r.Render(doc)
+			if err != nil {
+				got = err.Error()
+			}
+			results <- got
+		}()
+	}
+	for i := 0; i < cap(results); i++ {
+		if got := <-results; got != want {
+			t.Fatalf("Got %s, want %s", got, want)
+		}
+	}
+}
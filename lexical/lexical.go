@@ -0,0 +1,688 @@
+// Package lexical models the Lexical documents Ghost stores in Post.Lexical and Page.Lexical.
+//
+// Documents unmarshal into typed nodes; node types this package doesn't know are kept
+// as RawNode, and each typed node keeps its original type name and the fields it has no
+// typed counterpart for in Extra, so documents survive a round trip unchanged.
+package lexical
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Node types as serialized by Ghost's editor
+const (
+	TypeRoot           = "root"
+	TypeParagraph      = "paragraph"
+	TypeHeading        = "heading"
+	TypeQuote          = "quote"
+	TypeAside          = "aside"
+	TypeList           = "list"
+	TypeListItem       = "listitem"
+	TypeLink           = "link"
+	TypeText           = "text"
+	TypeLineBreak      = "linebreak"
+	TypeImage          = "image"
+	TypeBookmark       = "bookmark"
+	TypeCallout        = "callout"
+	TypeCodeBlock      = "codeblock"
+	TypeHTML           = "html"
+	TypeMarkdown       = "markdown"
+	TypeButton         = "button"
+	TypeToggle         = "toggle"
+	TypeEmbed          = "embed"
+	TypeHorizontalRule = "horizontalrule"
+)
+
+// Node is any node of a document tree
+type Node interface {
+	Type() string
+}
+
+// Parent is implemented by nodes that have children
+type Parent interface {
+	Node
+	GetChildren() Nodes
+}
+
+// Format is the bitmask of inline styles applied to a text node
+type Format int
+
+const (
+	FormatBold          Format = 1
+	FormatItalic        Format = 1 << 1
+	FormatStrikethrough Format = 1 << 2
+	FormatUnderline     Format = 1 << 3
+	FormatCode          Format = 1 << 4
+	FormatSubscript     Format = 1 << 5
+	FormatSuperscript   Format = 1 << 6
+	FormatHighlight     Format = 1 << 7
+)
+
+// Has reports whether all bits of f are set
+func (format Format) Has(f Format) bool {
+	return format&f == f
+}
+
+// Direction is the text direction of an element, empty means unset and is serialized as null
+type Direction string
+
+const (
+	DirectionLTR Direction = "ltr"
+	DirectionRTL Direction = "rtl"
+)
+
+func (d Direction) MarshalJSON() ([]byte, error) {
+	if d == "" {
+		return []byte("null"), nil
+	}
+	return json.Marshal(string(d))
+}
+
+// Extra keeps what a node was received with beyond its typed fields. It is written back
+// when the node is serialized; typed fields take precedence over extra fields of the same name.
+type Extra struct {
+	NodeType string                     `json:"-"` // serialized type if it differs from Type(), e.g. "extended-heading"
+	Fields   map[string]json.RawMessage `json:"-"`
+}
+
+func (e *Extra) extra() *Extra {
+	return e
+}
+
+// Element holds the fields shared by all nodes with children
+type Element struct {
+	Children  Nodes     `json:"children"`
+	Direction Direction `json:"direction"`
+	Format    string    `json:"format"` // alignment: "", "left", "center", "right", "justify"
+	Indent    int       `json:"indent"`
+	Version   int       `json:"version"`
+	Extra
+}
+
+func (e Element) GetChildren() Nodes {
+	return e.Children
+}
+
+// Document is the top level object stored in Post.Lexical: {"root": {...}}
+type Document struct {
+	Root Root `json:"root"`
+}
+
+type Root struct {
+	Element
+}
+
+type Paragraph struct {
+	Element
+}
+
+type Heading struct {
+	Element
+	Tag string `json:"tag"` // "h1" - "h6"
+}
+
+type Quote struct {
+	Element
+}
+
+// Aside is Ghost's alternative quote style
+type Aside struct {
+	Element
+}
+
+type ListType string
+
+const (
+	ListBullet ListType = "bullet"
+	ListNumber ListType = "number"
+	ListCheck  ListType = "check"
+)
+
+type List struct {
+	Element
+	ListType ListType `json:"listType"`
+	Start    int      `json:"start"`
+	Tag      string   `json:"tag"` // "ul" or "ol"
+}
+
+type ListItem struct {
+	Element
+	Value   int   `json:"value"`
+	Checked *bool `json:"checked,omitempty"`
+}
+
+type Link struct {
+	Element
+	URL    string `json:"url"`
+	Rel    string `json:"rel,omitempty"`
+	Target string `json:"target,omitempty"`
+	Title  string `json:"title,omitempty"`
+}
+
+type Text struct {
+	Detail  int    `json:"detail"`
+	Format  Format `json:"format"`
+	Mode    string `json:"mode"`
+	Style   string `json:"style"`
+	Text    string `json:"text"`
+	Version int    `json:"version"`
+	Extra
+}
+
+type LineBreak struct {
+	Version int `json:"version"`
+	Extra
+}
+
+type ImageCard struct {
+	Src       string `json:"src"`
+	Width     int    `json:"width,omitempty"`
+	Height    int    `json:"height,omitempty"`
+	Title     string `json:"title,omitempty"`
+	Alt       string `json:"alt,omitempty"`
+	Caption   string `json:"caption,omitempty"`   // HTML
+	CardWidth string `json:"cardWidth,omitempty"` // "regular", "wide" or "full"
+	Href      string `json:"href,omitempty"`
+	Version   int    `json:"version"`
+	Extra
+}
+
+type BookmarkMetadata struct {
+	Icon        string `json:"icon,omitempty"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Author      string `json:"author,omitempty"`
+	Publisher   string `json:"publisher,omitempty"`
+	Thumbnail   string `json:"thumbnail,omitempty"`
+}
+
+type BookmarkCard struct {
+	URL      string           `json:"url"`
+	Metadata BookmarkMetadata `json:"metadata"`
+	Caption  string           `json:"caption,omitempty"`
+	Version  int              `json:"version"`
+	Extra
+}
+
+type CalloutCard struct {
+	CalloutText     string `json:"calloutText"` // HTML
+	CalloutEmoji    string `json:"calloutEmoji"`
+	BackgroundColor string `json:"backgroundColor"` // "grey", "white", "blue", "green", "yellow", "red", "pink", "purple", "accent"
+	Version         int    `json:"version"`
+	Extra
+}
+
+type CodeBlockCard struct {
+	Code     string `json:"code"`
+	Language string `json:"language,omitempty"`
+	Caption  string `json:"caption,omitempty"`
+	Version  int    `json:"version"`
+	Extra
+}
+
+type HTMLCard struct {
+	HTML    string `json:"html"`
+	Version int    `json:"version"`
+	Extra
+}
+
+type MarkdownCard struct {
+	Markdown string `json:"markdown"`
+	Version  int    `json:"version"`
+	Extra
+}
+
+type ButtonCard struct {
+	ButtonText string `json:"buttonText"`
+	ButtonURL  string `json:"buttonUrl"`
+	Alignment  string `json:"alignment"` // "left" or "center"
+	Version    int    `json:"version"`
+	Extra
+}
+
+type ToggleCard struct {
+	Heading string `json:"heading"` // HTML
+	Content string `json:"content"` // HTML
+	Version int    `json:"version"`
+	Extra
+}
+
+type EmbedCard struct {
+	URL       string                 `json:"url"`
+	EmbedType string                 `json:"embedType,omitempty"` // "video", "rich", "photo", ...
+	HTML      string                 `json:"html"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Caption   string                 `json:"caption,omitempty"`
+	Version   int                    `json:"version"`
+	Extra
+}
+
+type HorizontalRule struct {
+	Version int `json:"version"`
+	Extra
+}
+
+// RawNode keeps a node of an unknown type as it was received
+type RawNode struct {
+	NodeType string
+	Raw      json.RawMessage
+}
+
+func (n *Root) Type() string           { return TypeRoot }
+func (n *Paragraph) Type() string      { return TypeParagraph }
+func (n *Heading) Type() string        { return TypeHeading }
+func (n *Quote) Type() string          { return TypeQuote }
+func (n *Aside) Type() string          { return TypeAside }
+func (n *List) Type() string           { return TypeList }
+func (n *ListItem) Type() string       { return TypeListItem }
+func (n *Link) Type() string           { return TypeLink }
+func (n *Text) Type() string           { return TypeText }
+func (n *LineBreak) Type() string      { return TypeLineBreak }
+func (n *ImageCard) Type() string      { return TypeImage }
+func (n *BookmarkCard) Type() string   { return TypeBookmark }
+func (n *CalloutCard) Type() string    { return TypeCallout }
+func (n *CodeBlockCard) Type() string  { return TypeCodeBlock }
+func (n *HTMLCard) Type() string       { return TypeHTML }
+func (n *MarkdownCard) Type() string   { return TypeMarkdown }
+func (n *ButtonCard) Type() string     { return TypeButton }
+func (n *ToggleCard) Type() string     { return TypeToggle }
+func (n *EmbedCard) Type() string      { return TypeEmbed }
+func (n *HorizontalRule) Type() string { return TypeHorizontalRule }
+func (n *RawNode) Type() string        { return n.NodeType }
+
+// newNode maps serialized types to nodes. Ghost's editor uses "extended-*" variants of the core nodes.
+func newNode(nodeType string) Node {
+	switch nodeType {
+	case TypeRoot:
+		return &Root{}
+	case TypeParagraph:
+		return &Paragraph{}
+	case TypeHeading, "extended-heading":
+		return &Heading{}
+	case TypeQuote, "extended-quote":
+		return &Quote{}
+	case TypeAside:
+		return &Aside{}
+	case TypeList:
+		return &List{}
+	case TypeListItem:
+		return &ListItem{}
+	case TypeLink:
+		return &Link{}
+	case TypeText, "extended-text":
+		return &Text{}
+	case TypeLineBreak:
+		return &LineBreak{}
+	case TypeImage:
+		return &ImageCard{}
+	case TypeBookmark:
+		return &BookmarkCard{}
+	case TypeCallout:
+		return &CalloutCard{}
+	case TypeCodeBlock:
+		return &CodeBlockCard{}
+	case TypeHTML:
+		return &HTMLCard{}
+	case TypeMarkdown:
+		return &MarkdownCard{}
+	case TypeButton:
+		return &ButtonCard{}
+	case TypeToggle:
+		return &ToggleCard{}
+	case TypeEmbed:
+		return &EmbedCard{}
+	case TypeHorizontalRule:
+		return &HorizontalRule{}
+	}
+	return nil
+}
+
+// Nodes is a list of child nodes, decoded into their concrete types
+type Nodes []Node
+
+func (nodes *Nodes) UnmarshalJSON(data []byte) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	result := make(Nodes, 0, len(raws))
+	for _, raw := range raws {
+		var header struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(raw, &header); err != nil {
+			return err
+		}
+
+		node := newNode(header.Type)
+		if node == nil {
+			result = append(result, &RawNode{NodeType: header.Type, Raw: append(json.RawMessage(nil), raw...)})
+			continue
+		}
+		if err := json.Unmarshal(raw, node); err != nil {
+			return fmt.Errorf("cannot decode %s node: %w", header.Type, err)
+		}
+		if err := keepExtra(node, raw); err != nil {
+			return fmt.Errorf("cannot decode %s node: %w", header.Type, err)
+		}
+		result = append(result, node)
+	}
+
+	*nodes = result
+	return nil
+}
+
+// keepExtra records the type name and the fields of raw that node doesn't serialize itself
+func keepExtra(node Node, raw []byte) error {
+	holder, ok := node.(interface{ extra() *Extra })
+	if !ok {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+	typed, err := json.Marshal(node)
+	if err != nil {
+		return err
+	}
+	var known map[string]json.RawMessage
+	if err := json.Unmarshal(typed, &known); err != nil {
+		return err
+	}
+
+	extra := holder.extra()
+	var nodeType string
+	if err := json.Unmarshal(fields["type"], &nodeType); err == nil && nodeType != node.Type() {
+		extra.NodeType = nodeType
+	}
+	for key, value := range fields {
+		if _, found := known[key]; found {
+			continue
+		}
+		if extra.Fields == nil {
+			extra.Fields = map[string]json.RawMessage{}
+		}
+		extra.Fields[key] = value
+	}
+	return nil
+}
+
+func (nodes Nodes) MarshalJSON() ([]byte, error) {
+	if nodes == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]Node(nodes))
+}
+
+// marshalNode serializes v and adds the node type in front of its fields and the extra fields after them
+func marshalNode(nodeType string, extra Extra, v interface{}) ([]byte, error) {
+	fields, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	if extra.NodeType != "" {
+		nodeType = extra.NodeType
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString(`{"type":`)
+	typeName, _ := json.Marshal(nodeType)
+	buf.Write(typeName)
+	if len(fields) > 2 {
+		buf.WriteByte(',')
+		buf.Write(fields[1 : len(fields)-1])
+	}
+
+	if len(extra.Fields) > 0 {
+		var known map[string]json.RawMessage
+		if err := json.Unmarshal(fields, &known); err != nil {
+			return nil, err
+		}
+		keys := make([]string, 0, len(extra.Fields))
+		for key := range extra.Fields {
+			if _, found := known[key]; !found && key != "type" {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name, _ := json.Marshal(key)
+			buf.WriteByte(',')
+			buf.Write(name)
+			buf.WriteByte(':')
+			buf.Write(extra.Fields[key])
+		}
+	}
+
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func defaultVersion(version int) int {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
+
+func (e Element) withDefaults() Element {
+	e.Version = defaultVersion(e.Version)
+	return e
+}
+
+func (n *Root) UnmarshalJSON(data []byte) error {
+	type root Root
+	if err := json.Unmarshal(data, (*root)(n)); err != nil {
+		return err
+	}
+	return keepExtra(n, data)
+}
+
+func (n Root) MarshalJSON() ([]byte, error) {
+	type root Root
+	n.Element = n.Element.withDefaults()
+	return marshalNode(TypeRoot, n.Extra, root(n))
+}
+
+func (n Paragraph) MarshalJSON() ([]byte, error) {
+	type paragraph Paragraph
+	n.Element = n.Element.withDefaults()
+	return marshalNode(TypeParagraph, n.Extra, paragraph(n))
+}
+
+func (n Heading) MarshalJSON() ([]byte, error) {
+	type heading Heading
+	n.Element = n.Element.withDefaults()
+	if n.Tag == "" {
+		n.Tag = "h2"
+	}
+	return marshalNode(TypeHeading, n.Extra, heading(n))
+}
+
+func (n Quote) MarshalJSON() ([]byte, error) {
+	type quote Quote
+	n.Element = n.Element.withDefaults()
+	return marshalNode(TypeQuote, n.Extra, quote(n))
+}
+
+func (n Aside) MarshalJSON() ([]byte, error) {
+	type aside Aside
+	n.Element = n.Element.withDefaults()
+	return marshalNode(TypeAside, n.Extra, aside(n))
+}
+
+func (n List) MarshalJSON() ([]byte, error) {
+	type list List
+	n.Element = n.Element.withDefaults()
+	if n.ListType == "" {
+		n.ListType = ListBullet
+	}
+	if n.Start == 0 {
+		n.Start = 1
+	}
+	if n.Tag == "" {
+		n.Tag = "ul"
+		if n.ListType == ListNumber {
+			n.Tag = "ol"
+		}
+	}
+	return marshalNode(TypeList, n.Extra, list(n))
+}
+
+func (n ListItem) MarshalJSON() ([]byte, error) {
+	type listItem ListItem
+	n.Element = n.Element.withDefaults()
+	if n.Value == 0 {
+		n.Value = 1
+	}
+	return marshalNode(TypeListItem, n.Extra, listItem(n))
+}
+
+func (n Link) MarshalJSON() ([]byte, error) {
+	type link Link
+	n.Element = n.Element.withDefaults()
+	return marshalNode(TypeLink, n.Extra, link(n))
+}
+
+func (n Text) MarshalJSON() ([]byte, error) {
+	type text Text
+	n.Version = defaultVersion(n.Version)
+	if n.Mode == "" {
+		n.Mode = "normal"
+	}
+	return marshalNode(TypeText, n.Extra, text(n))
+}
+
+func (n LineBreak) MarshalJSON() ([]byte, error) {
+	type lineBreak LineBreak
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeLineBreak, n.Extra, lineBreak(n))
+}
+
+func (n ImageCard) MarshalJSON() ([]byte, error) {
+	type imageCard ImageCard
+	n.Version = defaultVersion(n.Version)
+	if n.CardWidth == "" {
+		n.CardWidth = "regular"
+	}
+	return marshalNode(TypeImage, n.Extra, imageCard(n))
+}
+
+func (n BookmarkCard) MarshalJSON() ([]byte, error) {
+	type bookmarkCard BookmarkCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeBookmark, n.Extra, bookmarkCard(n))
+}
+
+func (n CalloutCard) MarshalJSON() ([]byte, error) {
+	type calloutCard CalloutCard
+	n.Version = defaultVersion(n.Version)
+	if n.BackgroundColor == "" {
+		n.BackgroundColor = "grey"
+	}
+	return marshalNode(TypeCallout, n.Extra, calloutCard(n))
+}
+
+func (n CodeBlockCard) MarshalJSON() ([]byte, error) {
+	type codeBlockCard CodeBlockCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeCodeBlock, n.Extra, codeBlockCard(n))
+}
+
+func (n HTMLCard) MarshalJSON() ([]byte, error) {
+	type htmlCard HTMLCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeHTML, n.Extra, htmlCard(n))
+}
+
+func (n MarkdownCard) MarshalJSON() ([]byte, error) {
+	type markdownCard MarkdownCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeMarkdown, n.Extra, markdownCard(n))
+}
+
+func (n ButtonCard) MarshalJSON() ([]byte, error) {
+	type buttonCard ButtonCard
+	n.Version = defaultVersion(n.Version)
+	if n.Alignment == "" {
+		n.Alignment = "center"
+	}
+	return marshalNode(TypeButton, n.Extra, buttonCard(n))
+}
+
+func (n ToggleCard) MarshalJSON() ([]byte, error) {
+	type toggleCard ToggleCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeToggle, n.Extra, toggleCard(n))
+}
+
+func (n EmbedCard) MarshalJSON() ([]byte, error) {
+	type embedCard EmbedCard
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeEmbed, n.Extra, embedCard(n))
+}
+
+func (n HorizontalRule) MarshalJSON() ([]byte, error) {
+	type horizontalRule HorizontalRule
+	n.Version = defaultVersion(n.Version)
+	return marshalNode(TypeHorizontalRule, n.Extra, horizontalRule(n))
+}
+
+func (n RawNode) MarshalJSON() ([]byte, error) {
+	return n.Raw, nil
+}
+
+// Parse decodes a serialized document as found in Post.Lexical
+func Parse(data string) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// String serializes the document for Post.Lexical
+func (d *Document) String() (string, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// Walk calls fn for every node in depth-first order. Returning false skips the node's children.
+func (d *Document) Walk(fn func(node Node) bool) {
+	walk(&d.Root, fn)
+}
+
+func walk(node Node, fn func(node Node) bool) {
+	if !fn(node) {
+		return
+	}
+	if parent, ok := node.(Parent); ok {
+		for _, child := range parent.GetChildren() {
+			walk(child, fn)
+		}
+	}
+}
+
+// TextContent returns the concatenated text of a node and its children
+func TextContent(node Node) string {
+	var buf bytes.Buffer
+	walk(node, func(n Node) bool {
+		switch n := n.(type) {
+		case *Text:
+			buf.WriteString(n.Text)
+		case *LineBreak:
+			buf.WriteByte('\n')
+		}
+		return true
+	})
+	return buf.String()
+}
@@ -0,0 +1,180 @@
+package lexical
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const ghostDocument = `{"root":{"children":[` +
+	`{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Title","type":"extended-text","version":1}],"direction":"ltr","format":"","indent":0,"type":"extended-heading","version":1,"tag":"h2"},` +
+	`{"children":[{"detail":0,"format":3,"mode":"normal","style":"","text":"bold italic","type":"text","version":1},` +
+	`{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"link","type":"text","version":1}],"direction":"ltr","format":"","indent":0,"type":"link","version":1,"rel":"noreferrer","target":null,"title":null,"url":"https://ghost.org"}],"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1},` +
+	`{"type":"image","version":1,"src":"https://example.com/a.jpg","width":800,"height":600,"title":"","alt":"A","caption":"","cardWidth":"wide","href":""},` +
+	`{"type":"callout","version":1,"calloutText":"<p>Note</p>","calloutEmoji":"💡","backgroundColor":"blue"},` +
+	`{"type":"paywall","version":1}` +
+	`],"direction":"ltr","format":"","indent":0,"type":"root","version":1}}`
+
+func TestParseGhostDocument(t *testing.T) {
+	doc, err := Parse(ghostDocument)
+	if err != nil {
+		t.Fatalf("Cannot parse document: %s", err)
+	}
+
+	children := doc.Root.Children
+	if len(children) != 5 {
+		t.Fatalf("Expected 5 top level nodes, got %d", len(children))
+	}
+
+	heading, ok := children[0].(*Heading)
+	if !ok || heading.Tag != "h2" || TextContent(heading) != "Title" {
+		t.Fatalf("Unexpected heading: %#v", children[0])
+	}
+
+	paragraph := children[1].(*Paragraph)
+	text := paragraph.Children[0].(*Text)
+	if !text.Format.Has(FormatBold|FormatItalic) || text.Format.Has(FormatCode) {
+		t.Fatalf("Unexpected format %d", text.Format)
+	}
+	link := paragraph.Children[1].(*Link)
+	if link.URL != "https://ghost.org" || link.Rel != "noreferrer" {
+		t.Fatalf("Unexpected link: %#v", link)
+	}
+
+	image := children[2].(*ImageCard)
+	if image.Src != "https://example.com/a.jpg" || image.CardWidth != "wide" || image.Width != 800 {
+		t.Fatalf("Unexpected image: %#v", image)
+	}
+
+	if callout := children[3].(*CalloutCard); callout.BackgroundColor != "blue" {
+		t.Fatalf("Unexpected callout: %#v", callout)
+	}
+
+	raw, ok := children[4].(*RawNode)
+	if !ok || raw.Type() != "paywall" {
+		t.Fatalf("Expected unknown node to be kept raw, got %#v", children[4])
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc, err := Parse(ghostDocument)
+	if err != nil {
+		t.Fatalf("Cannot parse document: %s", err)
+	}
+	serialized, err := doc.String()
+	if err != nil {
+		t.Fatalf("Cannot serialize document: %s", err)
+	}
+	again, err := Parse(serialized)
+	if err != nil {
+		t.Fatalf("Cannot parse serialized document: %s", err)
+	}
+	if !reflect.DeepEqual(doc, again) {
+		t.Fatalf("Document changed after round trip:\n%s", serialized)
+	}
+}
+
+// editorDocument is Ghost editor output with extended nodes and fields without a typed counterpart
+const editorDocument = `{"root":{"children":[` +
+	`{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"Title","type":"extended-text","version":1}],"direction":"ltr","format":"","indent":0,"type":"extended-heading","version":1,"tag":"h2"},` +
+	`{"children":[{"detail":0,"format":2,"mode":"normal","style":"","text":"Quoted","type":"extended-text","version":1}],"direction":"ltr","format":"","indent":0,"type":"extended-quote","version":1},` +
+	`{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"see ","type":"extended-text","version":1},` +
+	`{"children":[{"detail":0,"format":0,"mode":"normal","style":"","text":"docs","type":"extended-text","version":1}],"direction":"ltr","format":"","indent":0,"type":"link","version":1,"rel":null,"target":null,"title":null,"url":"https://ghost.org/docs/"}],` +
+	`"direction":"ltr","format":"","indent":0,"type":"paragraph","version":1,"textFormat":0,"textStyle":""},` +
+	`{"type":"image","version":1,"src":"https://example.com/a.jpg","width":800,"height":600,"title":"","alt":"","caption":"","cardWidth":"regular","href":""},` +
+	`{"type":"callout","version":1,"calloutText":"<p>Note</p>","calloutEmoji":"💡","backgroundColor":"grey","visibility":{"web":{"nonMember":true}}},` +
+	`{"type":"paywall","version":1}` +
+	`],"direction":"ltr","format":"","indent":0,"type":"root","version":1}}`
+
+func TestRoundTripKeepsEditorOutput(t *testing.T) {
+	for name, document := range map[string]string{"ghost": ghostDocument, "editor": editorDocument} {
+		doc, err := Parse(document)
+		if err != nil {
+			t.Fatalf("%s: cannot parse document: %s", name, err)
+		}
+		if _, ok := doc.Root.Children[0].(*Heading); !ok {
+			t.Fatalf("%s: expected extended-heading to decode as Heading, got %#v", name, doc.Root.Children[0])
+		}
+		serialized, err := doc.String()
+		if err != nil {
+			t.Fatalf("%s: cannot serialize document: %s", name, err)
+		}
+
+		var want, got interface{}
+		if err := json.Unmarshal([]byte(document), &want); err != nil {
+			t.Fatal(err)
+		}
+		if err := json.Unmarshal([]byte(serialized), &got); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(want, got) {
+			t.Errorf("%s: document changed after round trip:\n%s", name, serialized)
+		}
+	}
+}
+
+func TestTypedFieldsWinOverExtra(t *testing.T) {
+	doc, err := Parse(editorDocument)
+	if err != nil {
+		t.Fatalf("Cannot parse document: %s", err)
+	}
+	link := doc.Root.Children[2].(*Paragraph).Children[1].(*Link)
+	link.Target = "_blank"
+
+	serialized, err := doc.String()
+	if err != nil {
+		t.Fatalf("Cannot serialize document: %s", err)
+	}
+	if !strings.Contains(serialized, `"target":"_blank"`) || strings.Contains(serialized, `"target":null`) {
+		t.Fatalf("Expected the typed target to replace null: %s", serialized)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	doc := New(
+		NewHeading(3, NewText("Hello")),
+		NewList(ListNumber, NewListItem(NewText("one")), NewListItem(NewText("two", FormatCode))),
+		&CodeBlockCard{Code: "fmt.Println()", Language: "go"},
+	)
+	serialized, err := doc.String()
+	if err != nil {
+		t.Fatalf("Cannot serialize document: %s", err)
+	}
+
+	var generic map[string]interface{}
+	if err := json.Unmarshal([]byte(serialized), &generic); err != nil {
+		t.Fatalf("Invalid JSON: %s", err)
+	}
+	root := generic["root"].(map[string]interface{})
+	if root["type"] != "root" {
+		t.Fatalf("Expected root type, got %v", root["type"])
+	}
+	list := root["children"].([]interface{})[1].(map[string]interface{})
+	if list["type"] != "list" || list["tag"] != "ol" || list["listType"] != "number" {
+		t.Fatalf("Unexpected list: %v", list)
+	}
+	second := list["children"].([]interface{})[1].(map[string]interface{})
+	if second["value"] != float64(2) {
+		t.Fatalf("Expected second item to have value 2, got %v", second["value"])
+	}
+	text := second["children"].([]interface{})[0].(map[string]interface{})
+	if text["type"] != "text" || text["format"] != float64(FormatCode) || text["version"] != float64(1) {
+		t.Fatalf("Unexpected text: %v", text)
+	}
+	code := root["children"].([]interface{})[2].(map[string]interface{})
+	if code["type"] != "codeblock" || code["language"] != "go" {
+		t.Fatalf("Unexpected code card: %v", code)
+	}
+}
+
+func TestEmptyElementHasChildrenArray(t *testing.T) {
+	data, err := json.Marshal(NewParagraph())
+	if err != nil {
+		t.Fatalf("Cannot serialize paragraph: %s", err)
+	}
+	want := `{"type":"paragraph","children":[],"direction":"ltr","format":"","indent":0,"version":1}`
+	if string(data) != want {
+		t.Fatalf("Got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,238 @@
+package ghost
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+	"github.com/sklinkert/ghost/markdown"
+	"gopkg.in/yaml.v3"
+)
+
+// MarkdownFrontMatter is the YAML block at the top of a Markdown post.
+// Authors are emails, or slugs for authors without a known email.
+type MarkdownFrontMatter struct {
+	ID                  string   `yaml:"id,omitempty"`
+	Title               string   `yaml:"title,omitempty"`
+	Slug                string   `yaml:"slug,omitempty"`
+	Status              string   `yaml:"status,omitempty"`
+	Visibility          string   `yaml:"visibility,omitempty"`
+	Featured            bool     `yaml:"featured,omitempty"`
+	Tags                []string `yaml:"tags,omitempty"`
+	Authors             []string `yaml:"authors,omitempty"`
+	CreatedAt           string   `yaml:"created_at,omitempty"`
+	UpdatedAt           string   `yaml:"updated_at,omitempty"`
+	PublishedAt         string   `yaml:"published_at,omitempty"`
+	FeatureImage        string   `yaml:"feature_image,omitempty"`
+	FeatureImageAlt     string   `yaml:"feature_image_alt,omitempty"`
+	FeatureImageCaption string   `yaml:"feature_image_caption,omitempty"`
+	CanonicalURL        string   `yaml:"canonical_url,omitempty"`
+	Excerpt             string   `yaml:"excerpt,omitempty"`
+	MetaTitle           string   `yaml:"meta_title,omitempty"`
+	MetaDescription     string   `yaml:"meta_description,omitempty"`
+	OGImage             string   `yaml:"og_image,omitempty"`
+	OGTitle             string   `yaml:"og_title,omitempty"`
+	OGDescription       string   `yaml:"og_description,omitempty"`
+	TwitterImage        string   `yaml:"twitter_image,omitempty"`
+	TwitterTitle        string   `yaml:"twitter_title,omitempty"`
+	TwitterDescription  string   `yaml:"twitter_description,omitempty"`
+	CustomTemplate      string   `yaml:"custom_template,omitempty"`
+}
+
+// ParseMarkdownPost converts Markdown with optional YAML front matter into a post with Lexical content.
+// Without a title in the front matter a leading level 1 heading becomes the title.
+func ParseMarkdownPost(src []byte) (Post, error) {
+	var post Post
+
+	rawFrontMatter, body := markdown.SplitFrontMatter(src)
+	var frontMatter MarkdownFrontMatter
+	if err := yaml.Unmarshal(rawFrontMatter, &frontMatter); err != nil {
+		return post, fmt.Errorf("invalid front matter: %w", err)
+	}
+
+	doc, err := markdown.ToLexical(body)
+	if err != nil {
+		return post, err
+	}
+
+	if frontMatter.Title == "" && len(doc.Root.Children) > 0 {
+		if heading, ok := doc.Root.Children[0].(*lexical.Heading); ok && heading.Tag == "h1" {
+			frontMatter.Title = lexical.TextContent(heading)
+			doc.Root.Children = doc.Root.Children[1:]
+		}
+	}
+
+	post = frontMatter.post()
+	if err := post.SetLexical(doc); err != nil {
+		return post, err
+	}
+	return post, nil
+}
+
+func (f MarkdownFrontMatter) post() Post {
+	post := Post{
+		ID:                  f.ID,
+		Title:               f.Title,
+		Slug:                f.Slug,
+		Status:              f.Status,
+		Visibility:          f.Visibility,
+		Featured:            f.Featured,
+		CreatedAt:           f.CreatedAt,
+		UpdatedAt:           f.UpdatedAt,
+		PublishedAt:         f.PublishedAt,
+		FeatureImage:        f.FeatureImage,
+		FeatureImageAlt:     f.FeatureImageAlt,
+		FeatureImageCaption: f.FeatureImageCaption,
+		CanonicalURL:        f.CanonicalURL,
+		CustomExcerpt:       f.Excerpt,
+		MetaTitle:           f.MetaTitle,
+		MetaDescription:     f.MetaDescription,
+		OGImage:             f.OGImage,
+		OGTitle:             f.OGTitle,
+		OGDescription:       f.OGDescription,
+		TwitterImage:        f.TwitterImage,
+		TwitterTitle:        f.TwitterTitle,
+		TwitterDescription:  f.TwitterDescription,
+		CustomTemplate:      f.CustomTemplate,
+	}
+	for _, name := range f.Tags {
+		post.Tags = append(post.Tags, Tag{Name: name})
+	}
+	for _, author := range f.Authors {
+		if strings.Contains(author, "@") {
+			post.Authors = append(post.Authors, Author{Email: author})
+		} else {
+			post.Authors = append(post.Authors, Author{Slug: author})
+		}
+	}
+	return post
+}
+
+func markdownFrontMatter(post Post) MarkdownFrontMatter {
+	f := MarkdownFrontMatter{
+		ID:                  post.ID,
+		Title:               post.Title,
+		Slug:                post.Slug,
+		Status:              post.Status,
+		Visibility:          post.Visibility,
+		Featured:            post.Featured,
+		CreatedAt:           post.CreatedAt,
+		UpdatedAt:           post.UpdatedAt,
+		PublishedAt:         post.PublishedAt,
+		FeatureImage:        post.FeatureImage,
+		FeatureImageAlt:     post.FeatureImageAlt,
+		FeatureImageCaption: post.FeatureImageCaption,
+		CanonicalURL:        post.CanonicalURL,
+		Excerpt:             post.CustomExcerpt,
+		MetaTitle:           post.MetaTitle,
+		MetaDescription:     post.MetaDescription,
+		OGImage:             post.OGImage,
+		OGTitle:             post.OGTitle,
+		OGDescription:       post.OGDescription,
+		TwitterImage:        post.TwitterImage,
+		TwitterTitle:        post.TwitterTitle,
+		TwitterDescription:  post.TwitterDescription,
+		CustomTemplate:      post.CustomTemplate,
+	}
+	for _, tag := range post.Tags {
+		f.Tags = append(f.Tags, tag.Name)
+	}
+	for _, author := range post.Authors {
+		if author.Email != "" {
+			f.Authors = append(f.Authors, author.Email)
+		} else {
+			f.Authors = append(f.Authors, author.Slug)
+		}
+	}
+	return f
+}
+
+// MarshalMarkdownPost converts a post into Markdown with YAML front matter, ParseMarkdownPost reverses it.
+// The body is converted from the Lexical content, posts without Lexical or with cards Markdown can't express
+// fall back to the rendered HTML.
+func MarshalMarkdownPost(post Post) ([]byte, error) {
+	body, err := markdownBody(post)
+	if err != nil {
+		return nil, err
+	}
+
+	frontMatter, err := yaml.Marshal(markdownFrontMatter(post))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("---\n")
+	buf.Write(frontMatter)
+	buf.WriteString("---\n\n")
+	buf.WriteString(body)
+	return buf.Bytes(), nil
+}
+
+func markdownBody(post Post) (string, error) {
+	if post.Lexical != "" {
+		doc, err := post.LexicalDocument()
+		if err != nil {
+			return "", err
+		}
+		body, err := markdown.FromLexical(doc)
+		if err == nil || !errors.Is(err, markdown.ErrUnsupportedNode) || post.HTML == "" {
+			return body, err
+		}
+	}
+	return markdown.FromHTML(post.HTML)
+}
+
+// ExportMarkdownPosts writes every post to <dir>/<slug>.md and returns the written paths
+func (g *Ghost) ExportMarkdownPosts(dir string) ([]string, error) {
+	var paths []string
+
+	posts, err := g.AdminGetPosts()
+	if err != nil {
+		return paths, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return paths, err
+	}
+
+	for _, post := range posts.Posts {
+		data, err := MarshalMarkdownPost(post)
+		if err != nil {
+			return paths, fmt.Errorf("cannot convert post %q: %w", post.Slug, err)
+		}
+		path := filepath.Join(dir, post.Slug+".md")
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// CreatePostFromMarkdown creates a post from Markdown with YAML front matter, see ParseMarkdownPost.
+// The id and updated_at of exported posts are ignored.
+func (g *Ghost) CreatePostFromMarkdown(src []byte) (Posts, error) {
+	post, err := ParseMarkdownPost(src)
+	if err != nil {
+		return Posts{}, err
+	}
+	post.ID, post.UpdatedAt = "", ""
+	return g.AdminCreatePost(post)
+}
+
+// UpdatePostFromMarkdown updates the post identified by the id in the front matter. Ghost rejects the
+// update if the post changed since the updated_at in the front matter.
+func (g *Ghost) UpdatePostFromMarkdown(src []byte) error {
+	post, err := ParseMarkdownPost(src)
+	if err != nil {
+		return err
+	}
+	if post.ID == "" || post.UpdatedAt == "" {
+		return fmt.Errorf("front matter needs id and updated_at to update a post")
+	}
+	return g.AdminUpdatePost(post, "")
+}
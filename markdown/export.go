@@ -0,0 +1,282 @@
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+)
+
+// ErrUnsupportedNode is returned by FromLexical for nodes it can't express in Markdown
+var ErrUnsupportedNode = errors.New("node not supported in markdown")
+
+// FromLexical converts a Lexical document into Markdown that ToLexical reads back into the same document.
+// Cards without a Markdown counterpart (bookmarks, buttons, toggles, non-video embeds) are kept as HTML,
+// text formats without Markdown syntax (underline, highlight, sub- and superscript) are dropped.
+func FromLexical(doc *lexical.Document) (string, error) {
+	return blocks(doc.Root.Children)
+}
+
+func blocks(nodes lexical.Nodes) (string, error) {
+	var result []string
+	for _, node := range nodes {
+		text, err := block(node)
+		if err != nil {
+			return "", err
+		}
+		if text != "" {
+			result = append(result, text)
+		}
+	}
+	if len(result) == 0 {
+		return "", nil
+	}
+	return strings.Join(result, "\n\n") + "\n", nil
+}
+
+func block(node lexical.Node) (string, error) {
+	switch n := node.(type) {
+	case *lexical.Paragraph:
+		text, err := inlines(n.Children)
+		return escapeLineStart(text), err
+	case *lexical.Heading:
+		text, err := inlines(n.Children)
+		level, _ := strconv.Atoi(strings.TrimPrefix(n.Tag, "h"))
+		if level < 1 || level > 6 {
+			level = 1
+		}
+		return strings.Repeat("#", level) + " " + text, err
+	case *lexical.Quote:
+		return quote(n.Children)
+	case *lexical.Aside:
+		return quote(n.Children)
+	case *lexical.List:
+		var b strings.Builder
+		err := list(&b, n, "")
+		return strings.TrimSuffix(b.String(), "\n"), err
+	case *lexical.HorizontalRule:
+		return "---", nil
+	case *lexical.ImageCard:
+		image := fmt.Sprintf("![%s](%s%s)", escapeText(n.Alt), destination(n.Src), title(html.UnescapeString(n.Caption)))
+		if n.Href != "" {
+			image = fmt.Sprintf("[%s](%s)", image, destination(n.Href))
+		}
+		return image, nil
+	case *lexical.CodeBlockCard:
+		fence := "```"
+		for strings.Contains(n.Code, fence) {
+			fence += "`"
+		}
+		return fence + n.Language + "\n" + n.Code + "\n" + fence, nil
+	case *lexical.HTMLCard:
+		return strings.TrimSpace(n.HTML), nil
+	case *lexical.MarkdownCard:
+		return strings.TrimSpace(n.Markdown), nil
+	case *lexical.CalloutCard:
+		return callout(n)
+	case *lexical.EmbedCard:
+		if VideoEmbed(n.URL) != nil {
+			return n.URL, nil
+		}
+		return renderedHTML(n)
+	case *lexical.BookmarkCard, *lexical.ButtonCard, *lexical.ToggleCard:
+		return renderedHTML(n)
+	}
+	return "", fmt.Errorf("%w: %s", ErrUnsupportedNode, node.Type())
+}
+
+func renderedHTML(node lexical.Node) (string, error) {
+	return lexical.RenderHTML(lexical.New(node))
+}
+
+func quote(children lexical.Nodes) (string, error) {
+	text, err := inlines(children)
+	if err != nil {
+		return "", err
+	}
+	// two line breaks separate the paragraphs of a quote, see convertBlockquote
+	text = strings.ReplaceAll(text, "\\\n\\\n", "\n\n")
+	return prefixLines(text, "> "), nil
+}
+
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimSpace(prefix)
+			continue
+		}
+		lines[i] = prefix + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// callout writes a GitHub style alert, a colour or emoji the alert's style doesn't have follows the kind
+func callout(n *lexical.CalloutCard) (string, error) {
+	kind := "NOTE"
+	for name, style := range calloutStyles {
+		if style.color == n.BackgroundColor {
+			kind = name
+			break
+		}
+	}
+
+	marker := kind
+	style := calloutStyles[kind]
+	color := n.BackgroundColor
+	if color == "" {
+		color = "grey"
+	}
+	if n.CalloutEmoji != style.emoji {
+		marker += "|" + color + "|" + n.CalloutEmoji
+	} else if color != style.color {
+		marker += "|" + color
+	}
+
+	content, err := FromHTML(n.CalloutText)
+	if err != nil {
+		return "", err
+	}
+	return prefixLines("[!"+marker+"]\n"+strings.TrimSuffix(content, "\n"), "> "), nil
+}
+
+// list writes one line per item, nested lists live in their own list item
+func list(b *strings.Builder, n *lexical.List, indent string) error {
+	number := n.Start
+	if number < 1 {
+		number = 1
+	}
+
+	for _, child := range n.Children {
+		item, ok := child.(*lexical.ListItem)
+		if !ok {
+			return fmt.Errorf("%w: %s in list", ErrUnsupportedNode, child.Type())
+		}
+
+		var marker string
+		switch n.ListType {
+		case lexical.ListNumber:
+			marker = strconv.Itoa(number) + ". "
+		case lexical.ListCheck:
+			marker = "- [ ] "
+			if item.Checked != nil && *item.Checked {
+				marker = "- [x] "
+			}
+		default:
+			marker = "- "
+		}
+
+		if len(item.Children) == 1 {
+			if nested, ok := item.Children[0].(*lexical.List); ok {
+				if err := list(b, nested, indent+strings.Repeat(" ", len(marker))); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		text, err := inlines(item.Children)
+		if err != nil {
+			return err
+		}
+		continuation := strings.Repeat(" ", len(marker))
+		b.WriteString(indent + marker + strings.ReplaceAll(escapeLineStart(text), "\n", "\n"+indent+continuation) + "\n")
+		number++
+	}
+	return nil
+}
+
+func inlines(nodes lexical.Nodes) (string, error) {
+	var b strings.Builder
+	for _, node := range nodes {
+		switch n := node.(type) {
+		case *lexical.Text:
+			b.WriteString(formattedText(n.Text, n.Format))
+		case *lexical.LineBreak:
+			b.WriteString("\\\n")
+		case *lexical.Link:
+			text, err := inlines(n.Children)
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&b, "[%s](%s%s)", text, destination(n.URL), title(n.Title))
+		default:
+			return "", fmt.Errorf("%w: %s in text", ErrUnsupportedNode, node.Type())
+		}
+	}
+	return b.String(), nil
+}
+
+// formattedText keeps surrounding whitespace outside of the emphasis markers, "** bold**" is no emphasis
+func formattedText(text string, format lexical.Format) string {
+	core := strings.TrimSpace(text)
+	if core == "" {
+		return text
+	}
+	start := strings.Index(text, core)
+	leading, trailing := text[:start], text[start+len(core):]
+
+	if format.Has(lexical.FormatCode) {
+		fence := "`"
+		for strings.Contains(core, fence) {
+			fence += "`"
+		}
+		if strings.HasPrefix(core, "`") || strings.HasSuffix(core, "`") {
+			core = " " + core + " "
+		}
+		core = fence + core + fence
+	} else {
+		core = escapeText(core)
+	}
+
+	if format.Has(lexical.FormatStrikethrough) {
+		core = "~~" + core + "~~"
+	}
+	if format.Has(lexical.FormatItalic) {
+		core = "*" + core + "*"
+	}
+	if format.Has(lexical.FormatBold) {
+		core = "**" + core + "**"
+	}
+	return leading + core + trailing
+}
+
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`, "*", `\*`, "_", `\_`, "`", "\\`", "[", `\[`, "]", `\]`, "<", `\<`, "~", `\~`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// blockStart matches text that would otherwise start a heading, list, quote or thematic break
+var blockStart = regexp.MustCompile(`^(#{1,6}(\s|$)|[-+]\s|>|\d{1,9}[.)](\s|$)|=+\s*$|-{3,}\s*$)`)
+
+func escapeLineStart(text string) string {
+	match := blockStart.FindString(text)
+	if match == "" {
+		return text
+	}
+	if i := strings.IndexAny(match, ".)"); i > 0 && match[0] >= '0' && match[0] <= '9' {
+		return text[:i] + `\` + text[i:]
+	}
+	return `\` + text
+}
+
+func destination(url string) string {
+	if strings.ContainsAny(url, " ()<>") {
+		return "<" + strings.NewReplacer("<", "%3C", ">", "%3E").Replace(url) + ">"
+	}
+	return url
+}
+
+func title(s string) string {
+	if s == "" {
+		return ""
+	}
+	return ` "` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
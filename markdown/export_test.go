@@ -0,0 +1,107 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sklinkert/ghost/lexical"
+)
+
+func TestFromLexicalRoundTrip(t *testing.T) {
+	doc, err := ToLexical([]byte(sample))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exported, err := FromLexical(doc)
+	if err != nil {
+		t.Fatalf("Cannot export: %s", err)
+	}
+	reimported, err := ToLexical([]byte(exported))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := doc.String()
+	got, _ := reimported.String()
+	if got != want {
+		t.Fatalf("Round trip changed document, exported markdown:\n%s", exported)
+	}
+}
+
+func TestCalloutRoundTrip(t *testing.T) {
+	for _, callout := range []*lexical.CalloutCard{
+		{CalloutText: "Heads up", CalloutEmoji: "⚠️", BackgroundColor: "yellow"},
+		{CalloutText: "Grey note", CalloutEmoji: "💡", BackgroundColor: "grey"},
+		{CalloutText: "Party", CalloutEmoji: "🎉", BackgroundColor: "blue"},
+		{CalloutText: "No emoji", CalloutEmoji: "", BackgroundColor: "accent"},
+	} {
+		exported, err := FromLexical(lexical.New(callout))
+		if err != nil {
+			t.Fatalf("Cannot export: %s", err)
+		}
+		doc, err := ToLexical([]byte(exported))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := doc.Root.Children[0].(*lexical.CalloutCard)
+		if !ok || got.CalloutText != callout.CalloutText || got.CalloutEmoji != callout.CalloutEmoji || got.BackgroundColor != callout.BackgroundColor {
+			t.Fatalf("Callout changed, exported markdown:\n%s\ngot %#v", exported, doc.Root.Children[0])
+		}
+	}
+
+	exported, _ := FromLexical(lexical.New(&lexical.CalloutCard{CalloutText: "x", CalloutEmoji: "💡", BackgroundColor: "grey"}))
+	if !strings.HasPrefix(exported, "> [!NOTE|grey]\n") {
+		t.Fatalf("Unexpected marker: %q", exported)
+	}
+}
+
+func TestFromLexicalEscaping(t *testing.T) {
+	doc := lexical.New(
+		lexical.NewParagraph(lexical.NewText("1. not a list, *not* emphasis "), lexical.NewText("bold ", lexical.FormatBold)),
+		lexical.NewParagraph(lexical.NewText("# no heading")),
+	)
+	got, err := FromLexical(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "1\\. not a list, \\*not\\* emphasis **bold** \n\n\\# no heading\n"
+	if got != want {
+		t.Fatalf("Unexpected markdown:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestFromLexicalUnsupported(t *testing.T) {
+	doc := lexical.New(&lexical.RawNode{NodeType: "gallery", Raw: []byte(`{"type":"gallery"}`)})
+	if _, err := FromLexical(doc); !errors.Is(err, ErrUnsupportedNode) {
+		t.Fatalf("Expected ErrUnsupportedNode, got %v", err)
+	}
+}
+
+func TestFromHTML(t *testing.T) {
+	src := `<h2 id="intro">Intro</h2>
+<p>Hello <strong>bold</strong> <a href="https://ghost.org">Ghost</a><br>next</p>
+<figure class="kg-card kg-image-card kg-card-hascaption"><img src="https://example.com/a.jpg" class="kg-image" alt="A" loading="lazy" width="800" height="600"><figcaption>The caption</figcaption></figure>
+<pre><code class="language-go">x := 1
+</code></pre>
+<ul><li>one</li><li>two<ul><li>nested</li></ul></li></ul>
+<blockquote><p>Quoted</p></blockquote>
+<!--kg-card-begin: html--><table><tr><td>1</td></tr></table><!--kg-card-end: html-->
+<hr>`
+
+	got, err := FromHTML(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "## Intro\n\n" +
+		"Hello **bold** [Ghost](https://ghost.org)\\\nnext\n\n" +
+		"![A](https://example.com/a.jpg \"The caption\")\n\n" +
+		"```go\nx := 1\n```\n\n" +
+		"- one\n- two\n  - nested\n\n" +
+		"> Quoted\n\n" +
+		"<table><tbody><tr><td>1</td></tr></tbody></table>\n\n" +
+		"---\n"
+	if got != want {
+		t.Fatalf("Unexpected markdown:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,404 @@
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// FromHTML converts HTML, e.g. the rendered content of posts without Lexical source, into Markdown.
+// Elements without a Markdown counterpart are kept as HTML blocks.
+func FromHTML(src string) (string, error) {
+	doc, err := htmlToLexical(src)
+	if err != nil {
+		return "", err
+	}
+	return FromLexical(doc)
+}
+
+func htmlToLexical(src string) (*lexical.Document, error) {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{Type: html.ElementNode, Data: "body", DataAtom: atom.Body})
+	if err != nil {
+		return nil, err
+	}
+	return lexical.New(htmlBlocks(nodes)...), nil
+}
+
+var whitespace = regexp.MustCompile(`\s+`)
+
+// htmlBlocks converts block level nodes, loose inline content is collected into paragraphs
+func htmlBlocks(nodes []*html.Node) []lexical.Node {
+	var result []lexical.Node
+	var pending []lexical.Node
+	flush := func() {
+		if inlines := trimInlines(pending); len(inlines) > 0 {
+			result = append(result, lexical.NewParagraph(inlines...))
+		}
+		pending = nil
+	}
+
+	for i := 0; i < len(nodes); i++ {
+		node := nodes[i]
+		switch node.Type {
+		case html.TextNode:
+			pending = append(pending, htmlInline(node, 0)...)
+			continue
+		case html.CommentNode:
+			// Ghost wraps HTML and Markdown cards in kg-card-begin/-end comments
+			if card := strings.TrimSpace(node.Data); strings.HasPrefix(card, "kg-card-begin:") {
+				flush()
+				end := i + 1
+				for end < len(nodes) && !(nodes[end].Type == html.CommentNode && strings.HasPrefix(strings.TrimSpace(nodes[end].Data), "kg-card-end:")) {
+					end++
+				}
+				if raw := renderNodes(nodes[i+1 : end]); raw != "" {
+					result = append(result, &lexical.HTMLCard{HTML: raw})
+				}
+				i = end
+			}
+			continue
+		case html.ElementNode:
+		default:
+			continue
+		}
+
+		if !isBlock(node) {
+			pending = append(pending, htmlInline(node, 0)...)
+			continue
+		}
+		flush()
+		result = append(result, htmlBlock(node)...)
+	}
+	flush()
+	return result
+}
+
+func isBlock(node *html.Node) bool {
+	switch node.DataAtom {
+	case atom.A, atom.Abbr, atom.B, atom.Br, atom.Cite, atom.Code, atom.Del, atom.Em, atom.I, atom.Kbd, atom.Mark,
+		atom.Q, atom.S, atom.Small, atom.Span, atom.Strike, atom.Strong, atom.Sub, atom.Sup, atom.Time, atom.U:
+		return false
+	}
+	return true
+}
+
+func htmlBlock(node *html.Node) []lexical.Node {
+	switch node.DataAtom {
+	case atom.P:
+		if inlines := htmlInlines(node, 0); len(inlines) > 0 {
+			return []lexical.Node{lexical.NewParagraph(inlines...)}
+		}
+		return nil
+	case atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6:
+		level, _ := strconv.Atoi(node.Data[1:])
+		return []lexical.Node{lexical.NewHeading(level, htmlInlines(node, 0)...)}
+	case atom.Blockquote:
+		if quote := htmlQuote(node); quote != nil {
+			if hasClass(node, "kg-blockquote-alt") {
+				return []lexical.Node{&lexical.Aside{Element: quote.Element}}
+			}
+			return []lexical.Node{quote}
+		}
+	case atom.Ul, atom.Ol:
+		if list := htmlList(node); list != nil {
+			return []lexical.Node{list}
+		}
+	case atom.Hr:
+		return []lexical.Node{&lexical.HorizontalRule{}}
+	case atom.Pre:
+		return []lexical.Node{htmlCodeBlock(node)}
+	case atom.Img:
+		return []lexical.Node{htmlImage(node, nil, "")}
+	case atom.Figure:
+		if card := htmlFigure(node); card != nil {
+			return []lexical.Node{card}
+		}
+	case atom.Div, atom.Section, atom.Article, atom.Main, atom.Header, atom.Footer:
+		if !hasClassPrefix(node, "kg-") {
+			return htmlBlocks(children(node))
+		}
+	}
+	return []lexical.Node{&lexical.HTMLCard{HTML: renderNodes([]*html.Node{node})}}
+}
+
+// htmlQuote returns nil if the quote holds more than paragraphs and inline content
+func htmlQuote(node *html.Node) *lexical.Quote {
+	var inlines []lexical.Node
+	var paragraph []lexical.Node
+	flush := func() {
+		if trimmed := trimInlines(paragraph); len(trimmed) > 0 {
+			if len(inlines) > 0 {
+				inlines = append(inlines, lexical.NewLineBreak(), lexical.NewLineBreak())
+			}
+			inlines = append(inlines, trimmed...)
+		}
+		paragraph = nil
+	}
+
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		switch {
+		case child.Type == html.ElementNode && child.DataAtom == atom.P:
+			flush()
+			paragraph = htmlInlines(child, 0)
+			flush()
+		case child.Type == html.ElementNode && isBlock(child):
+			return nil
+		default:
+			paragraph = append(paragraph, htmlInline(child, 0)...)
+		}
+	}
+	flush()
+	return lexical.NewQuote(inlines...)
+}
+
+func htmlList(node *html.Node) *lexical.List {
+	listType := lexical.ListBullet
+	if node.DataAtom == atom.Ol {
+		listType = lexical.ListNumber
+	}
+
+	var items []lexical.Node
+	for li := node.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode {
+			continue
+		}
+		if li.DataAtom != atom.Li {
+			return nil
+		}
+
+		var inlines []lexical.Node
+		var nested []*lexical.List
+		for child := li.FirstChild; child != nil; child = child.NextSibling {
+			switch {
+			case child.Type == html.ElementNode && (child.DataAtom == atom.Ul || child.DataAtom == atom.Ol):
+				list := htmlList(child)
+				if list == nil {
+					return nil
+				}
+				nested = append(nested, list)
+			case child.Type == html.ElementNode && child.DataAtom == atom.P:
+				if len(inlines) > 0 {
+					inlines = append(inlines, lexical.NewLineBreak())
+				}
+				inlines = append(inlines, htmlInlines(child, 0)...)
+			case child.Type == html.ElementNode && isBlock(child):
+				return nil
+			default:
+				inlines = append(inlines, htmlInline(child, 0)...)
+			}
+		}
+		items = append(items, lexical.NewListItem(trimInlines(inlines)...))
+
+		for _, list := range nested {
+			for _, nestedItem := range list.Children {
+				if item, ok := nestedItem.(*lexical.ListItem); ok {
+					item.Indent++
+				}
+			}
+			list.Indent++
+			items = append(items, lexical.NewListItem(list))
+		}
+	}
+
+	list := lexical.NewList(listType, items...)
+	if start, err := strconv.Atoi(attribute(node, "start")); err == nil && start > 1 {
+		list.Start = start
+	}
+	return list
+}
+
+func htmlCodeBlock(pre *html.Node) *lexical.CodeBlockCard {
+	card := &lexical.CodeBlockCard{Code: strings.TrimSuffix(textContent(pre), "\n")}
+	if code := firstElement(pre, atom.Code); code != nil {
+		for _, class := range strings.Fields(attribute(code, "class")) {
+			if strings.HasPrefix(class, "language-") {
+				card.Language = strings.TrimPrefix(class, "language-")
+			}
+		}
+	}
+	return card
+}
+
+func htmlImage(img, link *html.Node, caption string) *lexical.ImageCard {
+	card := &lexical.ImageCard{
+		Src:     attribute(img, "src"),
+		Alt:     attribute(img, "alt"),
+		Title:   attribute(img, "title"),
+		Caption: caption,
+	}
+	card.Width, _ = strconv.Atoi(attribute(img, "width"))
+	card.Height, _ = strconv.Atoi(attribute(img, "height"))
+	if link != nil {
+		card.Href = attribute(link, "href")
+	}
+	switch {
+	case hasClass(img.Parent, "kg-width-wide"), link != nil && hasClass(link.Parent, "kg-width-wide"):
+		card.CardWidth = "wide"
+	case hasClass(img.Parent, "kg-width-full"), link != nil && hasClass(link.Parent, "kg-width-full"):
+		card.CardWidth = "full"
+	}
+	return card
+}
+
+// htmlFigure converts image and code figures, other cards are kept as HTML
+func htmlFigure(figure *html.Node) lexical.Node {
+	var caption string
+	if figcaption := firstElement(figure, atom.Figcaption); figcaption != nil {
+		caption = renderNodes(children(figcaption))
+	}
+
+	if hasClass(figure, "kg-code-card") {
+		if pre := firstElement(figure, atom.Pre); pre != nil {
+			card := htmlCodeBlock(pre)
+			card.Caption = caption
+			return card
+		}
+	}
+	if hasClassPrefix(figure, "kg-") && !hasClass(figure, "kg-image-card") {
+		return nil
+	}
+
+	img := firstElement(figure, atom.Img)
+	if img == nil {
+		return nil
+	}
+	var link *html.Node
+	if img.Parent != nil && img.Parent.DataAtom == atom.A {
+		link = img.Parent
+	}
+	return htmlImage(img, link, caption)
+}
+
+func htmlInlines(node *html.Node, format lexical.Format) []lexical.Node {
+	var result []lexical.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		result = append(result, htmlInline(child, format)...)
+	}
+	return trimInlines(result)
+}
+
+func htmlInline(node *html.Node, format lexical.Format) []lexical.Node {
+	switch node.Type {
+	case html.TextNode:
+		return []lexical.Node{lexical.NewText(whitespace.ReplaceAllString(node.Data, " "), format)}
+	case html.ElementNode:
+	default:
+		return nil
+	}
+
+	switch node.DataAtom {
+	case atom.Br:
+		return []lexical.Node{lexical.NewLineBreak()}
+	case atom.Strong, atom.B:
+		format |= lexical.FormatBold
+	case atom.Em, atom.I, atom.Cite:
+		format |= lexical.FormatItalic
+	case atom.S, atom.Del, atom.Strike:
+		format |= lexical.FormatStrikethrough
+	case atom.U:
+		format |= lexical.FormatUnderline
+	case atom.Mark:
+		format |= lexical.FormatHighlight
+	case atom.Sub:
+		format |= lexical.FormatSubscript
+	case atom.Sup:
+		format |= lexical.FormatSuperscript
+	case atom.Code, atom.Kbd:
+		return []lexical.Node{lexical.NewText(textContent(node), format|lexical.FormatCode)}
+	case atom.Img:
+		if alt := attribute(node, "alt"); alt != "" {
+			return []lexical.Node{lexical.NewText(alt, format)}
+		}
+		return nil
+	case atom.A:
+		var inlines []lexical.Node
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			inlines = append(inlines, htmlInline(child, format)...)
+		}
+		link := lexical.NewLink(attribute(node, "href"), inlines...)
+		link.Title = attribute(node, "title")
+		link.Rel = attribute(node, "rel")
+		link.Target = attribute(node, "target")
+		return []lexical.Node{link}
+	}
+
+	var result []lexical.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		result = append(result, htmlInline(child, format)...)
+	}
+	return result
+}
+
+func children(node *html.Node) []*html.Node {
+	var result []*html.Node
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		result = append(result, child)
+	}
+	return result
+}
+
+func firstElement(node *html.Node, a atom.Atom) *html.Node {
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.DataAtom == a {
+			return child
+		}
+		if found := firstElement(child, a); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+func attribute(node *html.Node, name string) string {
+	for _, attr := range node.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+func hasClass(node *html.Node, class string) bool {
+	if node == nil {
+		return false
+	}
+	for _, c := range strings.Fields(attribute(node, "class")) {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+func hasClassPrefix(node *html.Node, prefix string) bool {
+	for _, c := range strings.Fields(attribute(node, "class")) {
+		if strings.HasPrefix(c, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func textContent(node *html.Node) string {
+	if node.Type == html.TextNode {
+		return node.Data
+	}
+	var b strings.Builder
+	for child := node.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+func renderNodes(nodes []*html.Node) string {
+	var buf bytes.Buffer
+	for _, node := range nodes {
+		_ = html.Render(&buf, node)
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,516 @@
+// Package markdown converts CommonMark (with GitHub extensions) into Lexical documents
+// that use Ghost's cards for code blocks, images, callouts and embeds.
+package markdown
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+	"github.com/yuin/goldmark"
+	gast "github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	east "github.com/yuin/goldmark/extension/ast"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// Converter converts Markdown into Lexical documents
+type Converter struct {
+	// Embed turns a URL standing alone in a paragraph into an embed card, nil keeps it as a link
+	Embed func(rawURL string) *lexical.EmbedCard
+
+	md goldmark.Markdown
+}
+
+// NewConverter creates a converter embedding YouTube and Vimeo links
+func NewConverter() *Converter {
+	return &Converter{
+		Embed: VideoEmbed,
+		md:    goldmark.New(goldmark.WithExtensions(extension.GFM), goldmark.WithRendererOptions(gmhtml.WithUnsafe())),
+	}
+}
+
+// ToLexical converts Markdown with the default converter. Front matter must be removed first, see SplitFrontMatter.
+func ToLexical(src []byte) (*lexical.Document, error) {
+	return NewConverter().Convert(src)
+}
+
+// SplitFrontMatter separates a leading YAML front matter block delimited by "---" lines from the body
+func SplitFrontMatter(src []byte) (frontMatter, body []byte) {
+	normalized := bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	if !bytes.HasPrefix(normalized, []byte("---\n")) {
+		return nil, src
+	}
+
+	rest := normalized[4:]
+	if bytes.HasPrefix(rest, []byte("---\n")) || bytes.Equal(rest, []byte("---")) {
+		return []byte{}, bytes.TrimPrefix(rest[3:], []byte("\n"))
+	}
+	end := bytes.Index(rest, []byte("\n---\n"))
+	if end < 0 {
+		if bytes.HasSuffix(rest, []byte("\n---")) {
+			return rest[:len(rest)-4], nil
+		}
+		return nil, src
+	}
+	return rest[:end+1], rest[end+5:]
+}
+
+// Convert parses src and builds the document
+func (c *Converter) Convert(src []byte) (*lexical.Document, error) {
+	root := c.md.Parser().Parse(text.NewReader(src))
+
+	doc := lexical.New()
+	for child := root.FirstChild(); child != nil; child = child.NextSibling() {
+		nodes, err := c.convertBlock(child, src)
+		if err != nil {
+			return nil, err
+		}
+		doc.Append(nodes...)
+	}
+	return doc, nil
+}
+
+func (c *Converter) convertBlock(node gast.Node, src []byte) ([]lexical.Node, error) {
+	// Lexical has no inline HTML, blocks containing some are kept as rendered HTML
+	if hasRawHTML(node) {
+		rendered, err := c.renderHTML(node, src)
+		if err != nil {
+			return nil, err
+		}
+		return []lexical.Node{&lexical.HTMLCard{HTML: rendered}}, nil
+	}
+
+	switch n := node.(type) {
+	case *gast.Heading:
+		return []lexical.Node{lexical.NewHeading(n.Level, c.convertInlines(n, src, 0)...)}, nil
+	case *gast.Paragraph, *gast.TextBlock:
+		return c.convertParagraph(n, src), nil
+	case *gast.ThematicBreak:
+		return []lexical.Node{&lexical.HorizontalRule{}}, nil
+	case *gast.FencedCodeBlock:
+		return []lexical.Node{&lexical.CodeBlockCard{
+			Code:     codeBlockText(n, src),
+			Language: string(n.Language(src)),
+		}}, nil
+	case *gast.CodeBlock:
+		return []lexical.Node{&lexical.CodeBlockCard{Code: codeBlockText(n, src)}}, nil
+	case *gast.HTMLBlock:
+		var buf bytes.Buffer
+		lines := n.Lines()
+		for i := 0; i < lines.Len(); i++ {
+			segment := lines.At(i)
+			buf.Write(segment.Value(src))
+		}
+		if n.HasClosure() {
+			buf.Write(n.ClosureLine.Value(src))
+		}
+		return []lexical.Node{&lexical.HTMLCard{HTML: strings.TrimRight(buf.String(), "\n")}}, nil
+	case *gast.Blockquote:
+		return c.convertBlockquote(n, src)
+	case *gast.List:
+		if list, ok := c.convertList(n, src, 0); ok {
+			return []lexical.Node{list}, nil
+		}
+	}
+
+	// tables and other blocks without a Lexical counterpart are kept as rendered HTML
+	rendered, err := c.renderHTML(node, src)
+	if err != nil {
+		return nil, err
+	}
+	return []lexical.Node{&lexical.HTMLCard{HTML: rendered}}, nil
+}
+
+func hasRawHTML(node gast.Node) bool {
+	found := false
+	_ = gast.Walk(node, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if _, ok := n.(*gast.RawHTML); ok && entering {
+			found = true
+			return gast.WalkStop, nil
+		}
+		return gast.WalkContinue, nil
+	})
+	return found
+}
+
+func (c *Converter) renderHTML(node gast.Node, src []byte) (string, error) {
+	var buf bytes.Buffer
+	if err := c.md.Renderer().Render(&buf, src, node); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+func codeBlockText(node gast.Node, src []byte) string {
+	var buf bytes.Buffer
+	lines := node.Lines()
+	for i := 0; i < lines.Len(); i++ {
+		segment := lines.At(i)
+		buf.Write(segment.Value(src))
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// convertParagraph turns standalone images and embeddable URLs into cards. Images in the middle
+// of text split the paragraph because Lexical has no inline images.
+func (c *Converter) convertParagraph(node gast.Node, src []byte) []lexical.Node {
+	if card := c.standaloneCard(node, src); card != nil {
+		return []lexical.Node{card}
+	}
+
+	var result []lexical.Node
+	var inlines []lexical.Node
+	flush := func() {
+		if len(inlines) > 0 {
+			result = append(result, lexical.NewParagraph(trimInlines(inlines)...))
+		}
+		inlines = nil
+	}
+
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if image, ok := child.(*gast.Image); ok {
+			flush()
+			result = append(result, imageCard(image, src, ""))
+			continue
+		}
+		inlines = append(inlines, c.convertInline(child, src, 0)...)
+	}
+	flush()
+	return result
+}
+
+func (c *Converter) standaloneCard(node gast.Node, src []byte) lexical.Node {
+	if node.ChildCount() != 1 {
+		return nil
+	}
+
+	switch child := node.FirstChild().(type) {
+	case *gast.Image:
+		return imageCard(child, src, "")
+	case *gast.Link:
+		if image, ok := child.FirstChild().(*gast.Image); ok && child.ChildCount() == 1 {
+			return imageCard(image, src, string(child.Destination))
+		}
+		if c.Embed != nil && string(child.Destination) == plainText(child, src) {
+			if embed := c.Embed(string(child.Destination)); embed != nil {
+				return embed
+			}
+		}
+	case *gast.AutoLink:
+		if c.Embed != nil {
+			if embed := c.Embed(string(child.URL(src))); embed != nil {
+				return embed
+			}
+		}
+	case *gast.Text:
+		value := strings.TrimSpace(string(child.Value(src)))
+		if c.Embed != nil && !strings.ContainsAny(value, " \t") {
+			if embed := c.Embed(value); embed != nil {
+				return embed
+			}
+		}
+	}
+	return nil
+}
+
+func imageCard(image *gast.Image, src []byte, href string) *lexical.ImageCard {
+	return &lexical.ImageCard{
+		Src:     string(image.Destination),
+		Alt:     plainText(image, src),
+		Caption: html.EscapeString(string(image.Title)),
+		Href:    href,
+	}
+}
+
+func plainText(node gast.Node, src []byte) string {
+	var buf bytes.Buffer
+	_ = gast.Walk(node, func(n gast.Node, entering bool) (gast.WalkStatus, error) {
+		if !entering {
+			return gast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *gast.Text:
+			buf.Write(unescape(n.Value(src)))
+			if n.SoftLineBreak() {
+				buf.WriteByte(' ')
+			}
+		case *gast.String:
+			buf.Write(n.Value)
+		case *gast.AutoLink:
+			buf.Write(n.Label(src))
+		}
+		return gast.WalkContinue, nil
+	})
+	return buf.String()
+}
+
+func unescape(value []byte) []byte {
+	return util.UnescapePunctuations(util.ResolveNumericReferences(util.ResolveEntityNames(value)))
+}
+
+func (c *Converter) convertInlines(node gast.Node, src []byte, format lexical.Format) []lexical.Node {
+	var result []lexical.Node
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		result = append(result, c.convertInline(child, src, format)...)
+	}
+	return trimInlines(result)
+}
+
+func (c *Converter) convertInline(node gast.Node, src []byte, format lexical.Format) []lexical.Node {
+	switch n := node.(type) {
+	case *gast.Text:
+		value := string(unescape(n.Value(src)))
+		if n.IsRaw() {
+			value = string(n.Value(src))
+		}
+		result := []lexical.Node{lexical.NewText(value, format)}
+		if n.HardLineBreak() {
+			result = append(result, lexical.NewLineBreak())
+		} else if n.SoftLineBreak() {
+			result = append(result, lexical.NewText(" ", format))
+		}
+		return result
+	case *gast.String:
+		return []lexical.Node{lexical.NewText(string(n.Value), format)}
+	case *gast.CodeSpan:
+		return []lexical.Node{lexical.NewText(plainText(n, src), format|lexical.FormatCode)}
+	case *gast.Emphasis:
+		if n.Level >= 2 {
+			return c.convertInlines(n, src, format|lexical.FormatBold)
+		}
+		return c.convertInlines(n, src, format|lexical.FormatItalic)
+	case *east.Strikethrough:
+		return c.convertInlines(n, src, format|lexical.FormatStrikethrough)
+	case *gast.Link:
+		link := lexical.NewLink(string(n.Destination), c.convertInlines(n, src, format)...)
+		link.Title = string(n.Title)
+		return []lexical.Node{link}
+	case *gast.AutoLink:
+		target := string(n.URL(src))
+		return []lexical.Node{lexical.NewLink(target, lexical.NewText(string(n.Label(src)), format))}
+	case *gast.Image:
+		return []lexical.Node{lexical.NewLink(string(n.Destination), lexical.NewText(plainText(n, src), format))}
+	case *east.TaskCheckBox:
+		return nil
+	}
+	return c.convertInlines(node, src, format)
+}
+
+// trimInlines merges adjacent text nodes with the same format and drops surrounding whitespace
+func trimInlines(nodes []lexical.Node) []lexical.Node {
+	var result []lexical.Node
+	for _, node := range nodes {
+		text, ok := node.(*lexical.Text)
+		if ok && len(result) > 0 {
+			if previous, ok := result[len(result)-1].(*lexical.Text); ok && previous.Format == text.Format {
+				previous.Text += text.Text
+				continue
+			}
+		}
+		result = append(result, node)
+	}
+
+	if len(result) > 0 {
+		if first, ok := result[0].(*lexical.Text); ok {
+			first.Text = strings.TrimLeft(first.Text, " ")
+		}
+		if last, ok := result[len(result)-1].(*lexical.Text); ok {
+			last.Text = strings.TrimRight(last.Text, " ")
+		}
+	}
+
+	var nonEmpty []lexical.Node
+	for _, node := range result {
+		if text, ok := node.(*lexical.Text); ok && text.Text == "" {
+			continue
+		}
+		nonEmpty = append(nonEmpty, node)
+	}
+	return nonEmpty
+}
+
+// calloutPattern matches GitHub style alerts: "> [!NOTE]". A colour and an emoji that differ from
+// the alert's style follow the kind: "> [!NOTE|grey]" or "> [!NOTE|grey|🎉]", an empty emoji means none.
+var calloutPattern = regexp.MustCompile(`^\s*\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)(?:\|([a-z]+)(\|[^\]]*)?)?\]`)
+
+var calloutStyles = map[string]struct {
+	emoji string
+	color string
+}{
+	"NOTE":      {"💡", "blue"},
+	"TIP":       {"✅", "green"},
+	"IMPORTANT": {"❗", "purple"},
+	"WARNING":   {"⚠️", "yellow"},
+	"CAUTION":   {"🚨", "red"},
+}
+
+func (c *Converter) convertBlockquote(node *gast.Blockquote, src []byte) ([]lexical.Node, error) {
+	if first := node.FirstChild(); first != nil && first.Lines().Len() > 0 {
+		firstLine := first.Lines().At(0)
+		if match := calloutPattern.FindStringSubmatch(string(firstLine.Value(src))); match != nil {
+			return c.convertCallout(node, src, match)
+		}
+	}
+
+	// Lexical quotes only hold inline content, paragraphs are joined with line breaks
+	var inlines []lexical.Node
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		if _, ok := child.(*gast.Paragraph); !ok {
+			rendered, err := c.renderHTML(node, src)
+			if err != nil {
+				return nil, err
+			}
+			return []lexical.Node{&lexical.HTMLCard{HTML: rendered}}, nil
+		}
+		if len(inlines) > 0 {
+			inlines = append(inlines, lexical.NewLineBreak(), lexical.NewLineBreak())
+		}
+		inlines = append(inlines, c.convertInlines(child, src, 0)...)
+	}
+	return []lexical.Node{lexical.NewQuote(inlines...)}, nil
+}
+
+var calloutMarkerHTML = regexp.MustCompile(`^<p>\s*\[!(NOTE|TIP|IMPORTANT|WARNING|CAUTION)(\|[^\]]*)?\]\s*`)
+
+// convertCallout builds a callout from a blockquote whose first line matched calloutPattern
+func (c *Converter) convertCallout(node *gast.Blockquote, src []byte, match []string) ([]lexical.Node, error) {
+	var parts []string
+	for child := node.FirstChild(); child != nil; child = child.NextSibling() {
+		rendered, err := c.renderHTML(child, src)
+		if err != nil {
+			return nil, err
+		}
+		if len(parts) == 0 {
+			rendered = calloutMarkerHTML.ReplaceAllString(rendered, "<p>")
+			if rendered == "<p></p>" {
+				continue
+			}
+		}
+		parts = append(parts, rendered)
+	}
+
+	content := strings.Join(parts, "")
+	if len(parts) == 1 && strings.HasPrefix(content, "<p>") && strings.Count(content, "<p>") == 1 {
+		content = strings.TrimSuffix(strings.TrimPrefix(content, "<p>"), "</p>")
+	}
+
+	style := calloutStyles[match[1]]
+	if match[2] != "" {
+		style.color = match[2]
+	}
+	if match[3] != "" {
+		style.emoji = match[3][1:]
+	}
+	return []lexical.Node{&lexical.CalloutCard{
+		CalloutText:     content,
+		CalloutEmoji:    style.emoji,
+		BackgroundColor: style.color,
+	}}, nil
+}
+
+// convertList returns false when an item contains blocks Lexical lists can't hold.
+// depth is the nesting level of the list, 0 for a top level list.
+func (c *Converter) convertList(node *gast.List, src []byte, depth int) (*lexical.List, bool) {
+	listType := lexical.ListBullet
+	if node.IsOrdered() {
+		listType = lexical.ListNumber
+	}
+	value := 1
+	if node.IsOrdered() && node.Start > 1 {
+		value = node.Start
+	}
+
+	var items []lexical.Node
+	for item := node.FirstChild(); item != nil; item = item.NextSibling() {
+		var inlines []lexical.Node
+		var nested []*lexical.List
+		for child := item.FirstChild(); child != nil; child = child.NextSibling() {
+			switch child := child.(type) {
+			case *gast.Paragraph, *gast.TextBlock:
+				if len(inlines) > 0 {
+					inlines = append(inlines, lexical.NewLineBreak())
+				}
+				inlines = append(inlines, c.convertInlines(child, src, 0)...)
+			case *gast.List:
+				list, ok := c.convertList(child, src, depth+1)
+				if !ok {
+					return nil, false
+				}
+				nested = append(nested, list)
+			default:
+				return nil, false
+			}
+		}
+
+		listItem := lexical.NewListItem(inlines...)
+		listItem.Indent = depth
+		listItem.Value = value
+		value++
+		if checkbox := findCheckBox(item); checkbox != nil {
+			listType = lexical.ListCheck
+			checked := checkbox.IsChecked
+			listItem.Checked = &checked
+		}
+		items = append(items, listItem)
+
+		// nested lists are wrapped in their own list item, as the Lexical editor does.
+		// Like Lexical, the wrapper takes the next value without counting as an item.
+		for _, list := range nested {
+			wrapper := lexical.NewListItem(list)
+			wrapper.Indent = depth
+			wrapper.Value = value
+			items = append(items, wrapper)
+		}
+	}
+
+	list := lexical.NewList(listType, items...)
+	list.Indent = depth
+	if node.IsOrdered() && node.Start > 1 {
+		list.Start = node.Start
+	}
+	return list, true
+}
+
+func findCheckBox(item gast.Node) *east.TaskCheckBox {
+	if first := item.FirstChild(); first != nil {
+		if checkbox, ok := first.FirstChild().(*east.TaskCheckBox); ok {
+			return checkbox
+		}
+	}
+	return nil
+}
+
+var (
+	youtubePattern = regexp.MustCompile(`^https?://(?:www\.|m\.)?(?:youtube\.com/watch\?(?:.*&)?v=|youtu\.be/)([\w-]{6,})`)
+	vimeoPattern   = regexp.MustCompile(`^https?://(?:www\.)?vimeo\.com/(\d+)`)
+)
+
+// VideoEmbed creates embed cards with the iframe markup Ghost's oEmbed lookup returns for YouTube and Vimeo
+func VideoEmbed(rawURL string) *lexical.EmbedCard {
+	if _, err := url.ParseRequestURI(rawURL); err != nil {
+		return nil
+	}
+
+	var player string
+	if match := youtubePattern.FindStringSubmatch(rawURL); match != nil {
+		player = "https://www.youtube.com/embed/" + match[1] + "?feature=oembed"
+	} else if match := vimeoPattern.FindStringSubmatch(rawURL); match != nil {
+		player = "https://player.vimeo.com/video/" + match[1]
+	} else {
+		return nil
+	}
+
+	return &lexical.EmbedCard{
+		URL:       rawURL,
+		EmbedType: "video",
+		HTML: fmt.Sprintf(`<iframe width="200" height="113" src="%s" frameborder="0" `+
+			`allow="autoplay; fullscreen; picture-in-picture" allowfullscreen></iframe>`, player),
+	}
+}
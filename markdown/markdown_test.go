@@ -0,0 +1,184 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sklinkert/ghost/lexical"
+)
+
+const sample = `# Hello *world*
+
+Some **bold**, _italic_, ~~gone~~ and ` + "`code`" + ` with a [link](https://ghost.org "Ghost").
+Second line.
+
+![A cat](https://example.com/cat.jpg "Our cat")
+
+` + "```go\nfmt.Println(\"hi\")\n```" + `
+
+> [!WARNING]
+> Mind the **gap**.
+
+> Just a quote
+> over two lines
+
+1. one
+2. two
+   - nested
+
+- [x] done
+- [ ] todo
+
+https://www.youtube.com/watch?v=dQw4w9WgXcQ
+
+| a | b |
+|---|---|
+| 1 | 2 |
+
+---
+`
+
+func TestToLexical(t *testing.T) {
+	doc, err := ToLexical([]byte(sample))
+	if err != nil {
+		t.Fatalf("Cannot convert markdown: %s", err)
+	}
+
+	var types []string
+	for _, node := range doc.Root.Children {
+		types = append(types, node.Type())
+	}
+	want := []string{"heading", "paragraph", "image", "codeblock", "callout", "quote", "list", "list", "embed", "html", "horizontalrule"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Fatalf("Unexpected nodes:\ngot  %v\nwant %v", types, want)
+	}
+
+	children := doc.Root.Children
+	if heading := children[0].(*lexical.Heading); heading.Tag != "h1" || lexical.TextContent(heading) != "Hello world" {
+		t.Fatalf("Unexpected heading: %#v", heading)
+	}
+
+	paragraph := children[1].(*lexical.Paragraph)
+	if got := lexical.TextContent(paragraph); got != "Some bold, italic, gone and code with a link. Second line." {
+		t.Fatalf("Unexpected paragraph text: %q", got)
+	}
+	var formats []lexical.Format
+	for _, child := range paragraph.Children {
+		if text, ok := child.(*lexical.Text); ok && text.Format != 0 {
+			formats = append(formats, text.Format)
+		}
+	}
+	wantFormats := []lexical.Format{lexical.FormatBold, lexical.FormatItalic, lexical.FormatStrikethrough, lexical.FormatCode}
+	if len(formats) != len(wantFormats) {
+		t.Fatalf("Unexpected formats %v", formats)
+	}
+	for i := range formats {
+		if formats[i] != wantFormats[i] {
+			t.Fatalf("Unexpected formats %v", formats)
+		}
+	}
+
+	if image := children[2].(*lexical.ImageCard); image.Src != "https://example.com/cat.jpg" || image.Alt != "A cat" || image.Caption != "Our cat" {
+		t.Fatalf("Unexpected image: %#v", image)
+	}
+	if code := children[3].(*lexical.CodeBlockCard); code.Language != "go" || code.Code != `fmt.Println("hi")` {
+		t.Fatalf("Unexpected code block: %#v", code)
+	}
+	if callout := children[4].(*lexical.CalloutCard); callout.CalloutText != "Mind the <strong>gap</strong>." || callout.BackgroundColor != "yellow" {
+		t.Fatalf("Unexpected callout: %#v", callout)
+	}
+	if quote := children[5].(*lexical.Quote); lexical.TextContent(quote) != "Just a quote over two lines" {
+		t.Fatalf("Unexpected quote: %q", lexical.TextContent(quote))
+	}
+
+	ordered := children[6].(*lexical.List)
+	if ordered.ListType != lexical.ListNumber || len(ordered.Children) != 3 {
+		t.Fatalf("Unexpected ordered list: %#v", ordered)
+	}
+	if _, ok := ordered.Children[2].(*lexical.ListItem).Children[0].(*lexical.List); !ok {
+		t.Fatalf("Expected nested list in its own item")
+	}
+
+	tasks := children[7].(*lexical.List)
+	first := tasks.Children[0].(*lexical.ListItem)
+	if tasks.ListType != lexical.ListCheck || first.Checked == nil || !*first.Checked || lexical.TextContent(first) != "done" {
+		t.Fatalf("Unexpected task list: %#v", first)
+	}
+
+	if embed := children[8].(*lexical.EmbedCard); !strings.Contains(embed.HTML, "youtube.com/embed/dQw4w9WgXcQ") {
+		t.Fatalf("Unexpected embed: %#v", embed)
+	}
+	if table := children[9].(*lexical.HTMLCard); !strings.HasPrefix(table.HTML, "<table>") {
+		t.Fatalf("Unexpected table: %#v", table)
+	}
+}
+
+func TestInlineImageSplitsParagraph(t *testing.T) {
+	doc, err := ToLexical([]byte("before ![x](a.png) after"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Root.Children) != 3 {
+		t.Fatalf("Expected paragraph, image, paragraph, got %d nodes", len(doc.Root.Children))
+	}
+	if _, ok := doc.Root.Children[1].(*lexical.ImageCard); !ok {
+		t.Fatalf("Expected image card in the middle")
+	}
+}
+
+func TestInlineHTMLIsKept(t *testing.T) {
+	doc, err := ToLexical([]byte("Press <kbd>Ctrl</kbd> + <kbd>C</kbd>.\n\nPlain text.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Root.Children) != 2 {
+		t.Fatalf("Expected 2 nodes, got %d", len(doc.Root.Children))
+	}
+	card, ok := doc.Root.Children[0].(*lexical.HTMLCard)
+	if !ok || card.HTML != "<p>Press <kbd>Ctrl</kbd> + <kbd>C</kbd>.</p>" {
+		t.Fatalf("Expected an HTML card with the raw HTML, got %#v", doc.Root.Children[0])
+	}
+	if _, ok := doc.Root.Children[1].(*lexical.Paragraph); !ok {
+		t.Fatalf("Expected a paragraph, got %#v", doc.Root.Children[1])
+	}
+}
+
+func TestSplitFrontMatter(t *testing.T) {
+	frontMatter, body := SplitFrontMatter([]byte("---\ntitle: Hi\n---\n# Body\n"))
+	if string(frontMatter) != "title: Hi\n" || string(body) != "# Body\n" {
+		t.Fatalf("Unexpected split: %q / %q", frontMatter, body)
+	}
+
+	frontMatter, body = SplitFrontMatter([]byte("# No front matter\n"))
+	if frontMatter != nil || string(body) != "# No front matter\n" {
+		t.Fatalf("Unexpected split: %q / %q", frontMatter, body)
+	}
+}
+
+func TestNestedListLevels(t *testing.T) {
+	doc, err := ToLexical([]byte("1. a\n2. b\n   1. c\n      1. d\n3. e\n"))
+	if err != nil {
+		t.Fatalf("Cannot convert markdown: %s", err)
+	}
+
+	top := doc.Root.Children[0].(*lexical.List)
+	var values []int
+	for _, item := range top.Children {
+		values = append(values, item.(*lexical.ListItem).Value)
+	}
+	// the wrapper of the nested list doesn't count, so e stays 3
+	if len(values) != 4 || values[0] != 1 || values[1] != 2 || values[3] != 3 || lexical.TextContent(top.Children[3]) != "e" {
+		t.Fatalf("Unexpected values %v", values)
+	}
+
+	second := top.Children[2].(*lexical.ListItem).Children[0].(*lexical.List)
+	c := second.Children[0].(*lexical.ListItem)
+	third := second.Children[1].(*lexical.ListItem).Children[0].(*lexical.List)
+	d := third.Children[0].(*lexical.ListItem)
+	if second.Indent != 1 || c.Indent != 1 || lexical.TextContent(c) != "c" {
+		t.Fatalf("Unexpected second level: %#v", second)
+	}
+	if third.Indent != 2 || d.Indent != 2 || d.Value != 1 || lexical.TextContent(d) != "d" {
+		t.Fatalf("Unexpected third level: %#v", third)
+	}
+}
@@ -0,0 +1,114 @@
+package ghost
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const markdownPost = `---
+id: 65f1c0de8a1b2c0001a1b2c3
+slug: launch
+status: published
+visibility: members
+featured: true
+tags:
+- News
+- Product
+authors:
+- jane@example.com
+- john
+updated_at: "2024-03-01T09:30:00.000Z"
+published_at: "2024-03-01T09:00:00.000Z"
+feature_image: https://example.com/content/images/2024/03/cover.jpg
+feature_image_alt: A rocket
+excerpt: We launched.
+---
+
+# Launch day
+
+We **launched** today.
+`
+
+func TestParseMarkdownPost(t *testing.T) {
+	post, err := ParseMarkdownPost([]byte(markdownPost))
+	if err != nil {
+		t.Fatalf("Cannot parse post: %s", err)
+	}
+
+	if post.Title != "Launch day" || post.Slug != "launch" || post.Status != StatusPublished || post.Visibility != "members" || !post.Featured {
+		t.Fatalf("Unexpected post: %+v", post)
+	}
+	if post.PublishedAt != "2024-03-01T09:00:00.000Z" || post.UpdatedAt != "2024-03-01T09:30:00.000Z" || post.ID != "65f1c0de8a1b2c0001a1b2c3" {
+		t.Fatalf("Unexpected dates or id: %+v", post)
+	}
+	if post.FeatureImage != "https://example.com/content/images/2024/03/cover.jpg" || post.FeatureImageAlt != "A rocket" || post.CustomExcerpt != "We launched." {
+		t.Fatalf("Unexpected feature image or excerpt: %+v", post)
+	}
+	if !reflect.DeepEqual(post.Tags, []Tag{{Name: "News"}, {Name: "Product"}}) {
+		t.Fatalf("Unexpected tags: %+v", post.Tags)
+	}
+	if !reflect.DeepEqual(post.Authors, []Author{{Email: "jane@example.com"}, {Slug: "john"}}) {
+		t.Fatalf("Unexpected authors: %+v", post.Authors)
+	}
+
+	// the leading h1 became the title and is not repeated in the content
+	if strings.Contains(post.Lexical, "Launch day") || !strings.Contains(post.Lexical, "launched") {
+		t.Fatalf("Unexpected content: %s", post.Lexical)
+	}
+}
+
+func TestMarkdownPostRoundTrip(t *testing.T) {
+	post, err := ParseMarkdownPost([]byte(markdownPost))
+	if err != nil {
+		t.Fatalf("Cannot parse post: %s", err)
+	}
+	data, err := MarshalMarkdownPost(post)
+	if err != nil {
+		t.Fatalf("Cannot marshal post: %s", err)
+	}
+	if !strings.Contains(string(data), "title: Launch day\n") || strings.Contains(string(data), "# Launch day") {
+		t.Fatalf("Expected the title in the front matter only:\n%s", data)
+	}
+
+	again, err := ParseMarkdownPost(data)
+	if err != nil {
+		t.Fatalf("Cannot parse marshaled post: %s", err)
+	}
+	if !reflect.DeepEqual(post, again) {
+		t.Fatalf("Post changed after round trip:\n%+v\n%+v", post, again)
+	}
+}
+
+func TestCreatePostFromMarkdown(t *testing.T) {
+	var received Posts
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &received); err != nil {
+			t.Errorf("Cannot decode request: %s", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	g := New(server.URL, "", "65f1c0de8a1b2c0001a1b2c3:0123456789abcdef0123456789abcdef")
+	if _, err := g.CreatePostFromMarkdown([]byte(markdownPost)); err != nil {
+		t.Fatalf("Cannot create post: %s", err)
+	}
+
+	if len(received.Posts) != 1 {
+		t.Fatalf("Expected one post, got %+v", received)
+	}
+	post := received.Posts[0]
+	if post.ID != "" || post.UpdatedAt != "" {
+		t.Fatalf("Expected id and updated_at to be dropped: %+v", post)
+	}
+	if post.Title != "Launch day" || post.PublishedAt != "2024-03-01T09:00:00.000Z" || post.Lexical == "" || post.HTML != "" {
+		t.Fatalf("Unexpected post: %+v", post)
+	}
+}
@@ -0,0 +1,117 @@
+package mdsync
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sklinkert/ghost"
+)
+
+// Apply executes the plan and records the result in the state file. Conflicts are skipped.
+// Failed actions keep their error in Action.Err and don't stop the others.
+func (s *Syncer) Apply(plan *Plan) error {
+	st, err := loadState(s.statePath)
+	if err != nil {
+		return err
+	}
+	for slug, e := range plan.unchanged {
+		st.Posts[slug] = e
+	}
+	for _, slug := range plan.forgotten {
+		delete(st.Posts, slug)
+	}
+
+	var failed int
+	for i := range plan.Actions {
+		action := &plan.Actions[i]
+		if action.Kind == ActionConflict {
+			continue
+		}
+		if err := s.apply(action, st); err != nil {
+			action.Err = err
+			failed++
+		}
+	}
+
+	if err := st.save(s.statePath); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d actions failed", failed, len(plan.Actions))
+	}
+	return nil
+}
+
+func (s *Syncer) apply(action *Action, st *state) error {
+	if action.Kind == ActionDelete {
+		if s.delete {
+			if err := s.deleteSide(action); err != nil {
+				return err
+			}
+		}
+		delete(st.Posts, action.Slug)
+		return nil
+	}
+
+	if action.Side == Local {
+		return s.writeFile(action, st)
+	}
+	return s.writePost(action, st)
+}
+
+func (s *Syncer) deleteSide(action *Action) error {
+	if action.Side == Local {
+		return os.Remove(action.Path)
+	}
+	return s.client.AdminDeletePost(action.post.ID)
+}
+
+func (s *Syncer) writeFile(action *Action, st *state) error {
+	content, err := ghost.MarshalMarkdownPost(action.post)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(action.Path, content, 0644); err != nil {
+		return err
+	}
+	st.Posts[action.Slug] = entry{ID: action.post.ID, UpdatedAt: action.post.UpdatedAt, Hash: hash(content)}
+	return nil
+}
+
+func (s *Syncer) writePost(action *Action, st *state) error {
+	post, err := ghost.ParseMarkdownPost(action.local)
+	if err != nil {
+		return err
+	}
+	post.Slug = action.Slug
+
+	var updated ghost.Post
+	if action.post.ID == "" {
+		post.ID, post.UpdatedAt = "", ""
+		created, err := s.client.AdminCreatePost(post)
+		if err != nil {
+			return err
+		}
+		if len(created.Posts) == 0 {
+			return fmt.Errorf("no post returned")
+		}
+		updated = created.Posts[0]
+	} else {
+		// the site's updated_at, not the one of the file, the plan already decided who wins
+		post.ID, post.UpdatedAt = action.post.ID, action.post.UpdatedAt
+		if err := s.client.AdminUpdatePost(post, ""); err != nil {
+			return err
+		}
+		fetched, err := s.client.AdminGetPost(post.ID)
+		if err != nil {
+			return err
+		}
+		if len(fetched.Posts) == 0 {
+			return fmt.Errorf("post %s not found after update", post.ID)
+		}
+		updated = fetched.Posts[0]
+	}
+
+	st.Posts[action.Slug] = entry{ID: updated.ID, UpdatedAt: updated.UpdatedAt, Hash: hash(action.local)}
+	return nil
+}
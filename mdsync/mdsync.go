@@ -0,0 +1,335 @@
+// Package mdsync keeps a directory of Markdown files and the posts of a Ghost site in sync.
+//
+// Files are matched with posts by slug (front matter slug, or the file name without ".md").
+// A state file remembers the hash of every file and the updated_at of every post at the time
+// of the last sync, so a plan can tell which side changed. Posts changed on both sides are
+// reported as conflicts and left alone.
+package mdsync
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/sklinkert/ghost"
+)
+
+// DefaultStateFile is the name of the state file inside the synced directory
+const DefaultStateFile = ".ghost-sync.json"
+
+// Client is the part of *ghost.Ghost the syncer needs
+type Client interface {
+	AdminGetPosts() (ghost.Posts, error)
+	AdminGetPost(postId string) (ghost.Posts, error)
+	AdminCreatePost(post ghost.Post) (ghost.Posts, error)
+	AdminUpdatePost(post ghost.Post, sourceType ghost.SourceType) error
+	AdminDeletePost(postId string) error
+}
+
+type Mode int
+
+const (
+	// Bidirectional applies changes of either side to the other
+	Bidirectional Mode = iota
+	// Push only changes the site, local files win conflicts
+	Push
+	// Pull only changes local files, the site wins conflicts
+	Pull
+)
+
+type ActionKind string
+
+const (
+	ActionCreate   ActionKind = "create"
+	ActionUpdate   ActionKind = "update"
+	ActionDelete   ActionKind = "delete"
+	ActionConflict ActionKind = "conflict"
+)
+
+// Side is where an action makes its change
+type Side string
+
+const (
+	Local  Side = "local"
+	Remote Side = "remote"
+)
+
+type Action struct {
+	Kind   ActionKind
+	Side   Side
+	Slug   string
+	Path   string
+	Reason string
+	// Err is set by Apply if the action failed
+	Err error
+
+	post  ghost.Post // remote post, if there is one
+	local []byte     // local file content, if there is one
+}
+
+func (a Action) String() string {
+	side := string(a.Side)
+	if a.Kind == ActionConflict {
+		side = "both"
+	}
+	return fmt.Sprintf("%-8s %-6s %s (%s)", a.Kind, side, a.Slug, a.Reason)
+}
+
+type Plan struct {
+	Actions []Action
+
+	// unchanged holds posts that are in sync but not yet in the state file
+	unchanged map[string]entry
+	// forgotten holds state entries of posts deleted on both sides
+	forgotten []string
+}
+
+// Conflicts returns the actions Apply will skip
+func (p *Plan) Conflicts() []Action {
+	var conflicts []Action
+	for _, action := range p.Actions {
+		if action.Kind == ActionConflict {
+			conflicts = append(conflicts, action)
+		}
+	}
+	return conflicts
+}
+
+// Print writes one line per action
+func (p *Plan) Print(w io.Writer) error {
+	if len(p.Actions) == 0 {
+		_, err := fmt.Fprintln(w, "nothing to do")
+		return err
+	}
+	for _, action := range p.Actions {
+		if _, err := fmt.Fprintln(w, action); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Syncer struct {
+	client    Client
+	dir       string
+	statePath string
+	mode      Mode
+	delete    bool
+}
+
+type Option func(s *Syncer)
+
+// WithStateFile overrides the state file location, by default it is DefaultStateFile in the directory
+func WithStateFile(path string) Option {
+	return func(s *Syncer) {
+		s.statePath = path
+	}
+}
+
+// WithMode restricts the sync to one direction
+func WithMode(mode Mode) Option {
+	return func(s *Syncer) {
+		s.mode = mode
+	}
+}
+
+// WithDelete enables deleting posts whose file was removed and files whose post was removed.
+// Without it, deletions only drop the entry from the state file.
+func WithDelete(enabled bool) Option {
+	return func(s *Syncer) {
+		s.delete = enabled
+	}
+}
+
+// New creates a syncer for the Markdown files in dir
+func New(client Client, dir string, opts ...Option) *Syncer {
+	s := &Syncer{
+		client:    client,
+		dir:       dir,
+		statePath: filepath.Join(dir, DefaultStateFile),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+type localFile struct {
+	path    string
+	content []byte
+	hash    string
+}
+
+// Plan compares the directory with the site without changing anything
+func (s *Syncer) Plan() (*Plan, error) {
+	state, err := loadState(s.statePath)
+	if err != nil {
+		return nil, err
+	}
+	files, err := s.localFiles()
+	if err != nil {
+		return nil, err
+	}
+	posts, err := s.client.AdminGetPosts()
+	if err != nil {
+		return nil, err
+	}
+
+	remote := map[string]ghost.Post{}
+	for _, post := range posts.Posts {
+		remote[post.Slug] = post
+	}
+
+	slugs := map[string]bool{}
+	for slug := range files {
+		slugs[slug] = true
+	}
+	for slug := range remote {
+		slugs[slug] = true
+	}
+	for slug := range state.Posts {
+		slugs[slug] = true
+	}
+	var sorted []string
+	for slug := range slugs {
+		sorted = append(sorted, slug)
+	}
+	sort.Strings(sorted)
+
+	plan := &Plan{unchanged: map[string]entry{}}
+	for _, slug := range sorted {
+		file, hasFile := files[slug]
+		post, hasPost := remote[slug]
+		last, synced := state.Posts[slug]
+		action := Action{Slug: slug, Path: filepath.Join(s.dir, slug+".md"), post: post}
+		if hasFile {
+			action.Path, action.local = file.path, file.content
+		}
+
+		switch {
+		case hasFile && hasPost && synced:
+			localChanged := file.hash != last.Hash
+			remoteChanged := post.UpdatedAt != last.UpdatedAt
+			switch {
+			case localChanged && remoteChanged:
+				action.Kind, action.Reason = ActionConflict, "changed locally and on the site"
+			case localChanged:
+				action.Kind, action.Side, action.Reason = ActionUpdate, Remote, "file changed"
+			case remoteChanged:
+				action.Kind, action.Side, action.Reason = ActionUpdate, Local, "post changed on the site"
+			default:
+				continue
+			}
+		case hasFile && hasPost:
+			exported, err := ghost.MarshalMarkdownPost(post)
+			if err == nil && string(exported) == string(file.content) {
+				plan.unchanged[slug] = entry{ID: post.ID, UpdatedAt: post.UpdatedAt, Hash: file.hash}
+				continue
+			}
+			action.Kind, action.Reason = ActionConflict, "file and post exist but were never synced"
+		case hasFile && synced:
+			if file.hash != last.Hash {
+				action.Kind, action.Reason = ActionConflict, "post deleted on the site but file changed"
+			} else {
+				action.Kind, action.Side, action.Reason = ActionDelete, Local, "post deleted on the site"
+			}
+		case hasFile:
+			action.Kind, action.Side, action.Reason = ActionCreate, Remote, "new file"
+		case hasPost && synced:
+			if post.UpdatedAt != last.UpdatedAt {
+				action.Kind, action.Reason = ActionConflict, "file deleted but post changed on the site"
+			} else {
+				action.Kind, action.Side, action.Reason = ActionDelete, Remote, "file deleted"
+			}
+		case hasPost:
+			action.Kind, action.Side, action.Reason = ActionCreate, Local, "new post on the site"
+		default:
+			plan.forgotten = append(plan.forgotten, slug)
+			continue
+		}
+
+		action, ok := s.resolve(action)
+		if !ok {
+			continue
+		}
+		if action.Kind == ActionDelete && !s.delete {
+			action.Reason += ", deletes disabled: only removed from the state"
+		}
+		plan.Actions = append(plan.Actions, action)
+	}
+	return plan, nil
+}
+
+// resolve applies the mode, one way syncs turn conflicts into updates and drop actions of the other side
+func (s *Syncer) resolve(action Action) (Action, bool) {
+	if s.mode == Bidirectional {
+		return action, true
+	}
+
+	if action.Kind == ActionConflict {
+		switch {
+		case s.mode == Push && action.local != nil:
+			action.Kind, action.Side = ActionUpdate, Remote
+			if action.post.ID == "" {
+				action.Kind = ActionCreate
+			}
+		case s.mode == Push:
+			action.Kind, action.Side = ActionDelete, Remote
+		case s.mode == Pull && action.post.ID != "":
+			action.Kind, action.Side = ActionUpdate, Local
+			if action.local == nil {
+				action.Kind = ActionCreate
+			}
+		default:
+			action.Kind, action.Side = ActionDelete, Local
+		}
+		if s.mode == Push {
+			action.Reason += ", file wins"
+		} else {
+			action.Reason += ", site wins"
+		}
+	}
+
+	if s.mode == Push {
+		return action, action.Side == Remote
+	}
+	return action, action.Side == Local
+}
+
+func (s *Syncer) localFiles() (map[string]localFile, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.md"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]localFile{}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		post, err := ghost.ParseMarkdownPost(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		slug := post.Slug
+		if slug == "" {
+			slug = strings.TrimSuffix(filepath.Base(path), ".md")
+		}
+		if other, found := files[slug]; found {
+			return nil, fmt.Errorf("%s and %s have the same slug %q", other.path, path, slug)
+		}
+		files[slug] = localFile{path: path, content: content, hash: hash(content)}
+	}
+	return files, nil
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,112 @@
+package mdsync
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/fakesite"
+)
+
+var _ Client = (*ghost.Ghost)(nil)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func planString(t *testing.T, s *Syncer) (*Plan, string) {
+	t.Helper()
+	plan, err := s.Plan()
+	if err != nil {
+		t.Fatalf("Cannot plan: %s", err)
+	}
+	var buf bytes.Buffer
+	_ = plan.Print(&buf)
+	return plan, buf.String()
+}
+
+func TestSync(t *testing.T) {
+	dir := t.TempDir()
+	site := fakesite.New("https://example.com")
+	site.SavePost(ghost.Post{Slug: "from-site", Title: "From site", HTML: "<p>Remote</p>"})
+	writeFile(t, dir, "from-git.md", "---\ntitle: From Git\n---\nLocal text\n")
+
+	s := New(site, dir, WithDelete(true))
+	plan, printed := planString(t, s)
+	// actions are sorted by slug
+	want := "create   remote from-git (new file)\n" +
+		"create   local  from-site (new post on the site)\n"
+	if printed != want {
+		t.Fatalf("Unexpected plan:\n%s", printed)
+	}
+	if err := s.Apply(plan); err != nil {
+		t.Fatalf("Cannot apply: %s", err)
+	}
+	if _, found := site.PostBySlug("from-git"); !found {
+		t.Fatalf("Post from file was not created")
+	}
+	pulled, err := os.ReadFile(filepath.Join(dir, "from-site.md"))
+	if err != nil || !strings.Contains(string(pulled), "Remote") {
+		t.Fatalf("Post was not written to a file: %s %v", pulled, err)
+	}
+
+	if _, printed := planString(t, s); printed != "nothing to do\n" {
+		t.Fatalf("Expected nothing to do after apply, got:\n%s", printed)
+	}
+
+	// local change is pushed
+	writeFile(t, dir, "from-git.md", "---\ntitle: From Git\n---\nChanged text\n")
+	plan, printed = planString(t, s)
+	if printed != "update   remote from-git (file changed)\n" {
+		t.Fatalf("Unexpected plan:\n%s", printed)
+	}
+	if err := s.Apply(plan); err != nil {
+		t.Fatalf("Cannot apply: %s", err)
+	}
+	if post, _ := site.PostBySlug("from-git"); !strings.Contains(post.Lexical, "Changed text") {
+		t.Fatalf("Post was not updated: %s", post.Lexical)
+	}
+
+	// changes on both sides conflict and are left alone
+	writeFile(t, dir, "from-site.md", "---\ntitle: From site\nslug: from-site\n---\nEdited locally\n")
+	post, _ := site.PostBySlug("from-site")
+	post.Title = "Edited on site"
+	site.SavePost(post)
+	plan, printed = planString(t, s)
+	if printed != "conflict both   from-site (changed locally and on the site)\n" || len(plan.Conflicts()) != 1 {
+		t.Fatalf("Unexpected plan:\n%s", printed)
+	}
+	if err := s.Apply(plan); err != nil {
+		t.Fatalf("Cannot apply: %s", err)
+	}
+	if post, _ := site.PostBySlug("from-site"); post.Title != "Edited on site" {
+		t.Fatalf("Conflicting post was changed")
+	}
+
+	// push mode lets the file win
+	plan, printed = planString(t, New(site, dir, WithMode(Push)))
+	if printed != "update   remote from-site (changed locally and on the site, file wins)\n" {
+		t.Fatalf("Unexpected push plan:\n%s", printed)
+	}
+
+	// deleted file deletes the post
+	if err := os.Remove(filepath.Join(dir, "from-git.md")); err != nil {
+		t.Fatal(err)
+	}
+	plan, printed = planString(t, s)
+	if !strings.Contains(printed, "delete   remote from-git (file deleted)") {
+		t.Fatalf("Unexpected plan:\n%s", printed)
+	}
+	if err := s.Apply(plan); err != nil {
+		t.Fatalf("Cannot apply: %s", err)
+	}
+	if _, found := site.PostBySlug("from-git"); found {
+		t.Fatalf("Post was not deleted")
+	}
+}
@@ -0,0 +1,44 @@
+package mdsync
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+)
+
+// state is what the state file records about every synced post
+type state struct {
+	Posts map[string]entry `json:"posts"`
+}
+
+type entry struct {
+	ID        string `json:"id"`
+	UpdatedAt string `json:"updated_at"` // of the post when it was last synced
+	Hash      string `json:"hash"`       // sha256 of the file when it was last synced
+}
+
+func loadState(path string) (*state, error) {
+	s := &state{Posts: map[string]entry{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Posts == nil {
+		s.Posts = map[string]entry{}
+	}
+	return s, nil
+}
+
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
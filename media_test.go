@@ -0,0 +1,33 @@
+package ghost
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAdminUploadMediaThumbnailUsesPut(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/ghost/api/v3/admin/media/thumbnail/upload/" {
+			http.NotFound(w, r)
+			return
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil || r.FormValue("url") != "https://example.com/content/media/clip.mp4" {
+			http.Error(w, "bad form", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"media":[{"url":"https://example.com/content/media/clip_thumb.jpg","ref":null}]}`))
+	}))
+	defer server.Close()
+
+	g := New(server.URL, "", "65f1c0de8a1b2c0001a1b2c3:0123456789abcdef0123456789abcdef")
+	url, err := g.AdminUploadMediaThumbnail(context.Background(), "https://example.com/content/media/clip.mp4", "clip_thumb.jpg", strings.NewReader("jpeg"), "image/jpeg")
+	if err != nil {
+		t.Fatalf("Cannot upload thumbnail: %s", err)
+	}
+	if url != "https://example.com/content/media/clip_thumb.jpg" {
+		t.Fatalf("Unexpected thumbnail URL %s", url)
+	}
+}
@@ -1,10 +1,12 @@
 package ghost
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"time"
 )
 
@@ -49,9 +51,43 @@ type Member struct {
 	Attribution      *Attribution     `json:"attribution,omitempty"`
 }
 
+// MemberStatus is the access level of a member
+type MemberStatus string
+
+const (
+	MemberStatusFree   MemberStatus = "free"
+	MemberStatusPaid   MemberStatus = "paid"
+	MemberStatusComped MemberStatus = "comped"
+)
+
+// StatusOf returns the member's status as a MemberStatus
+func (m Member) StatusOf() MemberStatus {
+	return MemberStatus(m.Status)
+}
+
+// SubscriptionStatus is the state of a member subscription as reported by Stripe
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusActive            SubscriptionStatus = "active"
+	SubscriptionStatusTrialing          SubscriptionStatus = "trialing"
+	SubscriptionStatusPastDue           SubscriptionStatus = "past_due"
+	SubscriptionStatusUnpaid            SubscriptionStatus = "unpaid"
+	SubscriptionStatusCanceled          SubscriptionStatus = "canceled"
+	SubscriptionStatusIncomplete        SubscriptionStatus = "incomplete"
+	SubscriptionStatusIncompleteExpired SubscriptionStatus = "incomplete_expired"
+)
+
 type NewMember struct {
 	Name  string `json:"name"`
 	Email string `json:"email"`
+	Note  string `json:"note,omitempty"`
+	// Labels are label names, missing labels are created
+	Labels []string `json:"labels,omitempty"`
+	// Newsletters to subscribe to, Ghost picks the default newsletters if empty
+	Newsletters []NewsletterRef `json:"newsletters,omitempty"`
+	// Subscribed set to false creates the member without newsletters
+	Subscribed *bool `json:"subscribed,omitempty"`
 }
 
 type Subscription struct {
@@ -76,23 +112,49 @@ type Subscription struct {
 		Type     string `json:"type"`
 		Currency string `json:"currency"`
 	} `json:"price"`
+	Tier *Tier `json:"tier,omitempty"`
 }
 
-type Tier struct {
-	Id   string `json:"id"`
-	Name string `json:"name"`
+// StatusOf returns the subscription's status as a SubscriptionStatus
+func (s Subscription) StatusOf() SubscriptionStatus {
+	return SubscriptionStatus(s.Status)
 }
 
-type Newsletter struct {
-	Id          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Status      string `json:"status"`
+// IsComplimentary reports whether the subscription was granted by staff instead of being paid via Stripe
+func (s Subscription) IsComplimentary() bool {
+	return s.Id == "" && s.Price.Amount == 0
+}
+
+// memberTier - tier reference used when granting or revoking access, expiry_at null means forever
+type memberTier struct {
+	Id       string     `json:"id"`
+	ExpiryAt *time.Time `json:"expiry_at"`
+}
+
+type memberTiersUpdate struct {
+	Tiers []memberTier `json:"tiers"`
+}
+
+type memberCompedUpdate struct {
+	Comped bool `json:"comped"`
 }
 
 type EmailSuppression struct {
-	Suppressed bool        `json:"suppressed"`
-	Info       interface{} `json:"info"`
+	Suppressed bool                  `json:"suppressed"`
+	Info       *EmailSuppressionInfo `json:"info"`
+}
+
+// EmailSuppressionReason tells why Ghost stopped sending emails to a member
+type EmailSuppressionReason string
+
+const (
+	EmailSuppressionBounce EmailSuppressionReason = "bounce"
+	EmailSuppressionSpam   EmailSuppressionReason = "spam"
+)
+
+type EmailSuppressionInfo struct {
+	Reason    EmailSuppressionReason `json:"reason"`
+	Timestamp time.Time              `json:"timestamp"`
 }
 
 type Attribution struct {
@@ -106,12 +168,24 @@ type Attribution struct {
 }
 
 func (g *Ghost) AdminGetMembers() (Members, error) {
+	return g.adminGetMembers("")
+}
+
+// AdminGetSuppressedMembers returns all members whose email address is suppressed after a bounce or spam complaint
+func (g *Ghost) AdminGetSuppressedMembers() (Members, error) {
+	return g.adminGetMembers("email_disabled:1")
+}
+
+func (g *Ghost) adminGetMembers(filter string) (Members, error) {
 	const limit = 100
 	var allMembers Members
 	page := 1
 
 	for {
 		url := fmt.Sprintf("%s/ghost/api/v3/admin/members/?limit=%d&page=%d", g.url, limit, page)
+		if filter != "" {
+			url = url + "&filter=" + neturl.QueryEscape(filter)
+		}
 
 		var pageMembers Members
 		if err := g.getJson(url, &pageMembers); err != nil {
@@ -184,3 +258,210 @@ func (g *Ghost) AdminDeleteMember(memberId string) error {
 	}
 	return nil
 }
+
+// AdminGrantComplimentaryTier gives the member complimentary access to the tier until expiry.
+// A nil expiry grants access forever. An existing grant for the same tier is replaced.
+func (g *Ghost) AdminGrantComplimentaryTier(memberId, tierId string, expiry *time.Time) (Members, error) {
+	if err := g.require(capabilityTiers); err != nil {
+		return Members{}, err
+	}
+	members, err := g.AdminGetMember(memberId)
+	if err != nil {
+		return members, err
+	}
+	if len(members.Members) == 0 {
+		return members, fmt.Errorf("member %s not found", memberId)
+	}
+
+	tiers := []memberTier{{Id: tierId, ExpiryAt: expiry}}
+	for _, tier := range members.Members[0].Tiers {
+		if tier.Id != tierId {
+			tiers = append(tiers, memberTier{Id: tier.Id, ExpiryAt: tier.ExpiryAt})
+		}
+	}
+
+	return g.adminUpdateMemberTiers(memberId, tiers)
+}
+
+// AdminRevokeComplimentaryTier removes the member's access to the tier.
+// Tiers backed by a paid Stripe subscription cannot be revoked this way.
+func (g *Ghost) AdminRevokeComplimentaryTier(memberId, tierId string) (Members, error) {
+	if err := g.require(capabilityTiers); err != nil {
+		return Members{}, err
+	}
+	members, err := g.AdminGetMember(memberId)
+	if err != nil {
+		return members, err
+	}
+	if len(members.Members) == 0 {
+		return members, fmt.Errorf("member %s not found", memberId)
+	}
+
+	tiers := []memberTier{}
+	found := false
+	for _, tier := range members.Members[0].Tiers {
+		if tier.Id == tierId {
+			found = true
+			continue
+		}
+		tiers = append(tiers, memberTier{Id: tier.Id, ExpiryAt: tier.ExpiryAt})
+	}
+	if !found {
+		return members, fmt.Errorf("member %s has no access to tier %s", memberId, tierId)
+	}
+
+	return g.adminUpdateMemberTiers(memberId, tiers)
+}
+
+// AdminSetMemberComped toggles complimentary access to the site's default paid tier
+func (g *Ghost) AdminSetMemberComped(memberId string, comped bool) (Members, error) {
+	var members Members
+
+	update := map[string][]memberCompedUpdate{"members": {{Comped: comped}}}
+	data, err := json.Marshal(&update)
+	if err != nil {
+		return members, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/members/%s/?include=tiers", g.url, memberId)
+	if err := g.putJson(url, data, &members); err != nil {
+		return members, err
+	}
+	return members, nil
+}
+
+func (g *Ghost) adminUpdateMemberTiers(memberId string, tiers []memberTier) (Members, error) {
+	var members Members
+
+	update := map[string][]memberTiersUpdate{"members": {{Tiers: tiers}}}
+	data, err := json.Marshal(&update)
+	if err != nil {
+		return members, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/members/%s/?include=tiers", g.url, memberId)
+	if err := g.putJson(url, data, &members); err != nil {
+		return members, err
+	}
+	return members, nil
+}
+
+type memberSigninURLs struct {
+	MemberSigninURLs []struct {
+		MemberId string `json:"member_id"`
+		Url      string `json:"url"`
+	} `json:"member_signin_urls"`
+}
+
+// AdminGetMemberSigninURL returns a one-time sign-in link for the member, for members that cannot receive email
+func (g *Ghost) AdminGetMemberSigninURL(memberId string) (string, error) {
+	var urls memberSigninURLs
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/members/%s/signin_urls/", g.url, memberId)
+
+	if err := g.getJson(url, &urls); err != nil {
+		return "", err
+	}
+	if len(urls.MemberSigninURLs) == 0 {
+		return "", fmt.Errorf("no sign-in url returned for member %s", memberId)
+	}
+	return urls.MemberSigninURLs[0].Url, nil
+}
+
+// MagicLinkEmailType selects the email Ghost sends for a magic link
+type MagicLinkEmailType string
+
+const (
+	MagicLinkSignin    MagicLinkEmailType = "signin"
+	MagicLinkSignup    MagicLinkEmailType = "signup"
+	MagicLinkSubscribe MagicLinkEmailType = "subscribe"
+)
+
+type magicLinkNewsletter struct {
+	Name string `json:"name"`
+}
+
+type magicLinkRequest struct {
+	Email          string                `json:"email"`
+	EmailType      MagicLinkEmailType    `json:"emailType"`
+	Labels         []string              `json:"labels,omitempty"`
+	Newsletters    []magicLinkNewsletter `json:"newsletters,omitempty"`
+	IntegrityToken string                `json:"integrityToken,omitempty"`
+}
+
+// SendMagicLink triggers Ghost's own magic-link email via the public members API, as the signup
+// forms on the site do. Newsletters are referenced by name. No API token is needed.
+func (g *Ghost) SendMagicLink(email string, emailType MagicLinkEmailType, labels []string, newsletters []string) error {
+	if len(newsletters) > 0 {
+		if err := g.require(capabilityNewsletters); err != nil {
+			return err
+		}
+	}
+	integrityToken, err := g.getMembersIntegrityToken()
+	if err != nil {
+		return err
+	}
+
+	magicLink := magicLinkRequest{
+		Email:          email,
+		EmailType:      emailType,
+		Labels:         labels,
+		IntegrityToken: integrityToken,
+	}
+	for _, name := range newsletters {
+		magicLink.Newsletters = append(magicLink.Newsletters, magicLinkNewsletter{Name: name})
+	}
+	data, err := json.Marshal(&magicLink)
+	if err != nil {
+		return err
+	}
+
+	magicLinkURL := fmt.Sprintf("%s/members/api/send-magic-link/", g.url)
+	req, err := http.NewRequest(http.MethodPost, magicLinkURL, bytes.NewBuffer(data))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	content, _ := io.ReadAll(resp.Body)
+	responseBody := string(content)
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, responseBody)
+	}
+	return nil
+}
+
+// getMembersIntegrityToken fetches the anti-spam token newer Ghost versions require for magic links.
+// Older versions don't have the endpoint, then no token is sent.
+func (g *Ghost) getMembersIntegrityToken() (string, error) {
+	tokenURL := fmt.Sprintf("%s/members/api/integrity-token/", g.url)
+	resp, err := g.client.Get(tokenURL)
+	if err != nil {
+		return "", err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	content, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(content))
+	}
+	return string(bytes.TrimSpace(content)), nil
+}
+
+// AdminDeleteMemberEmailSuppression removes the suppression so the member receives emails again
+func (g *Ghost) AdminDeleteMemberEmailSuppression(memberId string) error {
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/members/%s/suppression/", g.url, memberId)
+	return g.deleteRequest(url)
+}
@@ -0,0 +1,290 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// MemberEventType is the kind of activity recorded in the member activity feed
+type MemberEventType string
+
+const (
+	MemberEventSignup          MemberEventType = "signup_event"
+	MemberEventLogin           MemberEventType = "login_event"
+	MemberEventSubscription    MemberEventType = "subscription_event"
+	MemberEventPayment         MemberEventType = "payment_event"
+	MemberEventNewsletter      MemberEventType = "newsletter_event"
+	MemberEventEmailSent       MemberEventType = "email_sent_event"
+	MemberEventEmailDelivered  MemberEventType = "email_delivered_event"
+	MemberEventEmailOpened     MemberEventType = "email_opened_event"
+	MemberEventEmailFailed     MemberEventType = "email_failed_event"
+	MemberEventEmailComplaint  MemberEventType = "email_complaint_event"
+	MemberEventClick           MemberEventType = "click_event"
+	MemberEventAggregatedClick MemberEventType = "aggregated_click_event"
+	MemberEventFeedback        MemberEventType = "feedback_event"
+	MemberEventComment         MemberEventType = "comment_event"
+)
+
+type MemberEvents struct {
+	Events []MemberEvent `json:"events"`
+	Meta   Pagination    `json:"meta,omitempty"`
+}
+
+// MemberEvent - the payload depends on Type, use Payload() to decode it
+type MemberEvent struct {
+	Type MemberEventType `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// MemberEventBase holds the fields shared by all event payloads
+type MemberEventBase struct {
+	Id        string    `json:"id"`
+	MemberId  string    `json:"member_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Member    struct {
+		Id    string `json:"id"`
+		Uuid  string `json:"uuid"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"member"`
+}
+
+type MemberEventPost struct {
+	Id    string `json:"id"`
+	Title string `json:"title"`
+	Url   string `json:"url"`
+}
+
+type SignupEvent struct {
+	MemberEventBase
+	Source      string       `json:"source"`
+	Attribution *Attribution `json:"attribution,omitempty"`
+}
+
+type LoginEvent struct {
+	MemberEventBase
+}
+
+type SubscriptionEvent struct {
+	MemberEventBase
+	Type           string       `json:"type"` // "created", "updated", "canceled", "reactivated", ...
+	Source         string       `json:"source"`
+	SubscriptionId string       `json:"subscription_id"`
+	FromPlan       string       `json:"from_plan"`
+	ToPlan         string       `json:"to_plan"`
+	Currency       string       `json:"currency"`
+	MrrDelta       int          `json:"mrr_delta"`
+	Tier           *Tier        `json:"tier,omitempty"`
+	Attribution    *Attribution `json:"attribution,omitempty"`
+}
+
+type PaymentEvent struct {
+	MemberEventBase
+	CustomerId string `json:"customer_id"`
+	Amount     int    `json:"amount"`
+	Currency   string `json:"currency"`
+	Source     string `json:"source"`
+}
+
+type NewsletterEvent struct {
+	MemberEventBase
+	Subscribed bool       `json:"subscribed"`
+	Source     string     `json:"source"`
+	Newsletter Newsletter `json:"newsletter"`
+}
+
+type EmailEvent struct {
+	MemberEventBase
+	EmailId string `json:"email_id"`
+	Email   struct {
+		Id      string `json:"id"`
+		Subject string `json:"subject"`
+	} `json:"email"`
+}
+
+type ClickEvent struct {
+	MemberEventBase
+	Link struct {
+		Id   string `json:"id"`
+		From string `json:"from"`
+		To   string `json:"to"`
+	} `json:"link"`
+	Post  MemberEventPost `json:"post"`
+	Count struct {
+		Clicks int `json:"clicks"`
+	} `json:"count"`
+}
+
+type FeedbackEvent struct {
+	MemberEventBase
+	Score  int             `json:"score"` // 1 = more like this, 0 = less like this
+	PostId string          `json:"post_id"`
+	Post   MemberEventPost `json:"post"`
+}
+
+type CommentEvent struct {
+	MemberEventBase
+	Html     string          `json:"html"`
+	ParentId string          `json:"parent_id"`
+	Post     MemberEventPost `json:"post"`
+}
+
+// Payload decodes Data into the struct matching Type, e.g. *SignupEvent for signup_event.
+// Unknown event types are decoded into *MemberEventBase.
+func (e MemberEvent) Payload() (interface{}, error) {
+	var target interface{}
+	switch e.Type {
+	case MemberEventSignup:
+		target = &SignupEvent{}
+	case MemberEventLogin:
+		target = &LoginEvent{}
+	case MemberEventSubscription:
+		target = &SubscriptionEvent{}
+	case MemberEventPayment:
+		target = &PaymentEvent{}
+	case MemberEventNewsletter:
+		target = &NewsletterEvent{}
+	case MemberEventEmailSent, MemberEventEmailDelivered, MemberEventEmailOpened,
+		MemberEventEmailFailed, MemberEventEmailComplaint:
+		target = &EmailEvent{}
+	case MemberEventClick, MemberEventAggregatedClick:
+		target = &ClickEvent{}
+	case MemberEventFeedback:
+		target = &FeedbackEvent{}
+	case MemberEventComment:
+		target = &CommentEvent{}
+	default:
+		target = &MemberEventBase{}
+	}
+
+	if err := json.Unmarshal(e.Data, target); err != nil {
+		return nil, fmt.Errorf("cannot decode %s: %w", e.Type, err)
+	}
+	return target, nil
+}
+
+// Base decodes only the fields shared by all events
+func (e MemberEvent) Base() (MemberEventBase, error) {
+	var base MemberEventBase
+	err := json.Unmarshal(e.Data, &base)
+	return base, err
+}
+
+const memberEventsPageSize = 100
+
+// AdminGetMemberEvents returns the newest page of member events matching the NQL filter,
+// e.g. "type:[signup_event,payment_event]+data.member_id:'abc'". Use MemberEventIterator to walk all events.
+func (g *Ghost) AdminGetMemberEvents(filter string) (MemberEvents, error) {
+	return g.adminGetMemberEvents(filter, memberEventsPageSize)
+}
+
+func (g *Ghost) adminGetMemberEvents(filter string, limit int) (MemberEvents, error) {
+	var events MemberEvents
+	eventsURL := fmt.Sprintf("%s/ghost/api/v3/admin/members/events/?limit=%d", g.url, limit)
+	if filter != "" {
+		eventsURL = eventsURL + "&filter=" + url.QueryEscape(filter)
+	}
+
+	if err := g.getJson(eventsURL, &events); err != nil {
+		return events, err
+	}
+	return events, nil
+}
+
+// MemberEventIterator streams member events from newest to oldest, fetching pages on demand.
+//
+//	it := g.NewMemberEventIterator("type:signup_event")
+//	for it.Next() {
+//		event := it.Event()
+//	}
+//	if err := it.Err(); err != nil { ... }
+type MemberEventIterator struct {
+	g      *Ghost
+	filter string
+	buffer []MemberEvent
+	event  MemberEvent
+	cursor string          // created_at of the oldest event seen so far
+	seen   map[string]bool // ids of events at the cursor timestamp
+	done   bool
+	err    error
+}
+
+// NewMemberEventIterator creates an iterator over all member events matching the NQL filter
+func (g *Ghost) NewMemberEventIterator(filter string) *MemberEventIterator {
+	return &MemberEventIterator{g: g, filter: filter, seen: map[string]bool{}}
+}
+
+// Next advances to the next event. It returns false when all events were read or an error occurred.
+func (it *MemberEventIterator) Next() bool {
+	for len(it.buffer) == 0 {
+		if it.done || it.err != nil {
+			return false
+		}
+		it.fetch()
+	}
+
+	it.event = it.buffer[0]
+	it.buffer = it.buffer[1:]
+	return true
+}
+
+// Event returns the current event
+func (it *MemberEventIterator) Event() MemberEvent {
+	return it.event
+}
+
+// Err returns the first error encountered while fetching
+func (it *MemberEventIterator) Err() error {
+	return it.err
+}
+
+// fetch loads the next page. Ghost paginates the activity feed by timestamp, so events sharing the
+// cursor timestamp are requested again and filtered out locally.
+func (it *MemberEventIterator) fetch() {
+	var filters []string
+	if it.filter != "" {
+		filters = append(filters, "("+it.filter+")")
+	}
+	if it.cursor != "" {
+		filters = append(filters, fmt.Sprintf("data.created_at:<='%s'", it.cursor))
+	}
+
+	page, err := it.g.adminGetMemberEvents(strings.Join(filters, "+"), memberEventsPageSize)
+	if err != nil {
+		it.err = err
+		return
+	}
+	if len(page.Events) < memberEventsPageSize {
+		it.done = true
+	}
+
+	var added int
+	for _, event := range page.Events {
+		base, err := event.Base()
+		if err != nil {
+			it.err = err
+			return
+		}
+		if it.seen[base.Id] {
+			continue
+		}
+
+		cursor := base.CreatedAt.UTC().Format("2006-01-02 15:04:05")
+		if cursor != it.cursor {
+			it.cursor = cursor
+			it.seen = map[string]bool{}
+		}
+		it.seen[base.Id] = true
+		it.buffer = append(it.buffer, event)
+		added++
+	}
+
+	// a full page of already seen events means more events share one timestamp than fit on a page,
+	// the older events can't be reached with a timestamp cursor
+	if added == 0 && !it.done {
+		it.err = fmt.Errorf("more than %d member events share the timestamp %s, older events can't be fetched", memberEventsPageSize, it.cursor)
+	}
+}
@@ -0,0 +1,121 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+var createdAtFilter = regexp.MustCompile(`data\.created_at:<='([^']+)'`)
+
+// newMemberEventServer serves events newest first like Ghost's activity feed, which only
+// supports a created_at filter to page through older events
+func newMemberEventServer(t *testing.T, times []time.Time, requests *int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		filter := r.URL.Query().Get("filter")
+		if !strings.HasPrefix(filter, "(type:signup_event)") {
+			t.Errorf("Filter lacks the type: %s", filter)
+		}
+		var before time.Time
+		if match := createdAtFilter.FindStringSubmatch(filter); match != nil {
+			var err error
+			if before, err = time.Parse("2006-01-02 15:04:05", match[1]); err != nil {
+				t.Errorf("Cannot parse cursor: %s", err)
+			}
+		}
+
+		var page MemberEvents
+		for i, createdAt := range times {
+			if len(page.Events) == memberEventsPageSize {
+				break
+			}
+			if !before.IsZero() && createdAt.After(before) {
+				continue
+			}
+			data := fmt.Sprintf(`{"id":"e%03d","member_id":"m1","created_at":%q}`, i, createdAt.Format(time.RFC3339))
+			page.Events = append(page.Events, MemberEvent{Type: MemberEventSignup, Data: json.RawMessage(data)})
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+}
+
+func TestMemberEventIterator(t *testing.T) {
+	// 250 events, 30 share each second so the groups straddle the page boundaries
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	var times []time.Time
+	for i := 0; i < 250; i++ {
+		times = append(times, start.Add(-time.Duration(i/30)*time.Second))
+	}
+
+	var requests int
+	server := newMemberEventServer(t, times, &requests)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	it := g.NewMemberEventIterator("type:signup_event")
+	var ids []string
+	for it.Next() {
+		base, err := it.Event().Base()
+		if err != nil {
+			t.Fatalf("Cannot decode event: %s", err)
+		}
+		ids = append(ids, base.Id)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(ids) != len(times) {
+		t.Fatalf("Expected %d events, got %d", len(times), len(ids))
+	}
+	for i, id := range ids {
+		if want := fmt.Sprintf("e%03d", i); id != want {
+			t.Fatalf("Event %d is %s, want %s", i, id, want)
+		}
+	}
+	if requests != 3 {
+		t.Fatalf("Expected 3 requests, got %d", requests)
+	}
+}
+
+func TestMemberEventIteratorTimestampOverflow(t *testing.T) {
+	// more events share one timestamp than fit on a page
+	var times []time.Time
+	for i := 0; i < memberEventsPageSize+50; i++ {
+		times = append(times, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC))
+	}
+
+	var requests int
+	server := newMemberEventServer(t, times, &requests)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	it := g.NewMemberEventIterator("type:signup_event")
+	seen := map[string]bool{}
+	for it.Next() {
+		base, err := it.Event().Base()
+		if err != nil {
+			t.Fatalf("Cannot decode event: %s", err)
+		}
+		if seen[base.Id] {
+			t.Fatalf("Event %s returned twice", base.Id)
+		}
+		seen[base.Id] = true
+	}
+
+	if len(seen) != memberEventsPageSize {
+		t.Fatalf("Expected %d events, got %d", memberEventsPageSize, len(seen))
+	}
+	if err := it.Err(); err == nil || !strings.Contains(err.Error(), "share the timestamp 2024-05-01 10:00:00") {
+		t.Fatalf("Expected an error about the shared timestamp, got %v", err)
+	}
+	if it.Next() {
+		t.Fatal("Next returned true after an error")
+	}
+}
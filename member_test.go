@@ -0,0 +1,292 @@
+package ghost
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testAdminKey = "65f1c0de8a1b2c0001a1b2c3:0123456789abcdef0123456789abcdef"
+
+// newMemberServer serves the member with ID "m1" and records the bodies of updates
+func newMemberServer(t *testing.T, member Member, updates *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ghost/api/v3/admin/members/m1/" || r.URL.Query().Get("include") != "tiers" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			http.NotFound(w, r)
+			return
+		}
+		switch r.Method {
+		case http.MethodGet:
+			_ = json.NewEncoder(w).Encode(Members{Members: []Member{member}})
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			*updates = append(*updates, string(body))
+			_ = json.NewEncoder(w).Encode(Members{Members: []Member{member}})
+		default:
+			t.Errorf("Unexpected method %s", r.Method)
+		}
+	}))
+}
+
+func TestAdminGrantComplimentaryTier(t *testing.T) {
+	expiry := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	earlier := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	member := Member{Id: "m1", Tiers: []Tier{{Id: "silver"}, {Id: "gold", ExpiryAt: &earlier}}}
+
+	var updates []string
+	server := newMemberServer(t, member, &updates)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	// the existing grant of gold is replaced, silver is kept
+	if _, err := g.AdminGrantComplimentaryTier("m1", "gold", &expiry); err != nil {
+		t.Fatalf("Cannot grant tier: %s", err)
+	}
+	want := `{"members":[{"tiers":[{"id":"gold","expiry_at":"2025-01-01T00:00:00Z"},{"id":"silver","expiry_at":null}]}]}`
+	if len(updates) != 1 || updates[0] != want {
+		t.Fatalf("Unexpected updates: %v", updates)
+	}
+}
+
+func TestAdminRevokeComplimentaryTier(t *testing.T) {
+	expiry := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	member := Member{Id: "m1", Tiers: []Tier{{Id: "silver"}, {Id: "gold", ExpiryAt: &expiry}}}
+
+	var updates []string
+	server := newMemberServer(t, member, &updates)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	if _, err := g.AdminRevokeComplimentaryTier("m1", "silver"); err != nil {
+		t.Fatalf("Cannot revoke tier: %s", err)
+	}
+	want := `{"members":[{"tiers":[{"id":"gold","expiry_at":"2025-01-01T00:00:00Z"}]}]}`
+	if len(updates) != 1 || updates[0] != want {
+		t.Fatalf("Unexpected updates: %v", updates)
+	}
+
+	if _, err := g.AdminRevokeComplimentaryTier("m1", "bronze"); err == nil {
+		t.Fatal("Expected an error for a tier the member has no access to")
+	}
+	if len(updates) != 1 {
+		t.Fatalf("Unexpected update: %v", updates)
+	}
+}
+
+func TestAdminSetMemberComped(t *testing.T) {
+	var updates []string
+	server := newMemberServer(t, Member{Id: "m1"}, &updates)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	if _, err := g.AdminSetMemberComped("m1", true); err != nil {
+		t.Fatalf("Cannot comp member: %s", err)
+	}
+	if _, err := g.AdminSetMemberComped("m1", false); err != nil {
+		t.Fatalf("Cannot uncomp member: %s", err)
+	}
+	if len(updates) != 2 || updates[0] != `{"members":[{"comped":true}]}` || updates[1] != `{"members":[{"comped":false}]}` {
+		t.Fatalf("Unexpected updates: %v", updates)
+	}
+}
+
+func TestComplimentaryTierNeedsGhost5(t *testing.T) {
+	g := New("http://127.0.0.1:0", "", testAdminKey)
+	g.SetVersion(Version{Major: 4, Minor: 48})
+
+	if _, err := g.AdminGrantComplimentaryTier("m1", "gold", nil); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+	if _, err := g.AdminRevokeComplimentaryTier("m1", "gold"); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+}
+
+func TestSubscriptionIsComplimentary(t *testing.T) {
+	var comped Subscription
+	comped.Status = "active"
+
+	paid := comped
+	paid.Id = "sub_1"
+	paid.Price.Amount = 500
+
+	if !comped.IsComplimentary() || paid.IsComplimentary() {
+		t.Fatalf("Unexpected IsComplimentary: %v, %v", comped.IsComplimentary(), paid.IsComplimentary())
+	}
+	if comped.StatusOf() != SubscriptionStatusActive {
+		t.Fatalf("Unexpected status %s", comped.StatusOf())
+	}
+}
+
+func TestSendMagicLink(t *testing.T) {
+	var paths []string
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/members/api/integrity-token/":
+			_, _ = w.Write([]byte("1714557600000:a1b2c3\n"))
+		case "/members/api/send-magic-link/":
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("Public members API got an Authorization header")
+			}
+			if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+				t.Errorf("Cannot decode request: %s", err)
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	g := New(server.URL, "", "")
+
+	if err := g.SendMagicLink("reader@example.com", MagicLinkSignup, []string{"VIP"}, []string{"Weekly", "Product updates"}); err != nil {
+		t.Fatalf("Cannot send magic link: %s", err)
+	}
+	if len(paths) != 2 || paths[0] != "GET /members/api/integrity-token/" || paths[1] != "POST /members/api/send-magic-link/" {
+		t.Fatalf("Unexpected requests: %v", paths)
+	}
+	want := map[string]interface{}{
+		"email":          "reader@example.com",
+		"emailType":      "signup",
+		"labels":         []interface{}{"VIP"},
+		"newsletters":    []interface{}{map[string]interface{}{"name": "Weekly"}, map[string]interface{}{"name": "Product updates"}},
+		"integrityToken": "1714557600000:a1b2c3",
+	}
+	if !reflect.DeepEqual(received, want) {
+		t.Fatalf("Unexpected request body: %+v", received)
+	}
+}
+
+func TestSendMagicLinkWithoutIntegrityToken(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/members/api/send-magic-link/" {
+			// Ghost versions before the integrity token
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+	g := New(server.URL, "", "")
+
+	if err := g.SendMagicLink("reader@example.com", MagicLinkSignin, nil, nil); err != nil {
+		t.Fatalf("Cannot send magic link: %s", err)
+	}
+	if _, found := received["integrityToken"]; found || received["emailType"] != "signin" {
+		t.Fatalf("Unexpected request body: %+v", received)
+	}
+	if _, found := received["newsletters"]; found {
+		t.Fatalf("Unexpected newsletters: %+v", received)
+	}
+}
+
+func TestAdminGetMemberSigninURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/ghost/api/v3/admin/members/m1/signin_urls/" || !strings.HasPrefix(r.Header.Get("Authorization"), "Ghost ") {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"member_signin_urls":[{"member_id":"m1","url":"https://example.com/members/?token=abc&action=signin"}]}`))
+	}))
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	url, err := g.AdminGetMemberSigninURL("m1")
+	if err != nil {
+		t.Fatalf("Cannot get sign-in URL: %s", err)
+	}
+	if url != "https://example.com/members/?token=abc&action=signin" {
+		t.Fatalf("Unexpected sign-in URL %s", url)
+	}
+	if _, err := g.AdminGetMemberSigninURL("m2"); err == nil {
+		t.Fatal("Expected an error for an unknown member")
+	}
+}
+
+func TestAdminGetSuppressedMembers(t *testing.T) {
+	var filters []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ghost/api/v3/admin/members/" {
+			http.NotFound(w, r)
+			return
+		}
+		filters = append(filters, r.URL.Query().Get("filter"))
+		if r.URL.Query().Get("page") == "1" {
+			_, _ = w.Write([]byte(`{"members":[{"id":"m1","email":"bounced@example.com","email_suppression":{"suppressed":true,"info":{"reason":"bounce","timestamp":"2024-05-01T10:00:00.000Z"}}}],` +
+				`"meta":{"pagination":{"page":1,"limit":100,"pages":2,"total":2,"next":2,"prev":null}}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"members":[{"id":"m2","email":"spam@example.com","email_suppression":{"suppressed":true,"info":{"reason":"spam","timestamp":"2024-05-02T10:00:00.000Z"}}}],` +
+			`"meta":{"pagination":{"page":2,"limit":100,"pages":2,"total":2,"next":null,"prev":1}}}`))
+	}))
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	members, err := g.AdminGetSuppressedMembers()
+	if err != nil {
+		t.Fatalf("Cannot get suppressed members: %s", err)
+	}
+	if !reflect.DeepEqual(filters, []string{"email_disabled:1", "email_disabled:1"}) {
+		t.Fatalf("Unexpected filters: %v", filters)
+	}
+	if len(members.Members) != 2 {
+		t.Fatalf("Expected 2 members, got %+v", members.Members)
+	}
+
+	bounced, spam := members.Members[0].EmailSuppression, members.Members[1].EmailSuppression
+	if !bounced.Suppressed || bounced.Info == nil || bounced.Info.Reason != EmailSuppressionBounce ||
+		!bounced.Info.Timestamp.Equal(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unexpected suppression: %+v", bounced)
+	}
+	if spam.Info == nil || spam.Info.Reason != EmailSuppressionSpam {
+		t.Fatalf("Unexpected suppression: %+v", spam)
+	}
+}
+
+func TestEmailSuppressionWithoutInfo(t *testing.T) {
+	var member Member
+	if err := json.Unmarshal([]byte(`{"id":"m1","email_suppression":{"suppressed":false,"info":null}}`), &member); err != nil {
+		t.Fatalf("Cannot decode member: %s", err)
+	}
+	if member.EmailSuppression.Suppressed || member.EmailSuppression.Info != nil {
+		t.Fatalf("Unexpected suppression: %+v", member.EmailSuppression)
+	}
+}
+
+func TestAdminDeleteMemberEmailSuppression(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		if r.Method != http.MethodDelete || r.URL.Path != "/ghost/api/v3/admin/members/m1/suppression/" {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Ghost ") {
+			t.Errorf("Missing Authorization header")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	if err := g.AdminDeleteMemberEmailSuppression("m1"); err != nil {
+		t.Fatalf("Cannot delete suppression: %s", err)
+	}
+	if err := g.AdminDeleteMemberEmailSuppression("m2"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Expected a 404 error, got %v", err)
+	}
+	if len(requests) != 2 || requests[0] != "DELETE /ghost/api/v3/admin/members/m1/suppression/" {
+		t.Fatalf("Unexpected requests: %v", requests)
+	}
+}
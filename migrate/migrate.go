@@ -0,0 +1,575 @@
+// Package migrate copies posts, pages and tags from one Ghost site to another, e.g. from
+// staging to production.
+//
+// Tags and authors are matched by slug, so IDs on the target are used for references. Links
+// to the source site are pointed to the target and images uploaded to the source are copied
+// to the target. Published dates, statuses and visibility are kept.
+//
+// Posts and pages that exist on the target (by slug) are skipped unless overwriting is enabled.
+// A state file remembers the migrated items and copied images, so an interrupted migration can
+// be run again and only does the remaining work.
+package migrate
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/siteimage"
+)
+
+// Source is the part of *ghost.Ghost the migrator reads from
+type Source interface {
+	AdminGetSite() (ghost.Site, error)
+	AdminGetPosts() (ghost.Posts, error)
+	AdminGetPages() (ghost.Pages, error)
+	AdminGetTags() (ghost.Tags, error)
+}
+
+// Target is the part of *ghost.Ghost the migrator writes to
+type Target interface {
+	AdminGetSite() (ghost.Site, error)
+	AdminGetPosts() (ghost.Posts, error)
+	AdminGetPages() (ghost.Pages, error)
+	AdminGetTags() (ghost.Tags, error)
+	AdminGetUsers() (ghost.Users, error)
+	AdminCreateTags(tags ghost.NewTags) error
+	AdminCreatePost(post ghost.Post) (ghost.Posts, error)
+	AdminUpdatePost(post ghost.Post, sourceType ghost.SourceType) error
+	AdminCreatePage(page ghost.Page) (ghost.Pages, error)
+	AdminUpdatePage(page ghost.Page, sourceType ghost.SourceType) error
+	AdminUploadImageReader(ctx context.Context, name string, r io.Reader, opts ghost.ImageUploadOptions) (ghost.Image, error)
+}
+
+type Action string
+
+const (
+	Created Action = "created"
+	Updated Action = "updated"
+	// Skipped items exist on the target and were not overwritten or didn't change since the last run
+	Skipped Action = "skipped"
+	Failed  Action = "failed"
+)
+
+// Item is the result of migrating one tag, post or page
+type Item struct {
+	Kind     string // "tag", "post" or "page"
+	Slug     string
+	SourceID string
+	TargetID string // empty in a dry run and for failed items
+	Action   Action
+	Err      error
+}
+
+func (i Item) String() string {
+	if i.Err != nil {
+		return fmt.Sprintf("%-7s %-4s %s: %v", i.Action, i.Kind, i.Slug, i.Err)
+	}
+	return fmt.Sprintf("%-7s %-4s %s", i.Action, i.Kind, i.Slug)
+}
+
+type Report struct {
+	Items []Item
+	// Images maps copied image URLs of the source to their URLs on the target
+	Images map[string]string
+	// FailedImages are images that couldn't be copied, references to them keep pointing to the source
+	FailedImages map[string]error
+}
+
+// Failed returns the items that couldn't be migrated
+func (r *Report) Failed() []Item {
+	var failed []Item
+	for _, item := range r.Items {
+		if item.Action == Failed {
+			failed = append(failed, item)
+		}
+	}
+	return failed
+}
+
+// Print writes one line per item
+func (r *Report) Print(w io.Writer) error {
+	for _, item := range r.Items {
+		if _, err := fmt.Fprintln(w, item); err != nil {
+			return err
+		}
+	}
+	for rawURL, err := range r.FailedImages {
+		if _, err := fmt.Fprintf(w, "failed  image %s: %v\n", rawURL, err); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type Migrator struct {
+	src       Source
+	dst       Target
+	statePath string
+	overwrite bool
+	dryRun    bool
+	images    bool
+	fetcher   ghost.Fetcher
+	authors   map[string]string
+}
+
+type Option func(m *Migrator)
+
+// WithStateFile enables resuming: migrated items and copied images are recorded in the file
+// at path and not migrated again unless they changed on the source
+func WithStateFile(path string) Option {
+	return func(m *Migrator) {
+		m.statePath = path
+	}
+}
+
+// WithOverwrite updates posts and pages that exist on the target with the source's version
+func WithOverwrite(enabled bool) Option {
+	return func(m *Migrator) {
+		m.overwrite = enabled
+	}
+}
+
+// WithDryRun reports what would be migrated without changing the target or the state file
+func WithDryRun(enabled bool) Option {
+	return func(m *Migrator) {
+		m.dryRun = enabled
+	}
+}
+
+// WithImages turns copying images on or off, on by default. Without it, images keep
+// pointing to the source site.
+func WithImages(enabled bool) Option {
+	return func(m *Migrator) {
+		m.images = enabled
+	}
+}
+
+// WithFetcher overrides how images are downloaded from the source, by default over HTTP
+func WithFetcher(fetcher ghost.Fetcher) Option {
+	return func(m *Migrator) {
+		m.fetcher = fetcher
+	}
+}
+
+// WithAuthors maps author slugs of the source to staff slugs on the target, for staff users
+// with different slugs on both sites. Staff users can't be created with the Admin API.
+func WithAuthors(slugs map[string]string) Option {
+	return func(m *Migrator) {
+		m.authors = slugs
+	}
+}
+
+// New creates a migrator from src to dst
+func New(src Source, dst Target, opts ...Option) *Migrator {
+	m := &Migrator{src: src, dst: dst, images: true}
+	for _, opt := range opts {
+		opt(m)
+	}
+	if m.fetcher == nil {
+		m.fetcher = ghost.HTTPFetcher(&http.Client{Timeout: 5 * time.Minute})
+	}
+	return m
+}
+
+// Run migrates all tags, posts and pages. Failed items are recorded in the report and don't
+// stop the others; the error is set if a site can't be read or items failed.
+func (m *Migrator) Run(ctx context.Context) (*Report, error) {
+	srcSite, err := m.src.AdminGetSite()
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	dstSite, err := m.dst.AdminGetSite()
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+	r := &run{
+		Migrator: m,
+		ctx:      ctx,
+		report:   &Report{Images: map[string]string{}, FailedImages: map[string]error{}},
+		srcURL:   strings.TrimSuffix(srcSite.URL, "/") + "/",
+		dstURL:   strings.TrimSuffix(dstSite.URL, "/") + "/",
+		tags:     map[string]string{},
+		users:    map[string]string{},
+	}
+	if r.srcURL == r.dstURL {
+		return nil, fmt.Errorf("source and target are the same site %s", r.srcURL)
+	}
+
+	r.state, err = loadState(m.statePath, r.srcURL, r.dstURL)
+	if err != nil {
+		return nil, err
+	}
+	r.rewrite = ghost.SiteURLRewriter(r.srcURL, r.dstURL, r.report.Images)
+
+	for _, step := range []func() error{r.migrateTags, r.loadUsers, r.migratePosts, r.migratePages} {
+		if err = ctx.Err(); err != nil {
+			break
+		}
+		if err = step(); err != nil {
+			break
+		}
+	}
+	if m.statePath != "" && !m.dryRun {
+		if saveErr := r.state.save(m.statePath); err == nil {
+			err = saveErr
+		}
+	}
+	if err != nil {
+		return r.report, err
+	}
+	if failed := len(r.report.Failed()); failed > 0 {
+		return r.report, fmt.Errorf("%d of %d items failed", failed, len(r.report.Items))
+	}
+	return r.report, nil
+}
+
+// run is the state of a single Run
+type run struct {
+	*Migrator
+	ctx     context.Context
+	report  *Report
+	state   *state
+	srcURL  string
+	dstURL  string
+	rewrite func(string) string
+	// tags maps tag IDs of the source to the target, users author slugs to user IDs on the target
+	tags  map[string]string
+	users map[string]string
+}
+
+func (r *run) add(item Item) {
+	if item.Err != nil {
+		item.Action = Failed
+	}
+	r.report.Items = append(r.report.Items, item)
+}
+
+func (r *run) migrateTags() error {
+	tags, err := r.src.AdminGetTags()
+	if err != nil {
+		return fmt.Errorf("source tags: %w", err)
+	}
+	existing, err := r.dst.AdminGetTags()
+	if err != nil {
+		return fmt.Errorf("target tags: %w", err)
+	}
+	bySlug := map[string]string{}
+	for _, tag := range existing.Tags {
+		bySlug[tag.Slug] = tag.Id
+	}
+
+	var created []ghost.Tag
+	for _, tag := range tags.Tags {
+		if id, ok := bySlug[tag.Slug]; ok {
+			r.tags[tag.Id] = id
+			r.add(Item{Kind: "tag", Slug: tag.Slug, SourceID: tag.Id, TargetID: id, Action: Skipped})
+			continue
+		}
+		if r.dryRun {
+			r.add(Item{Kind: "tag", Slug: tag.Slug, SourceID: tag.Id, Action: Created})
+			continue
+		}
+		err := r.dst.AdminCreateTags(ghost.NewTags{Tags: []ghost.NewTag{{
+			CreatedAt:          tag.CreatedAt,
+			UpdatedAt:          tag.UpdatedAt,
+			Name:               tag.Name,
+			Slug:               tag.Slug,
+			Description:        tag.Description,
+			FeatureImage:       r.image(tag.FeatureImage),
+			MetaTitle:          tag.MetaTitle,
+			MetaDescription:    tag.MetaDescription,
+			Visibility:         tag.Visibility,
+			TwitterImage:       r.image(tag.TwitterImage),
+			TwitterTitle:       tag.TwitterTitle,
+			TwitterDescription: tag.TwitterDescription,
+			CodeInjectionHead:  tag.CodeInjectionHead,
+			CodeInjectionFoot:  tag.CodeInjectionFoot,
+			CanonicalURL:       r.rewrite(tag.CanonicalURL),
+			AccentColor:        tag.AccentColor,
+		}}})
+		if err != nil {
+			r.add(Item{Kind: "tag", Slug: tag.Slug, SourceID: tag.Id, Err: err})
+			continue
+		}
+		created = append(created, tag)
+	}
+	if len(created) == 0 {
+		return nil
+	}
+
+	// creating tags doesn't return them, the new IDs come from the list
+	existing, err = r.dst.AdminGetTags()
+	if err != nil {
+		return fmt.Errorf("target tags: %w", err)
+	}
+	for _, tag := range existing.Tags {
+		bySlug[tag.Slug] = tag.Id
+	}
+	for _, tag := range created {
+		r.tags[tag.Id] = bySlug[tag.Slug]
+		r.add(Item{Kind: "tag", Slug: tag.Slug, SourceID: tag.Id, TargetID: bySlug[tag.Slug], Action: Created})
+	}
+	return nil
+}
+
+func (r *run) loadUsers() error {
+	users, err := r.dst.AdminGetUsers()
+	if err != nil {
+		return fmt.Errorf("target users: %w", err)
+	}
+	for _, user := range users.Users {
+		r.users[user.Slug] = user.ID
+	}
+	return nil
+}
+
+// tagRefs points tags to their IDs on the target, tags unknown to the target are created by Ghost
+func (r *run) tagRefs(tags []ghost.Tag) []ghost.Tag {
+	var refs []ghost.Tag
+	for _, tag := range tags {
+		if id, ok := r.tags[tag.Id]; ok && id != "" {
+			refs = append(refs, ghost.Tag{Id: id})
+			continue
+		}
+		refs = append(refs, ghost.Tag{Name: tag.Name, Slug: tag.Slug})
+	}
+	return refs
+}
+
+func (r *run) authorRefs(authors []ghost.Author) ([]ghost.Author, error) {
+	var refs []ghost.Author
+	for _, author := range authors {
+		slug := author.Slug
+		if mapped, ok := r.authors[slug]; ok {
+			slug = mapped
+		}
+		id, ok := r.users[slug]
+		if !ok {
+			return nil, fmt.Errorf("author %s is no staff user of the target", slug)
+		}
+		refs = append(refs, ghost.Author{ID: id})
+	}
+	return refs, nil
+}
+
+// content copies the images of the source and rewrites the content in the single format Ghost
+// should store, sending Lexical and Mobiledoc together is rejected and HTML would be converted
+func (r *run) content(lexical, mobiledoc, html string, urls []string) (string, string, string, ghost.SourceType) {
+	for _, rawURL := range urls {
+		r.image(rawURL)
+	}
+	switch {
+	case lexical != "":
+		return r.rewrite(lexical), "", "", ""
+	case mobiledoc != "":
+		return "", r.rewrite(mobiledoc), "", ""
+	default:
+		return "", "", r.rewrite(html), ghost.SourceHTML
+	}
+}
+
+// image copies an image uploaded to the source once and returns its URL on the target.
+// Other URLs are rewritten as links, failed images keep their source URL.
+func (r *run) image(rawURL string) string {
+	file, size, ok := siteimage.File(r.srcURL, rawURL)
+	if !ok || !r.images || r.dryRun {
+		return r.rewrite(rawURL)
+	}
+	if _, failed := r.report.FailedImages[rawURL]; failed {
+		return rawURL
+	}
+
+	uploaded, ok := r.state.Images[file]
+	if !ok {
+		var err error
+		uploaded, err = r.copyImage(file)
+		if err != nil {
+			r.report.FailedImages[rawURL] = err
+			return rawURL
+		}
+		r.state.Images[file] = uploaded
+	}
+	newURL := siteimage.Resized(uploaded, size)
+	r.report.Images[rawURL] = newURL
+	return newURL
+}
+
+func (r *run) copyImage(file string) (string, error) {
+	body, contentType, err := r.fetcher.Fetch(r.ctx, r.srcURL+siteimage.Path+file)
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	if !strings.HasPrefix(contentType, "image/") {
+		contentType = ""
+	}
+	image, err := r.dst.AdminUploadImageReader(r.ctx, path.Base(file), body, ghost.ImageUploadOptions{ContentType: contentType, Ref: file})
+	if err != nil {
+		return "", err
+	}
+	return image.URL, nil
+}
+
+func (r *run) migratePosts() error {
+	posts, err := r.src.AdminGetPosts()
+	if err != nil {
+		return fmt.Errorf("source posts: %w", err)
+	}
+	existing, err := r.dst.AdminGetPosts()
+	if err != nil {
+		return fmt.Errorf("target posts: %w", err)
+	}
+	bySlug := map[string]ghost.Post{}
+	for _, post := range existing.Posts {
+		bySlug[post.Slug] = post
+	}
+
+	for _, post := range posts.Posts {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		current, exists := bySlug[post.Slug]
+		item := Item{Kind: "post", Slug: post.Slug, SourceID: post.ID, TargetID: current.ID}
+		if exists && r.skip("post", post.Slug, post.UpdatedAt) {
+			item.Action = Skipped
+			r.add(item)
+			continue
+		}
+		r.add(r.migratePost(item, post, current, exists))
+	}
+	return nil
+}
+
+// skip reports whether an existing post or page is left alone: without overwriting, or
+// if it didn't change on the source since the state file recorded its migration
+func (r *run) skip(kind, slug, updatedAt string) bool {
+	if !r.overwrite {
+		return true
+	}
+	migrated, ok := r.state.Items[kind+"/"+slug]
+	return ok && migrated.UpdatedAt == updatedAt
+}
+
+func (r *run) migratePost(item Item, post ghost.Post, current ghost.Post, exists bool) Item {
+	sourceUpdatedAt := post.UpdatedAt
+	urls, err := post.ImageURLs()
+	if err != nil {
+		item.Err = err
+		return item
+	}
+	authors, err := r.authorRefs(post.Authors)
+	if err != nil {
+		item.Err = err
+		return item
+	}
+
+	var sourceType ghost.SourceType
+	post.Lexical, post.MobileDoc, post.HTML, sourceType = r.content(post.Lexical, post.MobileDoc, post.HTML, urls)
+	post.ID, post.UUID, post.URL, post.Excerpt, post.CommentID, post.UpdatedAt = "", "", "", "", "", ""
+	post.PostRevisions = nil
+	post.FeatureImage = r.image(post.FeatureImage)
+	post.OGImage = r.image(post.OGImage)
+	post.TwitterImage = r.image(post.TwitterImage)
+	post.CanonicalURL = r.rewrite(post.CanonicalURL)
+	post.Tags = r.tagRefs(post.Tags)
+	post.Authors = authors
+
+	item.Action = Created
+	if exists {
+		item.Action = Updated
+	}
+	if r.dryRun {
+		return item
+	}
+
+	if exists {
+		post.ID, post.UpdatedAt = current.ID, current.UpdatedAt
+		item.Err = r.dst.AdminUpdatePost(post, sourceType)
+	} else {
+		created, err := r.dst.AdminCreatePost(post)
+		if err == nil && len(created.Posts) > 0 {
+			item.TargetID = created.Posts[0].ID
+		}
+		item.Err = err
+	}
+	if item.Err == nil {
+		r.state.Items["post/"+item.Slug] = migrated{ID: item.TargetID, UpdatedAt: sourceUpdatedAt}
+	}
+	return item
+}
+
+func (r *run) migratePages() error {
+	pages, err := r.src.AdminGetPages()
+	if err != nil {
+		return fmt.Errorf("source pages: %w", err)
+	}
+	existing, err := r.dst.AdminGetPages()
+	if err != nil {
+		return fmt.Errorf("target pages: %w", err)
+	}
+	bySlug := map[string]ghost.Page{}
+	for _, page := range existing.Pages {
+		bySlug[page.Slug] = page
+	}
+
+	for _, page := range pages.Pages {
+		if err := r.ctx.Err(); err != nil {
+			return err
+		}
+		current, exists := bySlug[page.Slug]
+		item := Item{Kind: "page", Slug: page.Slug, SourceID: page.ID, TargetID: current.ID}
+		if exists && r.skip("page", page.Slug, page.UpdatedAt) {
+			item.Action = Skipped
+			r.add(item)
+			continue
+		}
+		r.add(r.migratePage(item, page, current, exists))
+	}
+	return nil
+}
+
+func (r *run) migratePage(item Item, page ghost.Page, current ghost.Page, exists bool) Item {
+	sourceUpdatedAt := page.UpdatedAt
+	urls, err := page.ImageURLs()
+	if err != nil {
+		item.Err = err
+		return item
+	}
+
+	var sourceType ghost.SourceType
+	page.Lexical, page.MobileDoc, page.HTML, sourceType = r.content(page.Lexical, page.MobileDoc, page.HTML, urls)
+	page.ID, page.UUID, page.URL, page.Excerpt, page.CommentID, page.UpdatedAt = "", "", "", "", "", ""
+	page.FeatureImage = r.image(page.FeatureImage)
+	page.OGImage = r.image(page.OGImage)
+	page.TwitterImage = r.image(page.TwitterImage)
+	page.Tags = r.tagRefs(page.Tags)
+
+	item.Action = Created
+	if exists {
+		item.Action = Updated
+	}
+	if r.dryRun {
+		return item
+	}
+
+	if exists {
+		page.ID, page.UpdatedAt = current.ID, current.UpdatedAt
+		item.Err = r.dst.AdminUpdatePage(page, sourceType)
+	} else {
+		created, err := r.dst.AdminCreatePage(page)
+		if err == nil && len(created.Pages) > 0 {
+			item.TargetID = created.Pages[0].ID
+		}
+		item.Err = err
+	}
+	if item.Err == nil {
+		r.state.Items["page/"+item.Slug] = migrated{ID: item.TargetID, UpdatedAt: sourceUpdatedAt}
+	}
+	return item
+}
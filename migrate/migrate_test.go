@@ -0,0 +1,129 @@
+package migrate
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sklinkert/ghost"
+	"github.com/sklinkert/ghost/internal/fakesite"
+)
+
+var (
+	_ Source = (*ghost.Ghost)(nil)
+	_ Target = (*ghost.Ghost)(nil)
+)
+
+func newSites() (*fakesite.Site, *fakesite.Site) {
+	staging := fakesite.New("https://staging.example.com")
+	staging.Images["https://staging.example.com/content/images/2024/01/chart.png"] = []byte("png")
+	news := ghost.Tag{Id: fakesite.NextID(), Name: "News", Slug: "news"}
+	staging.Tags = []ghost.Tag{news, {Id: fakesite.NextID(), Name: "Guides", Slug: "guides"}}
+	staging.Posts = []ghost.Post{{
+		ID:   fakesite.NextID(),
+		Slug: "launch",
+		Lexical: `{"root":{"children":[{"type":"image","version":1,"src":"https://staging.example.com/content/images/2024/01/chart.png"},` +
+			`{"type":"html","version":1,"html":"<a href=\"https://staging.example.com/guides/\">guides</a>"}],"type":"root","version":1}}`,
+		HTML:        "<p>rendered</p>",
+		Status:      "published",
+		PublishedAt: "2024-01-05T10:00:00.000Z",
+		UpdatedAt:   "2024-01-05T10:00:00.000Z",
+		Visibility:  "members",
+		Tags:        []ghost.Tag{news},
+		Authors:     []ghost.Author{{ID: "src-user", Slug: "jane"}},
+	}}
+	staging.Pages = []ghost.Page{{ID: fakesite.NextID(), Slug: "guides", HTML: `<p><img src="https://staging.example.com/content/images/size/w600/2024/01/chart.png"></p>`, Status: "draft"}}
+
+	production := fakesite.New("https://www.example.com")
+	production.Tags = []ghost.Tag{{Id: "dst-news", Name: "News", Slug: "news"}}
+	production.Users = []ghost.User{{ID: "dst-jane", Slug: "jane"}}
+	return staging, production
+}
+
+func TestMigrate(t *testing.T) {
+	staging, production := newSites()
+	statePath := filepath.Join(t.TempDir(), "migrate.json")
+	m := New(staging, production, WithFetcher(staging.Fetcher()), WithStateFile(statePath))
+
+	report, err := m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("%v: %v", err, report.Failed())
+	}
+
+	if len(production.Images) != 1 {
+		t.Fatalf("copied %d images, want 1", len(production.Images))
+	}
+	post := production.Posts[0]
+	for _, want := range []string{`"src":"https://www.example.com/content/images/2024/05/1-chart.png"`, `href=\"https://www.example.com/guides/\"`} {
+		if !strings.Contains(post.Lexical, want) {
+			t.Errorf("lexical lacks %s: %s", want, post.Lexical)
+		}
+	}
+	if post.HTML != "" || post.Status != "published" || post.PublishedAt != "2024-01-05T10:00:00.000Z" || post.Visibility != "members" {
+		t.Errorf("unexpected post %+v", post)
+	}
+	if len(post.Tags) != 1 || post.Tags[0].Id != "dst-news" {
+		t.Errorf("tag not mapped by slug: %+v", post.Tags)
+	}
+	if len(post.Authors) != 1 || post.Authors[0].ID != "dst-jane" {
+		t.Errorf("author not mapped by slug: %+v", post.Authors)
+	}
+	if len(production.Tags) != 2 || production.Tags[1].Slug != "guides" {
+		t.Errorf("missing tag not created: %+v", production.Tags)
+	}
+	page := production.Pages[0]
+	if page.Status != "draft" || !strings.Contains(page.HTML, `src="https://www.example.com/content/images/size/w600/2024/05/1-chart.png"`) {
+		t.Errorf("unexpected page %+v", page)
+	}
+
+	// resuming with overwrite only updates what changed on the source since the last run
+	m = New(staging, production, WithFetcher(staging.Fetcher()), WithStateFile(statePath), WithOverwrite(true))
+	staging.Pages[0].UpdatedAt = "2024-02-01T00:00:00.000Z"
+	report, err = m.Run(context.Background())
+	if err != nil {
+		t.Fatalf("%v: %v", err, report.Failed())
+	}
+	actions := map[string]Action{}
+	for _, item := range report.Items {
+		actions[item.Kind+"/"+item.Slug] = item.Action
+	}
+	if actions["post/launch"] != Skipped || actions["page/guides"] != Updated || actions["tag/guides"] != Skipped {
+		t.Errorf("unexpected actions %v", actions)
+	}
+	if len(production.Images) != 1 || len(production.Posts) != 1 || len(production.Pages) != 1 {
+		t.Errorf("resume duplicated content: %d images, %d posts, %d pages", len(production.Images), len(production.Posts), len(production.Pages))
+	}
+}
+
+func TestMigrateReportsUnknownAuthors(t *testing.T) {
+	staging, production := newSites()
+	production.Users = nil
+
+	report, err := New(staging, production, WithImages(false)).Run(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Slug != "launch" || !strings.Contains(failed[0].Err.Error(), "jane") {
+		t.Fatalf("unexpected failures %v", failed)
+	}
+	// without copying, images keep pointing to the source
+	if !strings.Contains(production.Pages[0].HTML, "https://staging.example.com/content/images/size/w600/2024/01/chart.png") {
+		t.Errorf("image was rewritten: %s", production.Pages[0].HTML)
+	}
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	staging, production := newSites()
+	report, err := New(staging, production, WithDryRun(true)).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(production.Posts) != 0 || len(production.Pages) != 0 || len(production.Tags) != 1 || len(production.Images) != 0 {
+		t.Fatal("dry run changed the target")
+	}
+	if len(report.Items) != 4 {
+		t.Errorf("expected 4 items, got %v", report.Items)
+	}
+}
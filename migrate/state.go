@@ -0,0 +1,57 @@
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// state is what the state file records about a migration between two sites
+type state struct {
+	Source string              `json:"source"`
+	Target string              `json:"target"`
+	Items  map[string]migrated `json:"items"`  // by "post/<slug>" or "page/<slug>"
+	Images map[string]string   `json:"images"` // file below content/images of the source -> URL on the target
+}
+
+type migrated struct {
+	ID        string `json:"id"`         // on the target
+	UpdatedAt string `json:"updated_at"` // of the source when it was migrated
+}
+
+// loadState reads the state file at path, an empty path or a missing file start a new migration
+func loadState(path, source, target string) (*state, error) {
+	s := &state{Source: source, Target: target, Items: map[string]migrated{}, Images: map[string]string{}}
+	if path == "" {
+		return s, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, err
+	}
+	if s.Source != source || s.Target != target {
+		return nil, fmt.Errorf("state file %s belongs to the migration from %s to %s", path, s.Source, s.Target)
+	}
+	if s.Items == nil {
+		s.Items = map[string]migrated{}
+	}
+	if s.Images == nil {
+		s.Images = map[string]string{}
+	}
+	return s, nil
+}
+
+func (s *state) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
@@ -0,0 +1,261 @@
+package mobiledoc
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+)
+
+var markupFormats = map[string]lexical.Format{
+	"b":      lexical.FormatBold,
+	"strong": lexical.FormatBold,
+	"i":      lexical.FormatItalic,
+	"em":     lexical.FormatItalic,
+	"s":      lexical.FormatStrikethrough,
+	"strike": lexical.FormatStrikethrough,
+	"del":    lexical.FormatStrikethrough,
+	"u":      lexical.FormatUnderline,
+	"code":   lexical.FormatCode,
+	"sub":    lexical.FormatSubscript,
+	"sup":    lexical.FormatSuperscript,
+	"mark":   lexical.FormatHighlight,
+}
+
+// ToLexical converts the document. Ghost cards with a Lexical counterpart of the same name
+// (gallery, video, audio, file, paywall, ...) are carried over with their payload unchanged.
+func ToLexical(doc *Document) (*lexical.Document, error) {
+	result := lexical.New()
+
+	for i, section := range doc.Sections {
+		node, err := convertSection(doc, section)
+		if err != nil {
+			return nil, fmt.Errorf("section %d: %w", i, err)
+		}
+		if node != nil {
+			result.Append(node)
+		}
+	}
+	return result, nil
+}
+
+func convertSection(doc *Document, section Section) (lexical.Node, error) {
+	switch section.Type {
+	case SectionMarkup:
+		inlines, err := convertMarkers(doc, section.Markers)
+		if err != nil {
+			return nil, err
+		}
+		tag := strings.ToLower(section.TagName)
+		switch tag {
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			level, _ := strconv.Atoi(tag[1:])
+			return lexical.NewHeading(level, inlines...), nil
+		case "blockquote":
+			return lexical.NewQuote(inlines...), nil
+		case "aside", "pull-quote":
+			return &lexical.Aside{Element: lexical.NewQuote(inlines...).Element}, nil
+		}
+		return lexical.NewParagraph(inlines...), nil
+	case SectionList:
+		listType := lexical.ListBullet
+		if strings.ToLower(section.TagName) == "ol" {
+			listType = lexical.ListNumber
+		}
+		var items []lexical.Node
+		for _, markers := range section.Items {
+			inlines, err := convertMarkers(doc, markers)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, lexical.NewListItem(inlines...))
+		}
+		return lexical.NewList(listType, items...), nil
+	case SectionImage:
+		return &lexical.ImageCard{Src: section.Src}, nil
+	case SectionCard:
+		if section.CardIndex < 0 || section.CardIndex >= len(doc.Cards) {
+			return nil, fmt.Errorf("unknown card %d", section.CardIndex)
+		}
+		return convertCard(doc.Cards[section.CardIndex])
+	}
+	return nil, fmt.Errorf("unknown section type %d", section.Type)
+}
+
+// convertMarkers replays the open/close markup stack. Consecutive markers inside the same
+// link markup end up in one Lexical link node.
+func convertMarkers(doc *Document, markers []Marker) ([]lexical.Node, error) {
+	var result []lexical.Node
+	var open []int
+	var currentLink *lexical.Link
+	currentLinkMarkup := -1
+
+	for _, marker := range markers {
+		for _, index := range marker.OpenMarkups {
+			if index < 0 || index >= len(doc.Markups) {
+				return nil, fmt.Errorf("unknown markup %d", index)
+			}
+			open = append(open, index)
+		}
+
+		var format lexical.Format
+		linkMarkup := -1
+		for _, index := range open {
+			markup := doc.Markups[index]
+			if strings.ToLower(markup.Tag) == "a" {
+				linkMarkup = index
+				continue
+			}
+			format |= markupFormats[strings.ToLower(markup.Tag)]
+		}
+
+		var node lexical.Node
+		switch marker.Type {
+		case MarkerText:
+			node = lexical.NewText(marker.Text, format)
+		case MarkerAtom:
+			if marker.AtomIndex < 0 || marker.AtomIndex >= len(doc.Atoms) {
+				return nil, fmt.Errorf("unknown atom %d", marker.AtomIndex)
+			}
+			atom := doc.Atoms[marker.AtomIndex]
+			if atom.Name == "soft-return" {
+				node = lexical.NewLineBreak()
+			} else {
+				node = lexical.NewText(atom.Text, format)
+			}
+		default:
+			return nil, fmt.Errorf("unknown marker type %d", marker.Type)
+		}
+
+		switch {
+		case linkMarkup < 0:
+			currentLink, currentLinkMarkup = nil, -1
+			result = append(result, node)
+		case linkMarkup == currentLinkMarkup && currentLink != nil:
+			currentLink.Children = append(currentLink.Children, node)
+		default:
+			markup := doc.Markups[linkMarkup]
+			currentLink = lexical.NewLink(markup.Attribute("href"), node)
+			currentLink.Rel = markup.Attribute("rel")
+			currentLink.Target = markup.Attribute("target")
+			currentLinkMarkup = linkMarkup
+			result = append(result, currentLink)
+		}
+
+		if marker.ClosedCount > len(open) {
+			return nil, fmt.Errorf("marker closes %d markups but only %d are open", marker.ClosedCount, len(open))
+		}
+		open = open[:len(open)-marker.ClosedCount]
+		if !containsIndex(open, currentLinkMarkup) {
+			currentLink, currentLinkMarkup = nil, -1
+		}
+	}
+	return result, nil
+}
+
+func containsIndex(indexes []int, index int) bool {
+	for _, i := range indexes {
+		if i == index {
+			return true
+		}
+	}
+	return false
+}
+
+func convertCard(card Card) (lexical.Node, error) {
+	p := payload(card.Payload)
+	switch card.Name {
+	case "image":
+		return &lexical.ImageCard{
+			Src:       p.string("src"),
+			Width:     p.int("width"),
+			Height:    p.int("height"),
+			Title:     p.string("title"),
+			Alt:       p.string("alt"),
+			Caption:   p.string("caption"),
+			CardWidth: p.string("cardWidth"),
+			Href:      p.string("href"),
+		}, nil
+	case "markdown", "card-markdown":
+		return &lexical.MarkdownCard{Markdown: p.string("markdown")}, nil
+	case "html":
+		return &lexical.HTMLCard{HTML: p.string("html")}, nil
+	case "code":
+		return &lexical.CodeBlockCard{Code: p.string("code"), Language: p.string("language"), Caption: p.string("caption")}, nil
+	case "hr":
+		return &lexical.HorizontalRule{}, nil
+	case "embed":
+		embed := &lexical.EmbedCard{
+			URL:       p.string("url"),
+			EmbedType: p.string("type"),
+			HTML:      p.string("html"),
+			Caption:   p.string("caption"),
+		}
+		if metadata, ok := card.Payload["metadata"].(map[string]interface{}); ok {
+			embed.Metadata = metadata
+		}
+		return embed, nil
+	case "bookmark":
+		bookmark := &lexical.BookmarkCard{URL: p.string("url"), Caption: p.string("caption")}
+		if metadata, ok := card.Payload["metadata"].(map[string]interface{}); ok {
+			m := payload(metadata)
+			bookmark.Metadata = lexical.BookmarkMetadata{
+				Icon:        m.string("icon"),
+				Title:       m.string("title"),
+				Description: m.string("description"),
+				Author:      m.string("author"),
+				Publisher:   m.string("publisher"),
+				Thumbnail:   m.string("thumbnail"),
+			}
+		}
+		return bookmark, nil
+	case "callout":
+		return &lexical.CalloutCard{
+			CalloutText:     p.string("calloutText"),
+			CalloutEmoji:    p.string("calloutEmoji"),
+			BackgroundColor: p.string("backgroundColor"),
+		}, nil
+	case "button":
+		return &lexical.ButtonCard{ButtonText: p.string("buttonText"), ButtonURL: p.string("buttonUrl"), Alignment: p.string("alignment")}, nil
+	case "toggle":
+		return &lexical.ToggleCard{Heading: p.string("heading"), Content: p.string("content")}, nil
+	}
+
+	// the remaining Ghost cards use the same field names in both formats
+	fields := map[string]interface{}{}
+	for key, value := range card.Payload {
+		fields[key] = value
+	}
+	fields["type"] = card.Name
+	fields["version"] = 1
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	return &lexical.RawNode{NodeType: card.Name, Raw: raw}, nil
+}
+
+type payload map[string]interface{}
+
+func (p payload) string(key string) string {
+	switch value := p[key].(type) {
+	case string:
+		return value
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return ""
+}
+
+func (p payload) int(key string) int {
+	switch value := p[key].(type) {
+	case float64:
+		return int(value)
+	case string:
+		i, _ := strconv.Atoi(value)
+		return i
+	}
+	return 0
+}
@@ -0,0 +1,271 @@
+// Package mobiledoc models the Mobiledoc documents older Ghost posts store in Post.MobileDoc
+// and converts them to Lexical.
+package mobiledoc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Section type identifiers of the Mobiledoc 0.3 format
+const (
+	SectionMarkup = 1
+	SectionImage  = 2
+	SectionList   = 3
+	SectionCard   = 10
+)
+
+// Marker type identifiers
+const (
+	MarkerText = 0
+	MarkerAtom = 1
+)
+
+type Document struct {
+	Version  string    `json:"version"`
+	Atoms    []Atom    `json:"atoms"`
+	Cards    []Card    `json:"cards"`
+	Markups  []Markup  `json:"markups"`
+	Sections []Section `json:"sections"`
+	// GhostVersion is set by Ghost, e.g. "4.0"
+	GhostVersion string `json:"ghostVersion,omitempty"`
+}
+
+// Atom is an inline element such as Ghost's "soft-return": [name, text, payload]
+type Atom struct {
+	Name    string
+	Text    string
+	Payload map[string]interface{}
+}
+
+// Card is a block level element: [name, payload]
+type Card struct {
+	Name    string
+	Payload map[string]interface{}
+}
+
+// Markup is an inline tag such as "b" or "a": [tagName, [attrName, attrValue, ...]]
+type Markup struct {
+	Tag        string
+	Attributes []string
+}
+
+// Attribute returns the value of an attribute, e.g. Attribute("href") of a link
+func (m Markup) Attribute(name string) string {
+	for i := 0; i+1 < len(m.Attributes); i += 2 {
+		if m.Attributes[i] == name {
+			return m.Attributes[i+1]
+		}
+	}
+	return ""
+}
+
+// Marker is a piece of text or an atom: [type, openMarkups, numberClosedMarkups, value]
+type Marker struct {
+	Type        int
+	OpenMarkups []int
+	ClosedCount int
+	Text        string // for MarkerText
+	AtomIndex   int    // for MarkerAtom
+}
+
+// Section is a block. Which fields are set depends on Type:
+// markup sections have TagName and Markers, list sections TagName and Items,
+// image sections Src and card sections CardIndex.
+type Section struct {
+	Type      int
+	TagName   string
+	Markers   []Marker
+	Items     [][]Marker
+	Src       string
+	CardIndex int
+}
+
+// Parse decodes a serialized document as found in Post.MobileDoc
+func Parse(data string) (*Document, error) {
+	var doc Document
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// String serializes the document for Post.MobileDoc
+func (d *Document) String() (string, error) {
+	data, err := json.Marshal(d)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func (a *Atom) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 2 {
+		return fmt.Errorf("invalid atom: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &a.Name); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &a.Text); err != nil {
+		return err
+	}
+	if len(raw) > 2 {
+		return json.Unmarshal(raw[2], &a.Payload)
+	}
+	return nil
+}
+
+func (a Atom) MarshalJSON() ([]byte, error) {
+	payload := a.Payload
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	return json.Marshal([]interface{}{a.Name, a.Text, payload})
+}
+
+func (c *Card) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 1 {
+		return fmt.Errorf("invalid card: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &c.Name); err != nil {
+		return err
+	}
+	if len(raw) > 1 {
+		return json.Unmarshal(raw[1], &c.Payload)
+	}
+	return nil
+}
+
+func (c Card) MarshalJSON() ([]byte, error) {
+	payload := c.Payload
+	if payload == nil {
+		payload = map[string]interface{}{}
+	}
+	return json.Marshal([]interface{}{c.Name, payload})
+}
+
+func (m *Markup) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 1 {
+		return fmt.Errorf("invalid markup: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &m.Tag); err != nil {
+		return err
+	}
+	if len(raw) > 1 {
+		return json.Unmarshal(raw[1], &m.Attributes)
+	}
+	return nil
+}
+
+func (m Markup) MarshalJSON() ([]byte, error) {
+	if len(m.Attributes) == 0 {
+		return json.Marshal([]interface{}{m.Tag})
+	}
+	return json.Marshal([]interface{}{m.Tag, m.Attributes})
+}
+
+func (m *Marker) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) != 4 {
+		return fmt.Errorf("invalid marker: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &m.Type); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[1], &m.OpenMarkups); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw[2], &m.ClosedCount); err != nil {
+		return err
+	}
+	if m.Type == MarkerAtom {
+		return json.Unmarshal(raw[3], &m.AtomIndex)
+	}
+	return json.Unmarshal(raw[3], &m.Text)
+}
+
+func (m Marker) MarshalJSON() ([]byte, error) {
+	openMarkups := m.OpenMarkups
+	if openMarkups == nil {
+		openMarkups = []int{}
+	}
+	var value interface{} = m.Text
+	if m.Type == MarkerAtom {
+		value = m.AtomIndex
+	}
+	return json.Marshal([]interface{}{m.Type, openMarkups, m.ClosedCount, value})
+}
+
+func (s *Section) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if len(raw) < 2 {
+		return fmt.Errorf("invalid section: %s", data)
+	}
+	if err := json.Unmarshal(raw[0], &s.Type); err != nil {
+		return err
+	}
+
+	switch s.Type {
+	case SectionMarkup:
+		if len(raw) < 3 {
+			return fmt.Errorf("invalid markup section: %s", data)
+		}
+		if err := json.Unmarshal(raw[1], &s.TagName); err != nil {
+			return err
+		}
+		return json.Unmarshal(raw[2], &s.Markers)
+	case SectionList:
+		if len(raw) < 3 {
+			return fmt.Errorf("invalid list section: %s", data)
+		}
+		if err := json.Unmarshal(raw[1], &s.TagName); err != nil {
+			return err
+		}
+		return json.Unmarshal(raw[2], &s.Items)
+	case SectionImage:
+		return json.Unmarshal(raw[1], &s.Src)
+	case SectionCard:
+		return json.Unmarshal(raw[1], &s.CardIndex)
+	}
+	return fmt.Errorf("unknown section type %d", s.Type)
+}
+
+func (s Section) MarshalJSON() ([]byte, error) {
+	switch s.Type {
+	case SectionMarkup:
+		markers := s.Markers
+		if markers == nil {
+			markers = []Marker{}
+		}
+		return json.Marshal([]interface{}{s.Type, s.TagName, markers})
+	case SectionList:
+		items := s.Items
+		if items == nil {
+			items = [][]Marker{}
+		}
+		return json.Marshal([]interface{}{s.Type, s.TagName, items})
+	case SectionImage:
+		return json.Marshal([]interface{}{s.Type, s.Src})
+	case SectionCard:
+		return json.Marshal([]interface{}{s.Type, s.CardIndex})
+	}
+	return nil, fmt.Errorf("unknown section type %d", s.Type)
+}
@@ -0,0 +1,107 @@
+package mobiledoc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/sklinkert/ghost/lexical"
+)
+
+const ghostMobiledoc = `{"version":"0.3.1","ghostVersion":"4.0",` +
+	`"atoms":[["soft-return","",{}]],` +
+	`"cards":[["image",{"src":"https://example.com/a.jpg","alt":"A","caption":"Cap","cardWidth":"wide","width":800,"height":600}],` +
+	`["code",{"code":"x := 1","language":"go"}],` +
+	`["gallery",{"images":[{"src":"g.jpg","row":0}]}]],` +
+	`"markups":[["b"],["a",["href","https://ghost.org","rel","noopener"]],["em"]],` +
+	`"sections":[[1,"h2",[[0,[],0,"Title"]]],` +
+	`[1,"p",[[0,[],0,"Hello "],[0,[0],1,"bold"],[0,[],0," and "],[0,[1],0,"a "],[0,[2],2,"link"],[1,[],0,0],[0,[],0,"next line"]]],` +
+	`[10,0],` +
+	`[3,"ol",[[[0,[],0,"one"]],[[0,[0],1,"two"]]]],` +
+	`[1,"blockquote",[[0,[],0,"Quote"]]],` +
+	`[10,1],` +
+	`[2,"https://example.com/old.jpg"],` +
+	`[10,2]]}`
+
+func TestParseRoundTrip(t *testing.T) {
+	doc, err := Parse(ghostMobiledoc)
+	if err != nil {
+		t.Fatalf("Cannot parse mobiledoc: %s", err)
+	}
+	if len(doc.Sections) != 8 || doc.Markups[1].Attribute("href") != "https://ghost.org" {
+		t.Fatalf("Unexpected document: %+v", doc)
+	}
+
+	serialized, err := doc.String()
+	if err != nil {
+		t.Fatalf("Cannot serialize mobiledoc: %s", err)
+	}
+	var got, want interface{}
+	_ = json.Unmarshal([]byte(serialized), &got)
+	_ = json.Unmarshal([]byte(ghostMobiledoc), &want)
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Round trip changed document:\n%s", serialized)
+	}
+}
+
+func TestToLexical(t *testing.T) {
+	doc, err := Parse(ghostMobiledoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := ToLexical(doc)
+	if err != nil {
+		t.Fatalf("Cannot convert: %s", err)
+	}
+
+	children := converted.Root.Children
+	if len(children) != 8 {
+		t.Fatalf("Expected 8 nodes, got %d", len(children))
+	}
+	if heading := children[0].(*lexical.Heading); heading.Tag != "h2" {
+		t.Fatalf("Unexpected heading %#v", heading)
+	}
+
+	paragraph := children[1].(*lexical.Paragraph)
+	if len(paragraph.Children) != 6 {
+		t.Fatalf("Unexpected paragraph children: %d", len(paragraph.Children))
+	}
+	if bold := paragraph.Children[1].(*lexical.Text); bold.Text != "bold" || bold.Format != lexical.FormatBold {
+		t.Fatalf("Unexpected bold text %#v", bold)
+	}
+	link := paragraph.Children[3].(*lexical.Link)
+	if link.URL != "https://ghost.org" || link.Rel != "noopener" || lexical.TextContent(link) != "a link" {
+		t.Fatalf("Unexpected link %#v", link)
+	}
+	if italic := link.Children[1].(*lexical.Text); italic.Format != lexical.FormatItalic {
+		t.Fatalf("Expected italic text inside link, got %#v", italic)
+	}
+	if _, ok := paragraph.Children[4].(*lexical.LineBreak); !ok {
+		t.Fatalf("Expected soft return to become a line break")
+	}
+
+	if image := children[2].(*lexical.ImageCard); image.Src != "https://example.com/a.jpg" || image.Width != 800 || image.CardWidth != "wide" {
+		t.Fatalf("Unexpected image %#v", image)
+	}
+	if list := children[3].(*lexical.List); list.ListType != lexical.ListNumber || len(list.Children) != 2 {
+		t.Fatalf("Unexpected list %#v", list)
+	}
+	if _, ok := children[4].(*lexical.Quote); !ok {
+		t.Fatalf("Expected quote")
+	}
+	if code := children[5].(*lexical.CodeBlockCard); code.Language != "go" {
+		t.Fatalf("Unexpected code card %#v", code)
+	}
+	if image := children[6].(*lexical.ImageCard); image.Src != "https://example.com/old.jpg" {
+		t.Fatalf("Unexpected image section %#v", image)
+	}
+
+	gallery := children[7].(*lexical.RawNode)
+	var fields map[string]interface{}
+	if err := json.Unmarshal(gallery.Raw, &fields); err != nil {
+		t.Fatal(err)
+	}
+	if gallery.Type() != "gallery" || fields["type"] != "gallery" || fields["images"] == nil {
+		t.Fatalf("Unexpected gallery %s", gallery.Raw)
+	}
+}
@@ -0,0 +1,130 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/sklinkert/ghost/internal/diff"
+	"github.com/sklinkert/ghost/lexical"
+	"github.com/sklinkert/ghost/mobiledoc"
+)
+
+type MobiledocMigrationOptions struct {
+	// DryRun only reports what would change, nothing is updated
+	DryRun bool
+	// SkipPosts and SkipPages exclude a resource type from the migration
+	SkipPosts bool
+	SkipPages bool
+}
+
+type MobiledocMigrationResult struct {
+	Type  string // "post" or "page"
+	ID    string
+	Slug  string
+	Title string
+	// Diff compares the HTML Ghost rendered from Mobiledoc with the HTML of the converted Lexical document
+	Diff     string
+	Migrated bool
+	Err      error
+}
+
+// MigrateMobiledocToLexical converts all posts and pages that only have Mobiledoc content to Lexical
+// and updates them. Failures of single items are reported in their result and don't stop the migration.
+func (g *Ghost) MigrateMobiledocToLexical(opts MobiledocMigrationOptions) ([]MobiledocMigrationResult, error) {
+	var results []MobiledocMigrationResult
+	if err := g.require(capabilityLexical); err != nil {
+		return results, err
+	}
+
+	if !opts.SkipPosts {
+		posts, err := g.AdminGetPosts()
+		if err != nil {
+			return results, err
+		}
+		for _, post := range posts.Posts {
+			if post.MobileDoc == "" || post.Lexical != "" {
+				continue
+			}
+			result := MobiledocMigrationResult{Type: "post", ID: post.ID, Slug: post.Slug, Title: post.Title}
+			g.migrateMobiledoc("posts", post.ID, post.UpdatedAt, post.MobileDoc, post.HTML, opts.DryRun, &result)
+			results = append(results, result)
+		}
+	}
+
+	if !opts.SkipPages {
+		pages, err := g.AdminGetPages()
+		if err != nil {
+			return results, err
+		}
+		for _, page := range pages.Pages {
+			if page.MobileDoc == "" || page.Lexical != "" {
+				continue
+			}
+			result := MobiledocMigrationResult{Type: "page", ID: page.ID, Slug: page.Slug, Title: page.Title}
+			g.migrateMobiledoc("pages", page.ID, page.UpdatedAt, page.MobileDoc, page.HTML, opts.DryRun, &result)
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+func (g *Ghost) migrateMobiledoc(resource, id, updatedAt, source, currentHTML string, dryRun bool, result *MobiledocMigrationResult) {
+	doc, err := mobiledoc.Parse(source)
+	if err != nil {
+		result.Err = fmt.Errorf("cannot parse mobiledoc: %w", err)
+		return
+	}
+	converted, err := mobiledoc.ToLexical(doc)
+	if err != nil {
+		result.Err = fmt.Errorf("cannot convert mobiledoc: %w", err)
+		return
+	}
+
+	renderedHTML, err := lexical.RenderHTML(converted)
+	if err != nil {
+		result.Err = fmt.Errorf("cannot render lexical: %w", err)
+		return
+	}
+	result.Diff = diff.Unified("mobiledoc", "lexical", htmlLines(currentHTML), htmlLines(renderedHTML))
+
+	if dryRun {
+		return
+	}
+
+	serialized, err := converted.String()
+	if err != nil {
+		result.Err = err
+		return
+	}
+	if err := g.adminSwitchToLexical(resource, id, updatedAt, serialized); err != nil {
+		result.Err = err
+		return
+	}
+	result.Migrated = true
+}
+
+// adminSwitchToLexical sets the lexical content and clears mobiledoc, Ghost refuses content with both
+func (g *Ghost) adminSwitchToLexical(resource, id, updatedAt, serialized string) error {
+	update := map[string][]map[string]interface{}{
+		resource: {{
+			"lexical":    serialized,
+			"mobiledoc":  nil,
+			"updated_at": updatedAt,
+		}},
+	}
+	data, err := json.Marshal(&update)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/%s/%s/", g.url, resource, id)
+	var response map[string]interface{}
+	return g.putJson(url, data, &response)
+}
+
+// htmlLines puts every tag on its own line so diffs stay readable
+func htmlLines(html string) string {
+	return strings.ReplaceAll(html, "><", ">\n<") + "\n"
+}
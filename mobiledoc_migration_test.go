@@ -0,0 +1,119 @@
+package ghost
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const migrationMobiledoc = `{"version":"0.3.1","atoms":[],"cards":[],"markups":[["b"]],` +
+	`"sections":[[1,"p",[[0,[],0,"Hello "],[0,[0],1,"world"]]]]}`
+
+// newMigrationServer serves a Mobiledoc post, a post already in Lexical and a Mobiledoc page.
+// The bodies of all PUT requests are recorded by path.
+func newMigrationServer(t *testing.T, updates map[string]map[string]interface{}) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/ghost/api/v3/admin/posts/":
+			_, _ = io.WriteString(w, `{"posts":[`+
+				`{"id":"p1","slug":"old","title":"Old","mobiledoc":`+jsonString(migrationMobiledoc)+`,"html":"<p>Hello <b>world</b></p>","updated_at":"2024-05-01T10:00:00.000Z"},`+
+				`{"id":"p2","slug":"new","title":"New","lexical":"{}","html":"<p>New</p>"}]}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/ghost/api/v3/admin/pages/":
+			_, _ = io.WriteString(w, `{"pages":[`+
+				`{"id":"a1","slug":"about","title":"About","mobiledoc":`+jsonString(migrationMobiledoc)+`,"html":"<p>Hello world</p>","updated_at":"2024-05-02T10:00:00.000Z"}]}`)
+		case r.Method == http.MethodPut:
+			var body map[string][]map[string]interface{}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Errorf("Cannot decode update: %s", err)
+			}
+			for _, items := range body {
+				updates[r.URL.Path] = items[0]
+			}
+			_ = json.NewEncoder(w).Encode(body)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func jsonString(s string) string {
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+func TestMigrateMobiledocToLexicalDryRun(t *testing.T) {
+	updates := map[string]map[string]interface{}{}
+	server := newMigrationServer(t, updates)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	results, err := g.MigrateMobiledocToLexical(MobiledocMigrationOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(updates) != 0 {
+		t.Fatalf("Dry run updated content: %+v", updates)
+	}
+	if len(results) != 2 || results[0].ID != "p1" || results[0].Type != "post" || results[1].ID != "a1" || results[1].Type != "page" {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	for _, result := range results {
+		if result.Err != nil || result.Migrated {
+			t.Fatalf("Unexpected result: %+v", result)
+		}
+	}
+
+	// the post's HTML differs in the bold tag, the page's HTML lacks the bold text
+	if !strings.Contains(results[0].Diff, "-<p>Hello <b>world</b>\n") || !strings.Contains(results[0].Diff, "+<p>Hello <strong>world</strong>\n") {
+		t.Fatalf("Unexpected post diff:\n%s", results[0].Diff)
+	}
+	if !strings.Contains(results[1].Diff, "-<p>Hello world</p>\n") {
+		t.Fatalf("Unexpected page diff:\n%s", results[1].Diff)
+	}
+}
+
+func TestMigrateMobiledocToLexical(t *testing.T) {
+	updates := map[string]map[string]interface{}{}
+	server := newMigrationServer(t, updates)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	results, err := g.MigrateMobiledocToLexical(MobiledocMigrationOptions{SkipPages: true})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(results) != 1 || !results[0].Migrated || results[0].Err != nil {
+		t.Fatalf("Unexpected results: %+v", results)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("Expected one update, got %+v", updates)
+	}
+
+	update, found := updates["/ghost/api/v3/admin/posts/p1/"]
+	if !found {
+		t.Fatalf("Post not updated: %+v", updates)
+	}
+	if mobiledoc, found := update["mobiledoc"]; !found || mobiledoc != nil {
+		t.Fatalf("Mobiledoc not cleared: %+v", update)
+	}
+	if update["updated_at"] != "2024-05-01T10:00:00.000Z" {
+		t.Fatalf("Unexpected updated_at: %+v", update)
+	}
+	serialized, _ := update["lexical"].(string)
+	if !strings.Contains(serialized, `"text":"world"`) || !strings.Contains(serialized, `"type":"root"`) {
+		t.Fatalf("Unexpected lexical: %s", serialized)
+	}
+}
+
+func TestMigrateMobiledocToLexicalNeedsLexical(t *testing.T) {
+	g := New("http://localhost", "", testAdminKey)
+	g.SetVersion(Version{Major: 5, Minor: 53})
+
+	if _, err := g.MigrateMobiledocToLexical(MobiledocMigrationOptions{}); err == nil {
+		t.Fatal("Expected an error for Ghost 5.53")
+	}
+}
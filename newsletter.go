@@ -0,0 +1,65 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Newsletters struct {
+	Newsletters []Newsletter `json:"newsletters"`
+	Meta        Pagination   `json:"meta,omitempty"`
+}
+
+type Newsletter struct {
+	Id                string `json:"id,omitempty"`
+	Name              string `json:"name"`
+	Slug              string `json:"slug,omitempty"`
+	Description       string `json:"description,omitempty"`
+	Status            string `json:"status,omitempty"`     // "active" or "archived"
+	Visibility        string `json:"visibility,omitempty"` // "members" or "paid"
+	SenderName        string `json:"sender_name,omitempty"`
+	SenderEmail       string `json:"sender_email,omitempty"` // Ghost sends a verification email before using it
+	SenderReplyTo     string `json:"sender_reply_to,omitempty"`
+	SubscribeOnSignup *bool  `json:"subscribe_on_signup,omitempty"` // Ghost defaults to true
+	SortOrder         int    `json:"sort_order,omitempty"`
+	HeaderImage       string `json:"header_image,omitempty"`
+}
+
+// NewsletterRef references a newsletter by ID, e.g. when creating a member
+type NewsletterRef struct {
+	Id string `json:"id"`
+}
+
+// AdminGetNewsletters returns all newsletters including archived ones
+func (g *Ghost) AdminGetNewsletters() (Newsletters, error) {
+	var newsletters Newsletters
+	if err := g.require(capabilityNewsletters); err != nil {
+		return newsletters, err
+	}
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/newsletters/?limit=all", g.url)
+
+	if err := g.getJson(url, &newsletters); err != nil {
+		return newsletters, err
+	}
+	return newsletters, nil
+}
+
+// AdminCreateNewsletter creates a newsletter without subscribing existing members to it
+func (g *Ghost) AdminCreateNewsletter(newsletter Newsletter) (Newsletters, error) {
+	var newsletters Newsletters
+	if err := g.require(capabilityNewsletters); err != nil {
+		return newsletters, err
+	}
+
+	newsletter.Id = ""
+	data, err := json.Marshal(map[string][]Newsletter{"newsletters": {newsletter}})
+	if err != nil {
+		return newsletters, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/newsletters/?opt_in_existing=false", g.url)
+	if err := g.postJson(url, data, &newsletters); err != nil {
+		return newsletters, err
+	}
+	return newsletters, nil
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+
+	"github.com/sklinkert/ghost/lexical"
 )
 
 type Pages struct {
@@ -57,9 +59,9 @@ func (g *Ghost) GetPages() (Pages, error) {
 }
 
 func (g *Ghost) AdminGetPages() (Pages, error) {
-	const ghostPagesURLSuffix = "%s/ghost/api/v3/admin/pages/?key=%s&limit=all&include=tags&formats=html,lexical,mobiledoc"
+	const ghostPagesURLSuffix = "%s/ghost/api/v3/admin/pages/?key=%s&limit=all&include=tags&formats=%s"
 	var pages Pages
-	var url = fmt.Sprintf(ghostPagesURLSuffix, g.url, g.contentAPIToken)
+	var url = fmt.Sprintf(ghostPagesURLSuffix, g.url, g.contentAPIToken, g.adminFormats())
 
 	if err := g.getJson(url, &pages); err != nil {
 		return pages, err
@@ -69,9 +71,9 @@ func (g *Ghost) AdminGetPages() (Pages, error) {
 }
 
 func (g *Ghost) AdminGetPage(pageId string) (Pages, error) {
-	const ghostPagesURLSuffix = "%s/ghost/api/v3/admin/pages/%s/?key=%s&include=tags&formats=html,lexical,mobiledoc"
+	const ghostPagesURLSuffix = "%s/ghost/api/v3/admin/pages/%s/?key=%s&include=tags&formats=%s"
 	var pages Pages
-	var url = fmt.Sprintf(ghostPagesURLSuffix, g.url, pageId, g.contentAPIToken)
+	var url = fmt.Sprintf(ghostPagesURLSuffix, g.url, pageId, g.contentAPIToken, g.adminFormats())
 
 	if err := g.getJson(url, &pages); err != nil {
 		return pages, err
@@ -82,6 +84,11 @@ func (g *Ghost) AdminGetPage(pageId string) (Pages, error) {
 
 func (g *Ghost) AdminCreatePage(page Page) (Pages, error) {
 	var pages Pages
+	if page.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return pages, err
+		}
+	}
 
 	newPage := Pages{Pages: []Page{page}}
 	createData, err := json.Marshal(&newPage)
@@ -102,6 +109,17 @@ func (g *Ghost) AdminCreatePage(page Page) (Pages, error) {
 }
 
 func (g *Ghost) AdminUpdatePage(page Page, sourceType SourceType) error {
+	return g.withSlugRedirect("page", page.ID, page.Slug, func() error {
+		return g.adminUpdatePage(page, sourceType)
+	})
+}
+
+func (g *Ghost) adminUpdatePage(page Page, sourceType SourceType) error {
+	if page.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return err
+		}
+	}
 	if err := g.checkAndRenewJWT(); err != nil {
 		return err
 	}
@@ -169,3 +187,21 @@ func (g *Ghost) AdminDeletePage(pageId string) error {
 	}
 	return nil
 }
+
+// SetLexical serializes doc into the page's Lexical field
+func (p *Page) SetLexical(doc *lexical.Document) error {
+	serialized, err := doc.String()
+	if err != nil {
+		return err
+	}
+	p.Lexical = serialized
+	return nil
+}
+
+// LexicalDocument parses the page's Lexical field, it is only set when fetched via the Admin API
+func (p Page) LexicalDocument() (*lexical.Document, error) {
+	if p.Lexical == "" {
+		return nil, fmt.Errorf("page %s has no lexical content", p.ID)
+	}
+	return lexical.Parse(p.Lexical)
+}
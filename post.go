@@ -7,6 +7,8 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+
+	"github.com/sklinkert/ghost/lexical"
 )
 
 type SourceType string
@@ -51,36 +53,50 @@ type PostRevision struct {
 }
 
 type Post struct {
-	ID                 string         `json:"id,omitempty"`
-	UUID               string         `json:"uuid,omitempty"`
-	Title              string         `json:"title,omitempty"`
-	Lexical            string         `json:"lexical,omitempty"`
-	MobileDoc          string         `json:"mobiledoc,omitempty"`
-	Slug               string         `json:"slug,omitempty"`
-	HTML               string         `json:"html,omitempty"`
-	CommentID          string         `json:"comment_id,omitempty"`
-	FeatureImage       string         `json:"feature_image,omitempty"`
-	Featured           bool           `json:"featured,omitempty"`
-	Page               bool           `json:"page,omitempty"`
-	MetaTitle          string         `json:"meta_title,omitempty"`
-	MetaDescription    string         `json:"meta_description,omitempty"`
-	CreatedAt          string         `json:"created_at,omitempty"`   // "2022-01-05T22:39:28.000Z"
-	UpdatedAt          string         `json:"updated_at,omitempty"`   // "2022-04-02T16:01:24.000Z"
-	PublishedAt        string         `json:"published_at,omitempty"` // "2022-01-19T06:31:00.000Z"
-	CustomExcerpt      string         `json:"custom_excerpt,omitempty"`
-	OGImage            string         `json:"og_image,omitempty"`
-	OGTitle            string         `json:"og_title,omitempty"`
-	OGDescription      string         `json:"og_description,omitempty"`
-	TwitterImage       string         `json:"twitter_image,omitempty"`
-	TwitterTitle       string         `json:"twitter_title,omitempty"`
-	TwitterDescription string         `json:"twitter_description,omitempty"`
-	CustomTemplate     string         `json:"custom_template,omitempty"`
-	URL                string         `json:"url,omitempty"`
-	Excerpt            string         `json:"excerpt,omitempty"`
-	Tags               []Tag          `json:"tags,omitempty"`
-	Status             string         `json:"status,omitempty"`     // "published"
-	Visibility         string         `json:"visibility,omitempty"` // "public"
-	PostRevisions      []PostRevision `json:"post_revisions,omitempty"`
+	ID                  string         `json:"id,omitempty"`
+	UUID                string         `json:"uuid,omitempty"`
+	Title               string         `json:"title,omitempty"`
+	Lexical             string         `json:"lexical,omitempty"`
+	MobileDoc           string         `json:"mobiledoc,omitempty"`
+	Slug                string         `json:"slug,omitempty"`
+	HTML                string         `json:"html,omitempty"`
+	CommentID           string         `json:"comment_id,omitempty"`
+	FeatureImage        string         `json:"feature_image,omitempty"`
+	FeatureImageAlt     string         `json:"feature_image_alt,omitempty"`
+	FeatureImageCaption string         `json:"feature_image_caption,omitempty"`
+	Featured            bool           `json:"featured,omitempty"`
+	Page                bool           `json:"page,omitempty"`
+	MetaTitle           string         `json:"meta_title,omitempty"`
+	MetaDescription     string         `json:"meta_description,omitempty"`
+	CreatedAt           string         `json:"created_at,omitempty"`   // "2022-01-05T22:39:28.000Z"
+	UpdatedAt           string         `json:"updated_at,omitempty"`   // "2022-04-02T16:01:24.000Z"
+	PublishedAt         string         `json:"published_at,omitempty"` // "2022-01-19T06:31:00.000Z"
+	CustomExcerpt       string         `json:"custom_excerpt,omitempty"`
+	OGImage             string         `json:"og_image,omitempty"`
+	OGTitle             string         `json:"og_title,omitempty"`
+	OGDescription       string         `json:"og_description,omitempty"`
+	TwitterImage        string         `json:"twitter_image,omitempty"`
+	TwitterTitle        string         `json:"twitter_title,omitempty"`
+	TwitterDescription  string         `json:"twitter_description,omitempty"`
+	CustomTemplate      string         `json:"custom_template,omitempty"`
+	CanonicalURL        string         `json:"canonical_url,omitempty"`
+	URL                 string         `json:"url,omitempty"`
+	Excerpt             string         `json:"excerpt,omitempty"`
+	Tags                []Tag          `json:"tags,omitempty"`
+	Authors             []Author       `json:"authors,omitempty"`
+	Status              string         `json:"status,omitempty"`     // "published"
+	Visibility          string         `json:"visibility,omitempty"` // "public"
+	PostRevisions       []PostRevision `json:"post_revisions,omitempty"`
+}
+
+// Author is a staff user credited on a post. Ghost resolves authors of new posts by ID or email.
+type Author struct {
+	ID           string `json:"id,omitempty"`
+	Name         string `json:"name,omitempty"`
+	Slug         string `json:"slug,omitempty"`
+	Email        string `json:"email,omitempty"`
+	ProfileImage string `json:"profile_image,omitempty"`
+	URL          string `json:"url,omitempty"`
 }
 
 type Posts struct {
@@ -88,9 +104,9 @@ type Posts struct {
 }
 
 func (g *Ghost) AdminGetPosts() (Posts, error) {
-	const ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/?key=%s&limit=all&include=tags&formats=html,lexical,mobiledoc"
+	const ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/?key=%s&limit=all&include=tags,authors&formats=%s"
 	var posts Posts
-	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, g.contentAPIToken)
+	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, g.contentAPIToken, g.adminFormats())
 
 	if err := g.getJson(url, &posts); err != nil {
 		return posts, err
@@ -100,9 +116,14 @@ func (g *Ghost) AdminGetPosts() (Posts, error) {
 }
 
 func (g *Ghost) AdminGetPost(postId string) (Posts, error) {
-	const ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/%s/?key=%s&include=tags,authors,authors.roles,email,tiers,newsletter,count.clicks,post_revisions,post_revisions.author&formats=html,lexical,mobiledoc"
+	const ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/%s/?key=%s&include=%s&formats=%s"
 	var posts Posts
-	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, postId, g.contentAPIToken)
+	include := "tags,authors,authors.roles,email,tiers,newsletter,count.clicks,post_revisions,post_revisions.author"
+	if capabilities, ok := g.knownCapabilities(); ok && !capabilities.Tiers {
+		// Ghost 4 has neither tiers, newsletters nor post revisions
+		include = "tags,authors,authors.roles,email"
+	}
+	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, postId, g.contentAPIToken, include, g.adminFormats())
 
 	if err := g.getJson(url, &posts); err != nil {
 		return posts, err
@@ -112,9 +133,9 @@ func (g *Ghost) AdminGetPost(postId string) (Posts, error) {
 }
 
 func (g *Ghost) AdminGetPostsByTag(tag string) (Posts, error) {
-	var ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/?key=%s&limit=all&formats=html,lexical&filter=tag:" + tag
+	var ghostPostsURLSuffix = "%s/ghost/api/v3/admin/posts/?key=%s&limit=all&formats=%s&filter=tag:" + tag
 	var posts Posts
-	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, g.contentAPIToken)
+	var url = fmt.Sprintf(ghostPostsURLSuffix, g.url, g.contentAPIToken, g.adminFormats())
 
 	if err := g.getJson(url, &posts); err != nil {
 		return posts, err
@@ -161,6 +182,11 @@ func (g *Ghost) GetPostsByTag(tag string) (Posts, error) {
 
 func (g *Ghost) AdminCreatePost(post Post) (Posts, error) {
 	var posts Posts
+	if post.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return posts, err
+		}
+	}
 
 	newPost := Posts{Posts: []Post{post}}
 	updateData, err := json.Marshal(&newPost)
@@ -181,6 +207,17 @@ func (g *Ghost) AdminCreatePost(post Post) (Posts, error) {
 }
 
 func (g *Ghost) AdminUpdatePost(post Post, sourceType SourceType) error {
+	return g.withSlugRedirect("post", post.ID, post.Slug, func() error {
+		return g.adminUpdatePost(post, sourceType)
+	})
+}
+
+func (g *Ghost) adminUpdatePost(post Post, sourceType SourceType) error {
+	if post.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return err
+		}
+	}
 	if err := g.checkAndRenewJWT(); err != nil {
 		return err
 	}
@@ -270,3 +307,21 @@ func (g *Ghost) AdminSearchPosts(query string) (Posts, error) {
 
 	return posts, nil
 }
+
+// SetLexical serializes doc into the post's Lexical field
+func (p *Post) SetLexical(doc *lexical.Document) error {
+	serialized, err := doc.String()
+	if err != nil {
+		return err
+	}
+	p.Lexical = serialized
+	return nil
+}
+
+// LexicalDocument parses the post's Lexical field, it is only set when fetched via the Admin API
+func (p Post) LexicalDocument() (*lexical.Document, error) {
+	if p.Lexical == "" {
+		return nil, fmt.Errorf("post %s has no lexical content", p.ID)
+	}
+	return lexical.Parse(p.Lexical)
+}
@@ -0,0 +1,191 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Redirect is an entry of Ghost's redirects file. From is a regular expression matched
+// against the request path, the first matching redirect wins.
+type Redirect struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Permanent bool   `json:"permanent,omitempty"` // 301 instead of 302
+}
+
+// FileValidationError lists the problems found in a redirects or routes file
+type FileValidationError struct {
+	File     string
+	Problems []string
+}
+
+func (e *FileValidationError) Error() string {
+	return e.File + ": " + strings.Join(e.Problems, "; ")
+}
+
+// ParseRedirects parses redirects.json or the redirects.yaml format:
+//
+//	301:
+//	  /old/: /new/
+//	302:
+//	  /sale/: /offers/
+func ParseRedirects(data []byte) ([]Redirect, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return nil, nil
+	}
+	if trimmed[0] == '[' {
+		var redirects []Redirect
+		if err := json.Unmarshal(trimmed, &redirects); err != nil {
+			return nil, err
+		}
+		return redirects, nil
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(trimmed, &document); err != nil {
+		return nil, err
+	}
+	if len(document.Content) == 0 {
+		return nil, nil
+	}
+	root := document.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("redirects.yaml: expected a mapping of status codes")
+	}
+
+	var redirects []Redirect
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		status, entries := root.Content[i].Value, root.Content[i+1]
+		if status != "301" && status != "302" {
+			return nil, fmt.Errorf("redirects.yaml: unknown status code %q, expected 301 or 302", status)
+		}
+		if entries.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("redirects.yaml: expected a mapping of paths below %s", status)
+		}
+		for j := 0; j+1 < len(entries.Content); j += 2 {
+			redirects = append(redirects, Redirect{
+				From:      entries.Content[j].Value,
+				To:        entries.Content[j+1].Value,
+				Permanent: status == "301",
+			})
+		}
+	}
+	return redirects, nil
+}
+
+// MarshalRedirects encodes redirects as redirects.json
+func MarshalRedirects(redirects []Redirect) ([]byte, error) {
+	if redirects == nil {
+		redirects = []Redirect{}
+	}
+	return json.MarshalIndent(redirects, "", "  ")
+}
+
+// MarshalRedirectsYAML encodes redirects as redirects.yaml. The format groups redirects by
+// status code, so the order between permanent and temporary redirects is lost.
+func MarshalRedirectsYAML(redirects []Redirect) ([]byte, error) {
+	groups := map[bool]*yaml.Node{}
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, permanent := range []bool{true, false} {
+		status := "302"
+		if permanent {
+			status = "301"
+		}
+		group := &yaml.Node{Kind: yaml.MappingNode}
+		groups[permanent] = group
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: status}, group)
+	}
+	for _, redirect := range redirects {
+		group := groups[redirect.Permanent]
+		group.Content = append(group.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: redirect.From},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: redirect.To})
+	}
+
+	var content []*yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if len(root.Content[i+1].Content) > 0 {
+			content = append(content, root.Content[i], root.Content[i+1])
+		}
+	}
+	root.Content = content
+	return marshalGhostYAML(root)
+}
+
+// ValidateRedirects returns a *FileValidationError if redirects would be rejected by Ghost
+// or could never match
+func ValidateRedirects(redirects []Redirect) error {
+	var problems []string
+	seen := map[string]int{}
+	for i, redirect := range redirects {
+		entry := fmt.Sprintf("redirect %d", i+1)
+		if redirect.From == "" {
+			problems = append(problems, entry+`: "from" is required`)
+		}
+		if redirect.To == "" {
+			problems = append(problems, entry+`: "to" is required`)
+		}
+		if redirect.From != "" && redirect.From == redirect.To {
+			problems = append(problems, fmt.Sprintf("%s: %s redirects to itself", entry, redirect.From))
+		}
+		if first, ok := seen[redirect.From]; ok && redirect.From != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s is already redirected by redirect %d", entry, redirect.From, first))
+		} else {
+			seen[redirect.From] = i + 1
+		}
+	}
+	if len(problems) > 0 {
+		return &FileValidationError{File: "redirects.json", Problems: problems}
+	}
+	return nil
+}
+
+// AdminDownloadRedirects writes the redirects file as stored by Ghost (JSON or YAML) to w
+func (g *Ghost) AdminDownloadRedirects(ctx context.Context, w io.Writer) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/redirects/download/", g.url)
+	return g.download(ctx, url, w)
+}
+
+// AdminGetRedirects downloads and parses the redirects
+func (g *Ghost) AdminGetRedirects(ctx context.Context) ([]Redirect, error) {
+	var buf bytes.Buffer
+	if err := g.AdminDownloadRedirects(ctx, &buf); err != nil {
+		return nil, err
+	}
+	return ParseRedirects(buf.Bytes())
+}
+
+// AdminUploadRedirects validates redirects and replaces all redirects of the site with them
+func (g *Ghost) AdminUploadRedirects(ctx context.Context, redirects []Redirect) error {
+	if err := ValidateRedirects(redirects); err != nil {
+		return err
+	}
+	data, err := MarshalRedirects(redirects)
+	if err != nil {
+		return err
+	}
+	return g.AdminUploadRedirectsFile(ctx, "redirects.json", bytes.NewReader(data))
+}
+
+// AdminUploadRedirectsFile uploads a redirects.json or redirects.yaml file as is
+func (g *Ghost) AdminUploadRedirectsFile(ctx context.Context, name string, r io.Reader) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/redirects/upload/", g.url)
+
+	contentType := "application/json"
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+	case ".yaml", ".yml":
+		contentType = "text/yaml"
+	default:
+		return fmt.Errorf("redirects file %s must be .json or .yaml", name)
+	}
+	return g.uploadDiscard(ctx, url, []uploadFile{{field: "redirects", name: name, contentType: contentType, r: r}}, nil)
+}
@@ -0,0 +1,131 @@
+package ghost
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readRedirects(t *testing.T, name string) ([]byte, []Redirect) {
+	data, err := os.ReadFile(filepath.Join("testdata", "redirects", name))
+	if err != nil {
+		t.Fatalf("Cannot read %s: %s", name, err)
+	}
+	redirects, err := ParseRedirects(data)
+	if err != nil {
+		t.Fatalf("Cannot parse %s: %s", name, err)
+	}
+	return data, redirects
+}
+
+func TestParseRedirects(t *testing.T) {
+	_, redirects := readRedirects(t, "redirects.json")
+	want := []Redirect{
+		{From: "/old-about/", To: "/about/", Permanent: true},
+		{From: "/sale/", To: "/offers/"},
+		{From: "^/blog/(.*)$", To: "/$1", Permanent: true},
+	}
+	if !reflect.DeepEqual(redirects, want) {
+		t.Fatalf("Unexpected JSON redirects: %+v", redirects)
+	}
+
+	// YAML groups the redirects by status code
+	_, redirects = readRedirects(t, "redirects.yaml")
+	want = []Redirect{
+		{From: "/old-about/", To: "/about/", Permanent: true},
+		{From: "^/blog/(.*)$", To: "/$1", Permanent: true},
+		{From: "/sale/", To: "/offers/"},
+	}
+	if !reflect.DeepEqual(redirects, want) {
+		t.Fatalf("Unexpected YAML redirects: %+v", redirects)
+	}
+
+	for _, empty := range []string{"", " \n", "[]"} {
+		if redirects, err := ParseRedirects([]byte(empty)); err != nil || len(redirects) != 0 {
+			t.Fatalf("Unexpected result for %q: %+v, %v", empty, redirects, err)
+		}
+	}
+}
+
+func TestParseRedirectsErrors(t *testing.T) {
+	for _, data := range []string{
+		`[{"from": "/old/", "to": 1}]`,
+		"- /old/\n",
+		"307:\n  /old/: /new/\n",
+		"301: /new/\n",
+	} {
+		if redirects, err := ParseRedirects([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q, got %+v", data, redirects)
+		}
+	}
+}
+
+func TestRedirectsRoundTrip(t *testing.T) {
+	data, redirects := readRedirects(t, "redirects.json")
+	out, err := MarshalRedirects(redirects)
+	if err != nil {
+		t.Fatalf("Cannot marshal redirects: %s", err)
+	}
+	if !bytes.Equal(out, bytes.TrimSpace(data)) {
+		t.Fatalf("Unexpected redirects.json:\n%s", out)
+	}
+
+	data, redirects = readRedirects(t, "redirects.yaml")
+	out, err = MarshalRedirectsYAML(redirects)
+	if err != nil {
+		t.Fatalf("Cannot marshal redirects: %s", err)
+	}
+	if !bytes.Equal(out, data) {
+		t.Fatalf("Unexpected redirects.yaml:\n%s", out)
+	}
+	again, err := ParseRedirects(out)
+	if err != nil {
+		t.Fatalf("Cannot parse marshaled redirects: %s", err)
+	}
+	if !reflect.DeepEqual(redirects, again) {
+		t.Fatalf("Redirects changed after round trip:\n%+v\n%+v", redirects, again)
+	}
+
+	// a section without redirects is left out
+	out, err = MarshalRedirectsYAML([]Redirect{{From: "/sale/", To: "/offers/"}})
+	if err != nil {
+		t.Fatalf("Cannot marshal redirects: %s", err)
+	}
+	if string(out) != "302:\n  /sale/: /offers/\n" {
+		t.Fatalf("Unexpected redirects.yaml:\n%s", out)
+	}
+}
+
+func TestValidateRedirects(t *testing.T) {
+	_, valid := readRedirects(t, "redirects.json")
+	if err := ValidateRedirects(valid); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name      string
+		redirects []Redirect
+		want      string
+	}{
+		{"from", []Redirect{{To: "/new/"}}, `redirect 1: "from" is required`},
+		{"to", []Redirect{{From: "/old/"}}, `redirect 1: "to" is required`},
+		{"loop", []Redirect{{From: "/old/", To: "/old/"}}, "redirect 1: /old/ redirects to itself"},
+		{"duplicate", []Redirect{{From: "/old/", To: "/new/"}, {From: "/old/", To: "/newer/"}}, "redirect 2: /old/ is already redirected by redirect 1"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateRedirects(test.redirects)
+			var validationErr *FileValidationError
+			if !errors.As(err, &validationErr) || validationErr.File != "redirects.json" {
+				t.Fatalf("Expected a *FileValidationError, got %v", err)
+			}
+			if len(validationErr.Problems) != 1 || !strings.Contains(validationErr.Problems[0], test.want) {
+				t.Fatalf("Expected %q, got %q", test.want, validationErr.Problems)
+			}
+		})
+	}
+}
@@ -0,0 +1,433 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sklinkert/ghost/lexical"
+	"github.com/sklinkert/ghost/mobiledoc"
+)
+
+// Fetcher downloads external assets for RehostImages
+type Fetcher interface {
+	Fetch(ctx context.Context, url string) (body io.ReadCloser, contentType string, err error)
+}
+
+// FetcherFunc adapts a function to the Fetcher interface, e.g. to serve files from disk in tests
+type FetcherFunc func(ctx context.Context, url string) (io.ReadCloser, string, error)
+
+func (f FetcherFunc) Fetch(ctx context.Context, url string) (io.ReadCloser, string, error) {
+	return f(ctx, url)
+}
+
+// HTTPFetcher downloads assets with client, responses other than 200 are errors
+func HTTPFetcher(client *http.Client) Fetcher {
+	return FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, "", err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, "", fmt.Errorf("unexpected status code %d", resp.StatusCode)
+		}
+		return resp.Body, resp.Header.Get("Content-Type"), nil
+	})
+}
+
+// DefaultRehostMaxSize is the largest image RehostImages downloads unless RehostOptions.MaxSize is set
+const DefaultRehostMaxSize = 50 << 20
+
+type RehostOptions struct {
+	// Fetcher downloads the images, defaults to HTTPFetcher with the client's http.Client
+	Fetcher Fetcher
+	// MaxSize is the largest image in bytes, larger images are reported as failed. Defaults to DefaultRehostMaxSize.
+	MaxSize int64
+	// IsExternal selects the URLs to rehost, defaults to http(s) URLs on other hosts than the site
+	IsExternal func(rawURL string) bool
+	// DryRun only reports the external images, nothing is downloaded, uploaded or updated
+	DryRun    bool
+	SkipPosts bool
+	SkipPages bool
+}
+
+type RehostReport struct {
+	Items []RehostItem
+	// Assets maps every rehosted URL to its new URL on the site
+	Assets map[string]string
+	// Failed holds the URLs that couldn't be downloaded or uploaded, references to them are left unchanged
+	Failed map[string]error
+	// Uploads counts the uploaded images, identical images found under several URLs are uploaded once
+	Uploads int
+}
+
+type RehostItem struct {
+	Type string // "post" or "page"
+	ID   string
+	Slug string
+	// Rewritten maps the external URLs of the item to their new URLs, which are empty in a dry run
+	Rewritten map[string]string
+	Updated   bool
+	Err       error
+}
+
+// rehostTarget is the content of a post or page that may reference images
+type rehostTarget struct {
+	typ, id, slug, updatedAt            string
+	lexical, mobiledoc, html            string
+	featureImage, ogImage, twitterImage string
+}
+
+type rehoster struct {
+	g          *Ghost
+	opts       RehostOptions
+	report     *RehostReport
+	byChecksum map[string]string
+}
+
+// RehostImages downloads images referenced from other hosts by posts and pages (content, feature,
+// OG and Twitter images), uploads them to Ghost and updates the references. Failures of single
+// images or items are recorded in the report and don't stop the run.
+func (g *Ghost) RehostImages(ctx context.Context, opts RehostOptions) (RehostReport, error) {
+	report := RehostReport{Assets: map[string]string{}, Failed: map[string]error{}}
+	if opts.Fetcher == nil {
+		opts.Fetcher = HTTPFetcher(g.client)
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = DefaultRehostMaxSize
+	}
+	if opts.IsExternal == nil {
+		site, err := url.Parse(g.url)
+		if err != nil {
+			return report, err
+		}
+		opts.IsExternal = func(rawURL string) bool {
+			u, err := url.Parse(rawURL)
+			return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != site.Host
+		}
+	}
+	r := &rehoster{g: g, opts: opts, report: &report, byChecksum: map[string]string{}}
+
+	var targets []rehostTarget
+	if !opts.SkipPosts {
+		posts, err := g.AdminGetPosts()
+		if err != nil {
+			return report, err
+		}
+		for _, p := range posts.Posts {
+			targets = append(targets, rehostTarget{"post", p.ID, p.Slug, p.UpdatedAt, p.Lexical, p.MobileDoc, p.HTML, p.FeatureImage, p.OGImage, p.TwitterImage})
+		}
+	}
+	if !opts.SkipPages {
+		pages, err := g.AdminGetPages()
+		if err != nil {
+			return report, err
+		}
+		for _, p := range pages.Pages {
+			targets = append(targets, rehostTarget{"page", p.ID, p.Slug, p.UpdatedAt, p.Lexical, p.MobileDoc, p.HTML, p.FeatureImage, p.OGImage, p.TwitterImage})
+		}
+	}
+
+	for _, target := range targets {
+		if item, found := r.rehostTarget(ctx, target); found {
+			report.Items = append(report.Items, item)
+		}
+	}
+	return report, nil
+}
+
+func (r *rehoster) rehostTarget(ctx context.Context, target rehostTarget) (RehostItem, bool) {
+	item := RehostItem{Type: target.typ, ID: target.id, Slug: target.slug, Rewritten: map[string]string{}}
+
+	urls, err := target.imageURLs()
+	if err != nil {
+		item.Err = err
+		return item, true
+	}
+	var external int
+	for _, u := range urls {
+		if !r.opts.IsExternal(u) {
+			continue
+		}
+		external++
+		if r.opts.DryRun {
+			item.Rewritten[u] = ""
+			continue
+		}
+		if newURL, err := r.rehost(ctx, u); err == nil {
+			item.Rewritten[u] = newURL
+		}
+	}
+	if external == 0 {
+		return item, false
+	}
+	// nothing to update in a dry run or if all downloads failed, see RehostReport.Failed
+	if r.opts.DryRun || len(item.Rewritten) == 0 {
+		return item, true
+	}
+
+	if err := r.update(target, item.Rewritten); err != nil {
+		item.Err = err
+		return item, true
+	}
+	item.Updated = true
+	return item, true
+}
+
+// rehost uploads the image behind rawURL once, later calls return the known URL
+func (r *rehoster) rehost(ctx context.Context, rawURL string) (string, error) {
+	if newURL, found := r.report.Assets[rawURL]; found {
+		return newURL, nil
+	}
+	if err, found := r.report.Failed[rawURL]; found {
+		return "", err
+	}
+
+	newURL, err := r.download(ctx, rawURL)
+	if err != nil {
+		r.report.Failed[rawURL] = err
+		return "", err
+	}
+	r.report.Assets[rawURL] = newURL
+	return newURL, nil
+}
+
+func (r *rehoster) download(ctx context.Context, rawURL string) (string, error) {
+	body, contentType, err := r.opts.Fetcher.Fetch(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	content, err := io.ReadAll(io.LimitReader(body, r.opts.MaxSize+1))
+	_ = body.Close()
+	if err != nil {
+		return "", err
+	}
+	if int64(len(content)) > r.opts.MaxSize {
+		return "", fmt.Errorf("image is larger than %d bytes", r.opts.MaxSize)
+	}
+
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+	if newURL, found := r.byChecksum[checksum]; found {
+		return newURL, nil
+	}
+
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !strings.HasPrefix(mediaType, "image/") {
+		contentType = ""
+	}
+	name := "image"
+	if u, err := url.Parse(rawURL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		name = path.Base(u.Path)
+	}
+
+	image, err := r.g.AdminUploadImageReader(ctx, name, bytes.NewReader(content), ImageUploadOptions{ContentType: contentType, Ref: rawURL})
+	if err != nil {
+		return "", err
+	}
+	r.byChecksum[checksum] = image.URL
+	r.report.Uploads++
+	return image.URL, nil
+}
+
+// update writes the rewritten content back in the format it is stored in
+func (r *rehoster) update(target rehostTarget, rewritten map[string]string) error {
+	replace := URLReplacer(rewritten)
+	var source SourceType
+	content := Post{ID: target.id, UpdatedAt: target.updatedAt}
+	switch {
+	case target.lexical != "":
+		content.Lexical = replace.Replace(target.lexical)
+	case target.mobiledoc != "":
+		content.MobileDoc = replace.Replace(target.mobiledoc)
+	case target.html != "":
+		content.HTML = replace.Replace(target.html)
+		source = SourceHTML
+	}
+	content.FeatureImage = replaceField(target.featureImage, rewritten)
+	content.OGImage = replaceField(target.ogImage, rewritten)
+	content.TwitterImage = replaceField(target.twitterImage, rewritten)
+
+	if target.typ == "page" {
+		return r.g.AdminUpdatePage(Page{
+			ID:           content.ID,
+			UpdatedAt:    content.UpdatedAt,
+			Lexical:      content.Lexical,
+			MobileDoc:    content.MobileDoc,
+			HTML:         content.HTML,
+			FeatureImage: content.FeatureImage,
+			OGImage:      content.OGImage,
+			TwitterImage: content.TwitterImage,
+		}, source)
+	}
+	return r.g.AdminUpdatePost(content, source)
+}
+
+func replaceField(value string, rewritten map[string]string) string {
+	if newURL, found := rewritten[value]; found {
+		return newURL
+	}
+	return value
+}
+
+// URLReplacer replaces the keys of rewritten with their values as they appear in plain text,
+// HTML attributes and JSON strings, e.g. in Lexical or Mobiledoc content. Longer URLs go first
+// so a URL is never replaced inside a longer one.
+func URLReplacer(rewritten map[string]string) *strings.Replacer {
+	var olds []string
+	for old := range rewritten {
+		olds = append(olds, old)
+	}
+	sort.Slice(olds, func(i, j int) bool { return len(olds[i]) > len(olds[j]) })
+
+	var pairs []string
+	seen := map[string]bool{}
+	for _, old := range olds {
+		newURL := rewritten[old]
+		for _, escape := range []func(string) string{noEscape, html.EscapeString, jsonEscape} {
+			if escaped := escape(old); !seen[escaped] {
+				seen[escaped] = true
+				pairs = append(pairs, escaped, escape(newURL))
+			}
+		}
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+func noEscape(s string) string {
+	return s
+}
+
+func jsonEscape(s string) string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded[1 : len(encoded)-1])
+}
+
+var (
+	imgTagPattern = regexp.MustCompile(`(?i)<img\s[^>]*>`)
+	srcPattern    = regexp.MustCompile(`(?i)\s(src|srcset)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// htmlImageURLs returns the src and srcset URLs of all img tags
+func htmlImageURLs(content string) []string {
+	var urls []string
+	for _, tag := range imgTagPattern.FindAllString(content, -1) {
+		for _, match := range srcPattern.FindAllStringSubmatch(tag, -1) {
+			value := html.UnescapeString(match[2] + match[3])
+			if strings.EqualFold(match[1], "src") {
+				urls = append(urls, value)
+				continue
+			}
+			for _, candidate := range strings.Split(value, ",") {
+				if fields := strings.Fields(candidate); len(fields) > 0 {
+					urls = append(urls, fields[0])
+				}
+			}
+		}
+	}
+	return urls
+}
+
+// payloadImageURLs collects "src" values and images in "html" values of card payloads, e.g. of galleries
+func payloadImageURLs(value interface{}) []string {
+	var urls []string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			s, isString := field.(string)
+			switch {
+			case isString && key == "src":
+				urls = append(urls, s)
+			case isString && key == "html":
+				urls = append(urls, htmlImageURLs(s)...)
+			default:
+				urls = append(urls, payloadImageURLs(field)...)
+			}
+		}
+	case []interface{}:
+		for _, field := range v {
+			urls = append(urls, payloadImageURLs(field)...)
+		}
+	}
+	return urls
+}
+
+// ImageURLs returns the images referenced by the post's content, feature, OG and Twitter images
+func (p Post) ImageURLs() ([]string, error) {
+	return rehostTarget{lexical: p.Lexical, mobiledoc: p.MobileDoc, html: p.HTML,
+		featureImage: p.FeatureImage, ogImage: p.OGImage, twitterImage: p.TwitterImage}.imageURLs()
+}
+
+// ImageURLs returns the images referenced by the page's content, feature, OG and Twitter images
+func (p Page) ImageURLs() ([]string, error) {
+	return rehostTarget{lexical: p.Lexical, mobiledoc: p.MobileDoc, html: p.HTML,
+		featureImage: p.FeatureImage, ogImage: p.OGImage, twitterImage: p.TwitterImage}.imageURLs()
+}
+
+func (t rehostTarget) imageURLs() ([]string, error) {
+	var urls []string
+	switch {
+	case t.lexical != "":
+		doc, err := lexical.Parse(t.lexical)
+		if err != nil {
+			return nil, err
+		}
+		var walkErr error
+		doc.Walk(func(node lexical.Node) bool {
+			switch n := node.(type) {
+			case *lexical.ImageCard:
+				urls = append(urls, n.Src)
+			case *lexical.HTMLCard:
+				urls = append(urls, htmlImageURLs(n.HTML)...)
+			case *lexical.RawNode:
+				var payload interface{}
+				if err := json.Unmarshal(n.Raw, &payload); err != nil {
+					walkErr = err
+				}
+				urls = append(urls, payloadImageURLs(payload)...)
+			}
+			return true
+		})
+		if walkErr != nil {
+			return nil, walkErr
+		}
+	case t.mobiledoc != "":
+		doc, err := mobiledoc.Parse(t.mobiledoc)
+		if err != nil {
+			return nil, err
+		}
+		for _, card := range doc.Cards {
+			urls = append(urls, payloadImageURLs(card.Payload)...)
+		}
+		for _, section := range doc.Sections {
+			if section.Type == mobiledoc.SectionImage {
+				urls = append(urls, section.Src)
+			}
+		}
+	default:
+		urls = htmlImageURLs(t.html)
+	}
+
+	for _, field := range []string{t.featureImage, t.ogImage, t.twitterImage} {
+		if field != "" {
+			urls = append(urls, field)
+		}
+	}
+	return urls, nil
+}
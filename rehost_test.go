@@ -0,0 +1,187 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const (
+	rehostPNG     = "https://cdn.example.org/a.png"
+	rehostCopy    = "https://mirror.example.org/a-copy.png"
+	rehostJPG     = "https://cdn.example.org/b.jpg"
+	rehostMissing = "https://cdn.example.org/missing.png"
+	rehostHuge    = "https://cdn.example.org/huge.png"
+)
+
+var rehostImages = map[string][]byte{
+	rehostPNG:  []byte("png"),
+	rehostCopy: []byte("png"),
+	rehostJPG:  []byte("jpg"),
+	rehostHuge: bytes.Repeat([]byte("x"), 64),
+}
+
+func rehostFetcher(fetched map[string]int) Fetcher {
+	return FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, string, error) {
+		fetched[url]++
+		content, ok := rehostImages[url]
+		if !ok {
+			return nil, "", fmt.Errorf("unexpected status code 404")
+		}
+		return io.NopCloser(bytes.NewReader(content)), "image/png", nil
+	})
+}
+
+// newRehostServer serves a Lexical and a Mobiledoc post and an HTML page, updates are stored by ID
+func newRehostServer(t *testing.T, uploads *int, updates map[string]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/ghost/api/v3/admin/posts/":
+			_ = json.NewEncoder(w).Encode(Posts{Posts: []Post{{
+				ID:   "lexical",
+				Slug: "lexical",
+				Lexical: `{"root":{"children":[{"type":"image","version":1,"src":"` + rehostPNG + `"},` +
+					`{"type":"image","version":1,"src":"` + rehostCopy + `"},` +
+					`{"type":"image","version":1,"src":"` + rehostMissing + `"},` +
+					`{"type":"image","version":1,"src":"` + server.URL + `/content/images/local.png"}],"type":"root","version":1}}`,
+				FeatureImage: rehostHuge,
+			}, {
+				ID:        "mobiledoc",
+				Slug:      "mobiledoc",
+				MobileDoc: `{"version":"0.3.1","atoms":[],"cards":[["image",{"src":"` + rehostPNG + `"}]],"markups":[],"sections":[[10,0]]}`,
+			}}})
+		case r.Method == http.MethodGet && r.URL.Path == "/ghost/api/v3/admin/pages/":
+			_ = json.NewEncoder(w).Encode(Pages{Pages: []Page{{
+				ID:   "html",
+				Slug: "html",
+				HTML: `<p><img src="` + rehostJPG + `" srcset="` + rehostJPG + ` 1x, ` + rehostMissing + ` 2x"></p>`,
+			}}})
+		case r.Method == http.MethodPost && r.URL.Path == "/ghost/api/v3/admin/images/upload/":
+			_, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Cannot read upload: %s", err)
+			}
+			*uploads++
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(ImageResponse{Images: []Image{{URL: fmt.Sprintf("%s/content/images/2024/05/%d-%s", server.URL, *uploads, header.Filename)}}})
+		case r.Method == http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			id := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			updates[id] = string(body)
+			_, _ = w.Write(body)
+		default:
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestRehostImages(t *testing.T) {
+	var uploads int
+	updates := map[string]string{}
+	server := newRehostServer(t, &uploads, updates)
+	defer server.Close()
+
+	fetched := map[string]int{}
+	g := New(server.URL, "", "65f1c0de8a1b2c0001a1b2c3:0123456789abcdef0123456789abcdef")
+	report, err := g.RehostImages(context.Background(), RehostOptions{Fetcher: rehostFetcher(fetched), MaxSize: 16})
+	if err != nil {
+		t.Fatalf("Cannot rehost images: %s", err)
+	}
+
+	// the copy of a.png has the same checksum and reuses its upload
+	if uploads != 2 || report.Uploads != 2 {
+		t.Fatalf("Expected 2 uploads, got %d (report %d)", uploads, report.Uploads)
+	}
+	pngURL := server.URL + "/content/images/2024/05/1-a.png"
+	jpgURL := server.URL + "/content/images/2024/05/2-b.jpg"
+	if report.Assets[rehostPNG] != pngURL || report.Assets[rehostCopy] != pngURL || report.Assets[rehostJPG] != jpgURL {
+		t.Fatalf("Unexpected assets: %+v", report.Assets)
+	}
+	for url, count := range fetched {
+		if count != 1 {
+			t.Fatalf("Expected %s to be fetched once, got %d", url, count)
+		}
+	}
+
+	if len(report.Failed) != 2 || report.Failed[rehostMissing] == nil || report.Failed[rehostHuge] == nil {
+		t.Fatalf("Unexpected failures: %+v", report.Failed)
+	}
+	if !strings.Contains(report.Failed[rehostHuge].Error(), "larger than 16 bytes") {
+		t.Fatalf("Unexpected error for oversized image: %s", report.Failed[rehostHuge])
+	}
+
+	var lexicalPost Posts
+	if err := json.Unmarshal([]byte(updates["lexical"]), &lexicalPost); err != nil {
+		t.Fatalf("Cannot decode update: %s", err)
+	}
+	post := lexicalPost.Posts[0]
+	if strings.Contains(post.Lexical, rehostPNG) || strings.Contains(post.Lexical, rehostCopy) || strings.Count(post.Lexical, pngURL) != 2 {
+		t.Fatalf("Unexpected lexical: %s", post.Lexical)
+	}
+	// failed and local images are left unchanged
+	if !strings.Contains(post.Lexical, rehostMissing) || !strings.Contains(post.Lexical, server.URL+"/content/images/local.png") || post.FeatureImage != rehostHuge {
+		t.Fatalf("Unexpected post: %+v", post)
+	}
+
+	var mobiledocPost Posts
+	if err := json.Unmarshal([]byte(updates["mobiledoc"]), &mobiledocPost); err != nil {
+		t.Fatalf("Cannot decode update: %s", err)
+	}
+	if md := mobiledocPost.Posts[0].MobileDoc; !strings.Contains(md, `"src":"`+pngURL+`"`) || mobiledocPost.Posts[0].Lexical != "" {
+		t.Fatalf("Unexpected mobiledoc: %s", md)
+	}
+
+	var htmlPage Pages
+	if err := json.Unmarshal([]byte(updates["html"]), &htmlPage); err != nil {
+		t.Fatalf("Cannot decode update: %s", err)
+	}
+	if html := htmlPage.Pages[0].HTML; html != `<p><img src="`+jpgURL+`" srcset="`+jpgURL+` 1x, `+rehostMissing+` 2x"></p>` {
+		t.Fatalf("Unexpected html: %s", html)
+	}
+
+	if len(report.Items) != 3 {
+		t.Fatalf("Expected 3 items, got %+v", report.Items)
+	}
+	for _, item := range report.Items {
+		if !item.Updated || item.Err != nil {
+			t.Fatalf("Unexpected item: %+v", item)
+		}
+	}
+}
+
+func TestRehostImagesSkipsFailedDownloads(t *testing.T) {
+	var uploads int
+	updates := map[string]string{}
+	server := newRehostServer(t, &uploads, updates)
+	defer server.Close()
+
+	failing := FetcherFunc(func(ctx context.Context, url string) (io.ReadCloser, string, error) {
+		return nil, "", fmt.Errorf("unexpected status code 500")
+	})
+	g := New(server.URL, "", "65f1c0de8a1b2c0001a1b2c3:0123456789abcdef0123456789abcdef")
+	report, err := g.RehostImages(context.Background(), RehostOptions{Fetcher: failing})
+	if err != nil {
+		t.Fatalf("Cannot rehost images: %s", err)
+	}
+
+	if uploads != 0 || len(updates) != 0 {
+		t.Fatalf("Expected no uploads or updates, got %d uploads and %+v", uploads, updates)
+	}
+	if len(report.Failed) != 5 || len(report.Assets) != 0 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	for _, item := range report.Items {
+		if item.Updated || len(item.Rewritten) != 0 {
+			t.Fatalf("Unexpected item: %+v", item)
+		}
+	}
+}
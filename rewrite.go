@@ -0,0 +1,63 @@
+package ghost
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// siteURLChars are the characters a URL can span in text, HTML attributes and JSON strings
+const siteURLChars = `[^\s"'<>\\)]*`
+
+// SiteURLRewriter returns a function that points URLs of the site at oldSite to newSite, e.g. to move
+// content between sites. URLs in rewritten are replaced by their value, other URLs keep their path on
+// newSite. Uploads below content/ (images, media, files) that are not in rewritten keep pointing
+// to oldSite because they don't exist on newSite.
+func SiteURLRewriter(oldSite, newSite string, rewritten map[string]string) func(string) string {
+	oldSite = strings.TrimSuffix(oldSite, "/")
+	newSite = strings.TrimSuffix(newSite, "/")
+	if oldSite == "" {
+		return URLReplacer(rewritten).Replace
+	}
+	pattern := regexp.MustCompile(regexp.QuoteMeta(oldSite) + `(?:[/?#]` + siteURLChars + `)?`)
+
+	rewrite := func(match string) string {
+		if newURL, ok := rewritten[match]; ok {
+			return newURL
+		}
+		if unescaped := html.UnescapeString(match); unescaped != match {
+			if newURL, ok := rewritten[unescaped]; ok {
+				return html.EscapeString(newURL)
+			}
+		}
+		rest := strings.TrimPrefix(match, oldSite)
+		if strings.HasPrefix(rest, "/content/") || oldSite == newSite {
+			return match
+		}
+		return newSite + rest
+	}
+
+	return func(content string) string {
+		var b strings.Builder
+		last := 0
+		for _, loc := range pattern.FindAllStringIndex(content, -1) {
+			start, end := loc[0], loc[1]
+			// https://example.com must not match https://example.community
+			if end < len(content) && isHostChar(content[end]) {
+				continue
+			}
+			b.WriteString(content[last:start])
+			b.WriteString(rewrite(content[start:end]))
+			last = end
+		}
+		if last == 0 {
+			return content
+		}
+		b.WriteString(content[last:])
+		return b.String()
+	}
+}
+
+func isHostChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '.' || c == ':'
+}
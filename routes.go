@@ -0,0 +1,307 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Routes is Ghost's routes.yaml. Routes and collections keep the order of the file because
+// a post belongs to the first collection whose filter matches.
+type Routes struct {
+	Routes      []Route
+	Collections []Collection
+	Taxonomies  Taxonomies
+}
+
+// Route is a custom route, e.g. a static page rendered with its own template or a channel
+type Route struct {
+	Path        string      `yaml:"-"`
+	Template    string      `yaml:"template,omitempty"`
+	Controller  string      `yaml:"controller,omitempty"` // "channel" for paginated post lists
+	Filter      string      `yaml:"filter,omitempty"`
+	Order       string      `yaml:"order,omitempty"`
+	Limit       int         `yaml:"limit,omitempty"`
+	RSS         *bool       `yaml:"rss,omitempty"`
+	ContentType string      `yaml:"content_type,omitempty"`
+	Data        interface{} `yaml:"data,omitempty"` // e.g. "page.about" or a mapping of named queries
+}
+
+// Collection is a set of posts with its own permalinks
+type Collection struct {
+	Path      string      `yaml:"-"`
+	Permalink string      `yaml:"permalink"`
+	Template  Templates   `yaml:"template,omitempty"`
+	Filter    string      `yaml:"filter,omitempty"`
+	Order     string      `yaml:"order,omitempty"`
+	Limit     int         `yaml:"limit,omitempty"`
+	RSS       *bool       `yaml:"rss,omitempty"`
+	Data      interface{} `yaml:"data,omitempty"`
+}
+
+// Templates are tried in order, routes.yaml allows a single name or a list
+type Templates []string
+
+type Taxonomies struct {
+	Tag    string `yaml:"tag,omitempty"`    // e.g. /tag/{slug}/
+	Author string `yaml:"author,omitempty"` // e.g. /author/{slug}/
+}
+
+func (t *Templates) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = Templates{node.Value}
+		return nil
+	}
+	var templates []string
+	if err := node.Decode(&templates); err != nil {
+		return err
+	}
+	*t = templates
+	return nil
+}
+
+func (t Templates) MarshalYAML() (interface{}, error) {
+	if len(t) == 1 {
+		return t[0], nil
+	}
+	return []string(t), nil
+}
+
+func (r *Routes) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("routes.yaml: expected routes, collections and taxonomies")
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i].Value, node.Content[i+1]
+		if value.Tag == "!!null" {
+			continue
+		}
+		if key != "taxonomies" && value.Kind != yaml.MappingNode {
+			return fmt.Errorf("routes.yaml: %s must be a mapping of paths", key)
+		}
+
+		switch key {
+		case "routes":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				route := Route{Path: value.Content[j].Value}
+				if entry := value.Content[j+1]; entry.Kind == yaml.ScalarNode {
+					route.Template = entry.Value
+				} else if err := entry.Decode(&route); err != nil {
+					return fmt.Errorf("routes.yaml: route %s: %w", route.Path, err)
+				}
+				r.Routes = append(r.Routes, route)
+			}
+		case "collections":
+			for j := 0; j+1 < len(value.Content); j += 2 {
+				collection := Collection{Path: value.Content[j].Value}
+				if err := value.Content[j+1].Decode(&collection); err != nil {
+					return fmt.Errorf("routes.yaml: collection %s: %w", collection.Path, err)
+				}
+				r.Collections = append(r.Collections, collection)
+			}
+		case "taxonomies":
+			if err := value.Decode(&r.Taxonomies); err != nil {
+				return fmt.Errorf("routes.yaml: taxonomies: %w", err)
+			}
+		default:
+			return fmt.Errorf("routes.yaml: unknown section %q", key)
+		}
+	}
+	return nil
+}
+
+func (r Routes) MarshalYAML() (interface{}, error) {
+	section := func(name string, entries []*yaml.Node) []*yaml.Node {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		if len(entries) > 0 {
+			value = &yaml.Node{Kind: yaml.MappingNode, Content: entries}
+		}
+		return []*yaml.Node{{Kind: yaml.ScalarNode, Value: name}, value}
+	}
+
+	var routes []*yaml.Node
+	for _, route := range r.Routes {
+		var value yaml.Node
+		path := route.Path
+		route.Path = ""
+		if (route == Route{Template: route.Template}) {
+			value = yaml.Node{Kind: yaml.ScalarNode, Value: route.Template}
+		} else if err := value.Encode(route); err != nil {
+			return nil, err
+		}
+		routes = append(routes, &yaml.Node{Kind: yaml.ScalarNode, Value: path}, &value)
+	}
+
+	var collections []*yaml.Node
+	for _, collection := range r.Collections {
+		var value yaml.Node
+		if err := value.Encode(collection); err != nil {
+			return nil, err
+		}
+		collections = append(collections, &yaml.Node{Kind: yaml.ScalarNode, Value: collection.Path}, &value)
+	}
+
+	var taxonomies yaml.Node
+	if err := taxonomies.Encode(r.Taxonomies); err != nil {
+		return nil, err
+	}
+	if len(taxonomies.Content) == 0 {
+		taxonomies = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	root.Content = append(root.Content, section("routes", routes)...)
+	root.Content = append(root.Content, section("collections", collections)...)
+	root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "taxonomies"}, &taxonomies)
+	return root, nil
+}
+
+// ParseRoutes parses routes.yaml
+func ParseRoutes(data []byte) (Routes, error) {
+	var routes Routes
+	if err := yaml.Unmarshal(data, &routes); err != nil {
+		return Routes{}, err
+	}
+	return routes, nil
+}
+
+// MarshalRoutes encodes routes as routes.yaml
+func MarshalRoutes(routes Routes) ([]byte, error) {
+	return marshalGhostYAML(routes)
+}
+
+// marshalGhostYAML encodes v with the two space indent of Ghost's default files
+func marshalGhostYAML(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+var (
+	permalinkNotation  = regexp.MustCompile(`\{[^}]*\}`)
+	permalinkNotations = map[string]bool{
+		"{id}": true, "{slug}": true, "{year}": true, "{month}": true, "{day}": true,
+		"{author}": true, "{primary_tag}": true, "{primary_author}": true,
+	}
+	routeData = regexp.MustCompile(`^(post|page|tag|author)\.[^.\s]+$`)
+)
+
+// ValidateRoutes returns a *FileValidationError with the problems Ghost would reject routes for
+func ValidateRoutes(routes Routes) error {
+	var problems []string
+	problem := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+	checkPath := func(kind, path string) {
+		if !strings.HasPrefix(path, "/") || !strings.HasSuffix(path, "/") {
+			problem("%s %q needs a leading and a trailing slash", kind, path)
+		}
+	}
+
+	paths := map[string]string{}
+	usePath := func(kind, path string) {
+		if other, ok := paths[path]; ok {
+			problem("%s %s is already used by a %s", kind, path, other)
+		}
+		paths[path] = kind
+	}
+
+	for _, route := range routes.Routes {
+		checkPath("route", route.Path)
+		usePath("route", route.Path)
+		if strings.Contains(route.Path, "{") {
+			problem("route %s can't contain permalink notation", route.Path)
+		}
+		if route.Controller != "" && route.Controller != "channel" {
+			problem("route %s: unknown controller %q", route.Path, route.Controller)
+		}
+		if route.Controller == "" && (route.Filter != "" || route.Limit != 0 || route.Order != "") {
+			problem("route %s: filter, limit and order need controller: channel", route.Path)
+		}
+		if data, ok := route.Data.(string); ok && !routeData.MatchString(data) {
+			problem("route %s: data %q must look like post.slug, page.slug, tag.slug or author.slug", route.Path, data)
+		}
+	}
+
+	for _, collection := range routes.Collections {
+		checkPath("collection", collection.Path)
+		usePath("collection", collection.Path)
+		if collection.Permalink == "" {
+			problem("collection %s needs a permalink", collection.Path)
+			continue
+		}
+		checkPath("permalink", collection.Permalink)
+		notations := permalinkNotation.FindAllString(collection.Permalink, -1)
+		for _, notation := range notations {
+			if !permalinkNotations[notation] {
+				problem("collection %s: unknown permalink notation %s", collection.Path, notation)
+			}
+		}
+		if !strings.Contains(collection.Permalink, "{slug}") && !strings.Contains(collection.Permalink, "{id}") {
+			problem("collection %s: permalink needs {slug} or {id}", collection.Path)
+		}
+		if data, ok := collection.Data.(string); ok && !routeData.MatchString(data) {
+			problem("collection %s: data %q must look like post.slug, page.slug, tag.slug or author.slug", collection.Path, data)
+		}
+	}
+
+	for kind, permalink := range map[string]string{"tag": routes.Taxonomies.Tag, "author": routes.Taxonomies.Author} {
+		if permalink == "" {
+			continue
+		}
+		checkPath(kind+" taxonomy", permalink)
+		if !strings.Contains(permalink, "{slug}") {
+			problem("%s taxonomy %s needs {slug}", kind, permalink)
+		}
+	}
+
+	if len(problems) > 0 {
+		return &FileValidationError{File: "routes.yaml", Problems: problems}
+	}
+	return nil
+}
+
+// AdminDownloadRoutes writes routes.yaml to w
+func (g *Ghost) AdminDownloadRoutes(ctx context.Context, w io.Writer) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/settings/routes/yaml/", g.url)
+	return g.download(ctx, url, w)
+}
+
+// AdminGetRoutes downloads and parses routes.yaml
+func (g *Ghost) AdminGetRoutes(ctx context.Context) (Routes, error) {
+	var buf bytes.Buffer
+	if err := g.AdminDownloadRoutes(ctx, &buf); err != nil {
+		return Routes{}, err
+	}
+	return ParseRoutes(buf.Bytes())
+}
+
+// AdminUploadRoutes validates routes and replaces the site's routes.yaml
+func (g *Ghost) AdminUploadRoutes(ctx context.Context, routes Routes) error {
+	if err := ValidateRoutes(routes); err != nil {
+		return err
+	}
+	data, err := MarshalRoutes(routes)
+	if err != nil {
+		return err
+	}
+	return g.AdminUploadRoutesFile(ctx, bytes.NewReader(data))
+}
+
+// AdminUploadRoutesFile uploads a routes.yaml file as is
+func (g *Ghost) AdminUploadRoutesFile(ctx context.Context, r io.Reader) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/settings/routes/yaml/", g.url)
+	return g.uploadDiscard(ctx, url, []uploadFile{{field: "routes", name: "routes.yaml", contentType: "text/yaml", r: r}}, nil)
+}
@@ -0,0 +1,138 @@
+package ghost
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func readRoutes(t *testing.T, name string) ([]byte, Routes) {
+	data, err := os.ReadFile(filepath.Join("testdata", "routes", name))
+	if err != nil {
+		t.Fatalf("Cannot read %s: %s", name, err)
+	}
+	routes, err := ParseRoutes(data)
+	if err != nil {
+		t.Fatalf("Cannot parse %s: %s", name, err)
+	}
+	return data, routes
+}
+
+func TestParseRoutes(t *testing.T) {
+	_, routes := readRoutes(t, "default.yaml")
+	want := Routes{
+		Collections: []Collection{{Path: "/", Permalink: "/{slug}/", Template: Templates{"index"}}},
+		Taxonomies:  Taxonomies{Tag: "/tag/{slug}/", Author: "/author/{slug}/"},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Fatalf("Unexpected default routes: %+v", routes)
+	}
+
+	_, routes = readRoutes(t, "custom.yaml")
+	rss := false
+	want = Routes{
+		Routes: []Route{
+			{Path: "/about/", Template: "about"},
+			{Path: "/podcast/", Template: "podcast", Controller: "channel", Filter: "tag:podcast", RSS: &rss},
+			{Path: "/features/", Template: "features", Data: "page.features"},
+		},
+		Collections: []Collection{
+			{Path: "/blog/", Permalink: "/blog/{year}/{slug}/", Template: Templates{"blog", "index"}, Filter: "tag:blog"},
+			{Path: "/", Permalink: "/{slug}/", Template: Templates{"index"}},
+		},
+		Taxonomies: Taxonomies{Tag: "/topic/{slug}/", Author: "/writer/{slug}/"},
+	}
+	if !reflect.DeepEqual(routes, want) {
+		t.Fatalf("Unexpected custom routes: %+v", routes)
+	}
+}
+
+func TestRoutesRoundTrip(t *testing.T) {
+	for _, name := range []string{"default.yaml", "custom.yaml"} {
+		t.Run(name, func(t *testing.T) {
+			data, routes := readRoutes(t, name)
+			out, err := MarshalRoutes(routes)
+			if err != nil {
+				t.Fatalf("Cannot marshal routes: %s", err)
+			}
+			// the file only differs by the blank lines between the sections
+			if want := strings.ReplaceAll(string(data), "\n\n", "\n"); string(out) != want {
+				t.Fatalf("Unexpected routes.yaml:\n%s\nwant:\n%s", out, want)
+			}
+
+			again, err := ParseRoutes(out)
+			if err != nil {
+				t.Fatalf("Cannot parse marshaled routes: %s", err)
+			}
+			if !reflect.DeepEqual(routes, again) {
+				t.Fatalf("Routes changed after round trip:\n%+v\n%+v", routes, again)
+			}
+		})
+	}
+}
+
+func TestMarshalRoutesEmpty(t *testing.T) {
+	out, err := MarshalRoutes(Routes{})
+	if err != nil {
+		t.Fatalf("Cannot marshal routes: %s", err)
+	}
+	if string(out) != "routes:\ncollections:\ntaxonomies:\n" {
+		t.Fatalf("Unexpected routes.yaml:\n%s", out)
+	}
+}
+
+func TestParseRoutesErrors(t *testing.T) {
+	for _, data := range []string{
+		"- /about/\n",
+		"routes:\n  - /about/\n",
+		"collections: /\n",
+		"redirects:\n  /old/: /new/\n",
+		"collections:\n  /:\n    permalink: [/{slug}/]\n",
+	} {
+		if routes, err := ParseRoutes([]byte(data)); err == nil {
+			t.Errorf("Expected an error for %q, got %+v", data, routes)
+		}
+	}
+}
+
+func TestValidateRoutes(t *testing.T) {
+	_, valid := readRoutes(t, "custom.yaml")
+	if err := ValidateRoutes(valid); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	tests := []struct {
+		name   string
+		routes Routes
+		want   string
+	}{
+		{"slashes", Routes{Routes: []Route{{Path: "about", Template: "about"}}}, `route "about" needs a leading and a trailing slash`},
+		{"notation", Routes{Routes: []Route{{Path: "/{slug}/", Template: "about"}}}, "can't contain permalink notation"},
+		{"controller", Routes{Routes: []Route{{Path: "/news/", Controller: "list"}}}, `unknown controller "list"`},
+		{"channel", Routes{Routes: []Route{{Path: "/news/", Filter: "tag:news"}}}, "need controller: channel"},
+		{"route data", Routes{Routes: []Route{{Path: "/about/", Data: "about"}}}, `data "about" must look like`},
+		{"duplicate", Routes{
+			Routes:      []Route{{Path: "/blog/", Template: "blog"}},
+			Collections: []Collection{{Path: "/blog/", Permalink: "/blog/{slug}/"}},
+		}, "collection /blog/ is already used by a route"},
+		{"permalink", Routes{Collections: []Collection{{Path: "/"}}}, "collection / needs a permalink"},
+		{"unknown notation", Routes{Collections: []Collection{{Path: "/", Permalink: "/{category}/{slug}/"}}}, "unknown permalink notation {category}"},
+		{"slug", Routes{Collections: []Collection{{Path: "/", Permalink: "/{year}/"}}}, "permalink needs {slug} or {id}"},
+		{"taxonomy", Routes{Taxonomies: Taxonomies{Tag: "/tag/{id}/"}}, "tag taxonomy /tag/{id}/ needs {slug}"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateRoutes(test.routes)
+			var validationErr *FileValidationError
+			if !errors.As(err, &validationErr) || validationErr.File != "routes.yaml" {
+				t.Fatalf("Expected a *FileValidationError, got %v", err)
+			}
+			if !strings.Contains(err.Error(), test.want) {
+				t.Fatalf("Expected %q in %s", test.want, err)
+			}
+		})
+	}
+}
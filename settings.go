@@ -0,0 +1,304 @@
+package ghost
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Settings are the public site settings of the Content API
+type Settings struct {
+	Title               string           `json:"title"`
+	Description         string           `json:"description"`
+	Logo                string           `json:"logo"`
+	Icon                string           `json:"icon"`
+	AccentColor         string           `json:"accent_color"`
+	CoverImage          string           `json:"cover_image"`
+	Facebook            string           `json:"facebook"`
+	Twitter             string           `json:"twitter"`
+	Locale              string           `json:"locale"` // Ghost 5
+	Lang                string           `json:"lang"`   // Ghost 4 and older
+	Timezone            string           `json:"timezone"`
+	CodeInjectionHead   string           `json:"codeinjection_head"`
+	CodeInjectionFoot   string           `json:"codeinjection_foot"`
+	Navigation          []NavigationItem `json:"navigation"`
+	SecondaryNavigation []NavigationItem `json:"secondary_navigation"`
+	MetaTitle           string           `json:"meta_title"`
+	MetaDescription     string           `json:"meta_description"`
+	OGImage             string           `json:"og_image"`
+	OGTitle             string           `json:"og_title"`
+	OGDescription       string           `json:"og_description"`
+	TwitterImage        string           `json:"twitter_image"`
+	TwitterTitle        string           `json:"twitter_title"`
+	TwitterDescription  string           `json:"twitter_description"`
+	URL                 string           `json:"url"`
+}
+
+type SettingsResponse struct {
+	Settings Settings `json:"settings"`
+}
+
+type NavigationItem struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// Site is the basic site information of the Admin API
+type Site struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Logo        string `json:"logo"`
+	Icon        string `json:"icon"`
+	AccentColor string `json:"accent_color"`
+	Locale      string `json:"locale"`
+	URL         string `json:"url"`
+	Version     string `json:"version"` // e.g. "5.82"
+}
+
+type SiteResponse struct {
+	Site Site `json:"site"`
+}
+
+// Setting is a single key/value setting as the Admin API sends it
+type Setting struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+type settingsList struct {
+	Settings []Setting `json:"settings"`
+}
+
+// AdminSettings are the settings of the Admin API. Ghost sends them as a key/value list,
+// the common ones are mapped to typed fields, the others are kept in Other by key.
+type AdminSettings struct {
+	Title                    string           `json:"title"`
+	Description              string           `json:"description"`
+	Logo                     string           `json:"logo"`
+	Icon                     string           `json:"icon"`
+	AccentColor              string           `json:"accent_color"`
+	CoverImage               string           `json:"cover_image"`
+	Facebook                 string           `json:"facebook"`
+	Twitter                  string           `json:"twitter"`
+	Locale                   string           `json:"locale"`
+	Timezone                 string           `json:"timezone"`
+	CodeInjectionHead        string           `json:"codeinjection_head"`
+	CodeInjectionFoot        string           `json:"codeinjection_foot"`
+	Navigation               []NavigationItem `json:"navigation"`
+	SecondaryNavigation      []NavigationItem `json:"secondary_navigation"`
+	MetaTitle                string           `json:"meta_title"`
+	MetaDescription          string           `json:"meta_description"`
+	OGImage                  string           `json:"og_image"`
+	OGTitle                  string           `json:"og_title"`
+	OGDescription            string           `json:"og_description"`
+	TwitterImage             string           `json:"twitter_image"`
+	TwitterTitle             string           `json:"twitter_title"`
+	TwitterDescription       string           `json:"twitter_description"`
+	IsPrivate                bool             `json:"is_private"`
+	Password                 string           `json:"password"`
+	MembersSignupAccess      string           `json:"members_signup_access"` // "all", "invite" or "none"
+	DefaultContentVisibility string           `json:"default_content_visibility"`
+	CommentsEnabled          string           `json:"comments_enabled"` // "off", "all" or "paid"
+	// Other holds the settings without a typed field, e.g. "lang" on Ghost 4
+	Other map[string]json.RawMessage `json:"-"`
+}
+
+// navigationSettings are stored as JSON encoded strings by the Admin API
+var navigationSettings = map[string]bool{"navigation": true, "secondary_navigation": true}
+
+// readOnlySettings are set by Ghost itself and never copied between sites, see isReadOnlySetting
+var readOnlySettings = map[string]bool{
+	"db_hash":               true,
+	"routes_hash":           true,
+	"next_update_check":     true,
+	"notifications":         true,
+	"version_notifications": true,
+	"members_public_key":    true,
+	"members_private_key":   true,
+	"ghost_public_key":      true,
+	"ghost_private_key":     true,
+}
+
+// isReadOnlySetting reports whether the setting is generated by Ghost or a secret of the site:
+// hashes, keys, UUIDs and the Stripe connection
+func isReadOnlySetting(key string) bool {
+	return readOnlySettings[key] ||
+		strings.HasPrefix(key, "members_stripe_") ||
+		strings.HasPrefix(key, "stripe_") ||
+		strings.HasSuffix(key, "_uuid") ||
+		strings.HasSuffix(key, "_secret")
+}
+
+// GetSettings returns the public settings (Content API)
+func (g *Ghost) GetSettings() (Settings, error) {
+	const ghostSettingsURLSuffix = "%s/ghost/api/v2/content/settings/?key=%s"
+	var response SettingsResponse
+	var url = fmt.Sprintf(ghostSettingsURLSuffix, g.url, g.contentAPIToken)
+
+	if err := g.getJson(url, &response); err != nil {
+		return response.Settings, err
+	}
+	return response.Settings, nil
+}
+
+// AdminGetSite returns the site information including the Ghost version
+func (g *Ghost) AdminGetSite() (Site, error) {
+	var response SiteResponse
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/site/", g.url)
+
+	if err := g.getJson(url, &response); err != nil {
+		return response.Site, err
+	}
+	return response.Site, nil
+}
+
+// AdminGetSettings returns all settings
+func (g *Ghost) AdminGetSettings() (AdminSettings, error) {
+	var response settingsList
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/settings/", g.url)
+
+	if err := g.getJson(url, &response); err != nil {
+		return AdminSettings{}, err
+	}
+	return newAdminSettings(response.Settings)
+}
+
+// AdminUpdateSettings saves the settings named by keys, e.g. "title" or a key of Other,
+// and returns all settings after the update.
+func (g *Ghost) AdminUpdateSettings(settings AdminSettings, keys ...string) (AdminSettings, error) {
+	if len(keys) == 0 {
+		return AdminSettings{}, fmt.Errorf("no settings to update")
+	}
+
+	values, err := settings.values()
+	if err != nil {
+		return AdminSettings{}, err
+	}
+	var update settingsList
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			return AdminSettings{}, fmt.Errorf("unknown setting %q", key)
+		}
+		update.Settings = append(update.Settings, Setting{Key: key, Value: value})
+	}
+
+	updateData, err := json.Marshal(&update)
+	if err != nil {
+		return AdminSettings{}, err
+	}
+
+	var response settingsList
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/settings/", g.url)
+	if err := g.putJson(url, updateData, &response); err != nil {
+		return AdminSettings{}, err
+	}
+	return newAdminSettings(response.Settings)
+}
+
+// Changed returns the sorted keys whose values differ from other, e.g. to update a site
+// with the settings of another: AdminUpdateSettings(desired, desired.Changed(current)...)
+// Read-only and secret settings like db_hash, site_uuid or members_stripe_* are never returned.
+func (s AdminSettings) Changed(other AdminSettings) ([]string, error) {
+	values, err := s.values()
+	if err != nil {
+		return nil, err
+	}
+	otherValues, err := other.values()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for key, value := range values {
+		if isReadOnlySetting(key) {
+			continue
+		}
+		if otherValue, ok := otherValues[key]; !ok || !jsonEqual(value, otherValue) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func newAdminSettings(list []Setting) (AdminSettings, error) {
+	known, err := AdminSettings{}.typedValues()
+	if err != nil {
+		return AdminSettings{}, err
+	}
+
+	typed := map[string]json.RawMessage{}
+	settings := AdminSettings{Other: map[string]json.RawMessage{}}
+	for _, setting := range list {
+		value := setting.Value
+		if _, ok := known[setting.Key]; !ok {
+			settings.Other[setting.Key] = value
+			continue
+		}
+		if navigationSettings[setting.Key] {
+			var encoded string
+			if json.Unmarshal(value, &encoded) == nil {
+				value = json.RawMessage(encoded)
+			}
+		}
+		typed[setting.Key] = value
+	}
+
+	object, err := json.Marshal(typed)
+	if err != nil {
+		return AdminSettings{}, err
+	}
+	if err := json.Unmarshal(object, &settings); err != nil {
+		return AdminSettings{}, err
+	}
+	return settings, nil
+}
+
+// typedValues returns the JSON values of the typed fields by key
+func (s AdminSettings) typedValues() (map[string]json.RawMessage, error) {
+	object, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(object, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// values returns all settings by key as the Admin API expects them
+func (s AdminSettings) values() (map[string]json.RawMessage, error) {
+	values, err := s.typedValues()
+	if err != nil {
+		return nil, err
+	}
+	for key := range navigationSettings {
+		navigation := string(values[key])
+		if navigation == "null" {
+			navigation = "[]"
+		}
+		encoded, err := json.Marshal(navigation)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = encoded
+	}
+	for key, value := range s.Other {
+		values[key] = value
+	}
+	return values, nil
+}
+
+// jsonEqual compares JSON values ignoring formatting and key order
+func jsonEqual(a, b []byte) bool {
+	var valueA, valueB interface{}
+	if json.Unmarshal(a, &valueA) != nil || json.Unmarshal(b, &valueB) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(valueA, valueB)
+}
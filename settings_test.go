@@ -0,0 +1,85 @@
+package ghost
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestAdminSettingsChanged(t *testing.T) {
+	current, err := newAdminSettings([]Setting{
+		{Key: "title", Value: json.RawMessage(`"Old title"`)},
+		{Key: "navigation", Value: json.RawMessage(`"[{\"label\":\"Home\",\"url\":\"/\"}]"`)},
+		{Key: "lang", Value: json.RawMessage(`"en"`)},
+		{Key: "db_hash", Value: json.RawMessage(`"a1b2"`)},
+		{Key: "site_uuid", Value: json.RawMessage(`"6b4c8c3e-0000-4000-8000-000000000001"`)},
+		{Key: "members_stripe_webhook_secret", Value: json.RawMessage(`"whsec_current"`)},
+		{Key: "stripe_secret_key", Value: json.RawMessage(`"sk_current"`)},
+		{Key: "members_email_auth_secret", Value: json.RawMessage(`"current"`)},
+	})
+	if err != nil {
+		t.Fatalf("Cannot read settings: %s", err)
+	}
+
+	desired := current
+	desired.Title = "New title"
+	desired.Other = map[string]json.RawMessage{
+		"lang":                          json.RawMessage(`"de"`),
+		"db_hash":                       json.RawMessage(`"c3d4"`),
+		"site_uuid":                     json.RawMessage(`"6b4c8c3e-0000-4000-8000-000000000002"`),
+		"members_stripe_webhook_secret": json.RawMessage(`"whsec_other"`),
+		"stripe_secret_key":             json.RawMessage(`"sk_other"`),
+		"members_email_auth_secret":     json.RawMessage(`"other"`),
+		"routes_hash":                   json.RawMessage(`"e5f6"`),
+	}
+
+	keys, err := desired.Changed(current)
+	if err != nil {
+		t.Fatalf("Cannot compare settings: %s", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"lang", "title"}) {
+		t.Fatalf("Unexpected changed keys: %v", keys)
+	}
+
+	keys, err = current.Changed(current)
+	if err != nil {
+		t.Fatalf("Cannot compare settings: %s", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected no changes, got %v", keys)
+	}
+}
+
+func TestAdminSettingsChangedIgnoresFormatting(t *testing.T) {
+	current, err := newAdminSettings([]Setting{
+		{Key: "navigation", Value: json.RawMessage(`"[{\"label\":\"Home\",\"url\":\"/\"}]"`)},
+		{Key: "labs", Value: json.RawMessage(`{"members":true,"newsletters":false}`)},
+	})
+	if err != nil {
+		t.Fatalf("Cannot read settings: %s", err)
+	}
+	desired, err := newAdminSettings([]Setting{
+		{Key: "navigation", Value: json.RawMessage(`"[ { \"url\": \"/\", \"label\": \"Home\" } ]"`)},
+		{Key: "labs", Value: json.RawMessage(`{ "newsletters": false, "members": true }`)},
+	})
+	if err != nil {
+		t.Fatalf("Cannot read settings: %s", err)
+	}
+
+	keys, err := desired.Changed(current)
+	if err != nil {
+		t.Fatalf("Cannot compare settings: %s", err)
+	}
+	if len(keys) != 0 {
+		t.Fatalf("Expected no changes, got %v", keys)
+	}
+
+	desired.Other["labs"] = json.RawMessage(`{"members":true,"newsletters":true}`)
+	keys, err = desired.Changed(current)
+	if err != nil {
+		t.Fatalf("Cannot compare settings: %s", err)
+	}
+	if !reflect.DeepEqual(keys, []string{"labs"}) {
+		t.Fatalf("Unexpected changed keys: %v", keys)
+	}
+}
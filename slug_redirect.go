@@ -0,0 +1,156 @@
+package ghost
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// SetSlugRedirects turns automatic redirects on or off. When on, AdminUpdatePost and AdminUpdatePage
+// compare the URL of a published post or page before and after the update and add a 301 redirect
+// from the old path to the new one. Off by default.
+func (g *Ghost) SetSlugRedirects(enabled bool) {
+	g.slugRedirects = enabled
+}
+
+// AddPathRedirect returns redirects with a permanent redirect from oldPath to newPath added.
+// Redirects to oldPath are pointed to newPath, so chains collapse into a single hop, redirects
+// from newPath are dropped because the content lives there now, and an existing redirect from
+// oldPath is replaced.
+func AddPathRedirect(redirects []Redirect, oldPath, newPath string) []Redirect {
+	oldPath, newPath = normalizeRedirectPath(oldPath), normalizeRedirectPath(newPath)
+
+	result := make([]Redirect, 0, len(redirects)+1)
+	for _, redirect := range redirects {
+		from, literal := redirectFromPath(redirect.From)
+		if literal && (from == oldPath || from == newPath) {
+			continue
+		}
+		if normalizeRedirectPath(redirect.To) == oldPath {
+			redirect.To = newPath
+		}
+		if literal && from == normalizeRedirectPath(redirect.To) {
+			continue
+		}
+		result = append(result, redirect)
+	}
+	if oldPath == newPath {
+		return result
+	}
+	return append(result, Redirect{From: pathRedirectFrom(oldPath), To: newPath, Permanent: true})
+}
+
+// AdminAddPathRedirect downloads the redirects, adds a 301 from oldPath to newPath
+// with AddPathRedirect and uploads the result
+func (g *Ghost) AdminAddPathRedirect(ctx context.Context, oldPath, newPath string) error {
+	redirects, err := g.AdminGetRedirects(ctx)
+	if err != nil {
+		return err
+	}
+	return g.AdminUploadRedirects(ctx, AddPathRedirect(redirects, oldPath, newPath))
+}
+
+// pathRedirectFrom matches path with and without trailing slash
+func pathRedirectFrom(path string) string {
+	return "^" + regexp.QuoteMeta(strings.TrimSuffix(path, "/")) + "/?$"
+}
+
+var (
+	pathRedirectPattern = regexp.MustCompile(`^\^((?:[^\\^$.|?*+()\[\]{}]|\\.)*?)/\?\$$`)
+	regexpEscape        = regexp.MustCompile(`\\(.)`)
+)
+
+// redirectFromPath returns the path a redirect's From matches, literal is false for real patterns
+func redirectFromPath(from string) (path string, literal bool) {
+	if match := pathRedirectPattern.FindStringSubmatch(from); match != nil {
+		unquoted := regexpEscape.ReplaceAllString(match[1], "$1")
+		return normalizeRedirectPath(unquoted), true
+	}
+	if strings.HasPrefix(from, "/") && regexp.QuoteMeta(from) == from {
+		return normalizeRedirectPath(from), true
+	}
+	return "", false
+}
+
+func normalizeRedirectPath(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return path
+}
+
+// withSlugRedirect runs update and adds a redirect if it changed the published path
+func (g *Ghost) withSlugRedirect(resource, id, slug string, update func() error) error {
+	if !g.slugRedirects || slug == "" || id == "" {
+		return update()
+	}
+
+	stored, err := g.adminPublishedPath(resource, id)
+	if err != nil {
+		return err
+	}
+	if err := update(); err != nil {
+		return err
+	}
+	if stored.path == "" || stored.slug == slug {
+		return nil
+	}
+
+	updated, err := g.adminPublishedPath(resource, id)
+	if err != nil {
+		return fmt.Errorf("%s updated, but reading its new URL failed: %w", resource, err)
+	}
+	if updated.path == "" || updated.path == stored.path {
+		return nil
+	}
+	if err := g.AdminAddPathRedirect(context.Background(), stored.path, updated.path); err != nil {
+		return fmt.Errorf("%s updated, but adding the redirect from %s to %s failed: %w", resource, stored.path, updated.path, err)
+	}
+	return nil
+}
+
+type publishedPath struct {
+	slug string
+	path string // empty unless published
+}
+
+// adminPublishedPath returns the slug and the URL path of a post or page relative to the site
+func (g *Ghost) adminPublishedPath(resource, id string) (publishedPath, error) {
+	var response map[string][]struct {
+		Slug   string `json:"slug"`
+		URL    string `json:"url"`
+		Status string `json:"status"`
+	}
+	var uri = fmt.Sprintf("%s/ghost/api/v3/admin/%ss/%s/?fields=slug,url,status", g.url, resource, id)
+	if err := g.getJson(uri, &response); err != nil {
+		return publishedPath{}, err
+	}
+	items := response[resource+"s"]
+	if len(items) == 0 {
+		return publishedPath{}, fmt.Errorf("%s %s not found", resource, id)
+	}
+
+	item := items[0]
+	result := publishedPath{slug: item.Slug}
+	if item.Status != StatusPublished || item.URL == "" {
+		return result, nil
+	}
+	parsed, err := url.Parse(item.URL)
+	if err != nil {
+		return result, err
+	}
+	result.path = parsed.Path
+	// Ghost in a subdirectory: redirects are relative to the site
+	if site, err := url.Parse(g.url); err == nil && site.Path != "" && site.Path != "/" {
+		result.path = "/" + strings.TrimPrefix(strings.TrimPrefix(result.path, strings.TrimSuffix(site.Path, "/")), "/")
+	}
+	return result, nil
+}
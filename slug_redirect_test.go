@@ -0,0 +1,101 @@
+package ghost
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddPathRedirect(t *testing.T) {
+	tests := []struct {
+		name             string
+		redirects        []Redirect
+		oldPath, newPath string
+		want             []Redirect
+	}{
+		{
+			name:    "first redirect",
+			oldPath: "/launch/", newPath: "/launch-day/",
+			want: []Redirect{{From: "^/launch/?$", To: "/launch-day/", Permanent: true}},
+		},
+		{
+			name:    "paths are normalized",
+			oldPath: "blog/launch", newPath: "/blog/launch-day",
+			want: []Redirect{{From: `^/blog/launch/?$`, To: "/blog/launch-day/", Permanent: true}},
+		},
+		{
+			name:      "chain collapses",
+			redirects: []Redirect{{From: "^/a/?$", To: "/b/", Permanent: true}},
+			oldPath:   "/b/", newPath: "/c/",
+			want: []Redirect{
+				{From: "^/a/?$", To: "/c/", Permanent: true},
+				{From: "^/b/?$", To: "/c/", Permanent: true},
+			},
+		},
+		{
+			name:      "revert to the old slug",
+			redirects: []Redirect{{From: "^/a/?$", To: "/b/", Permanent: true}},
+			oldPath:   "/b/", newPath: "/a/",
+			want: []Redirect{{From: "^/b/?$", To: "/a/", Permanent: true}},
+		},
+		{
+			name: "revert after a chain",
+			redirects: []Redirect{
+				{From: "^/a/?$", To: "/c/", Permanent: true},
+				{From: "^/b/?$", To: "/c/", Permanent: true},
+			},
+			oldPath: "/c/", newPath: "/a/",
+			want: []Redirect{
+				{From: "^/b/?$", To: "/a/", Permanent: true},
+				{From: "^/c/?$", To: "/a/", Permanent: true},
+			},
+		},
+		{
+			name:      "redirect from the old path is replaced",
+			redirects: []Redirect{{From: "/a/", To: "/elsewhere/"}},
+			oldPath:   "/a/", newPath: "/b/",
+			want: []Redirect{{From: "^/a/?$", To: "/b/", Permanent: true}},
+		},
+		{
+			name: "unrelated redirects are kept",
+			redirects: []Redirect{
+				{From: "^/tag/(.*)/$", To: "/topic/$1/", Permanent: true},
+				{From: "/sale/", To: "/offers/"},
+				{From: "^/a-(.*)$", To: "https://old.example.com/a/"},
+			},
+			oldPath: "/a/", newPath: "/b/",
+			want: []Redirect{
+				{From: "^/tag/(.*)/$", To: "/topic/$1/", Permanent: true},
+				{From: "/sale/", To: "/offers/"},
+				{From: "^/a-(.*)$", To: "https://old.example.com/a/"},
+				{From: "^/a/?$", To: "/b/", Permanent: true},
+			},
+		},
+		{
+			name:      "patterns pointing to the old path follow",
+			redirects: []Redirect{{From: "^/archive/(.*)$", To: "/a/"}},
+			oldPath:   "/a/", newPath: "/b/",
+			want: []Redirect{
+				{From: "^/archive/(.*)$", To: "/b/"},
+				{From: "^/a/?$", To: "/b/", Permanent: true},
+			},
+		},
+		{
+			name:      "same path",
+			redirects: []Redirect{{From: "/sale/", To: "/offers/"}},
+			oldPath:   "/a", newPath: "/a/",
+			want: []Redirect{{From: "/sale/", To: "/offers/"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := AddPathRedirect(test.redirects, test.oldPath, test.newPath)
+			if !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("Unexpected redirects:\n%+v\nwant:\n%+v", got, test.want)
+			}
+			if err := ValidateRedirects(got); err != nil {
+				t.Fatalf("Invalid redirects: %s", err)
+			}
+		})
+	}
+}
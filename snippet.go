@@ -0,0 +1,180 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/sklinkert/ghost/lexical"
+	"github.com/sklinkert/ghost/mobiledoc"
+)
+
+type Snippets struct {
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Snippet is reusable content editors can insert into posts. Ghost 5 keeps a Lexical and a
+// Mobiledoc version, Ghost 4 only Mobiledoc.
+type Snippet struct {
+	ID        string `json:"id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	MobileDoc string `json:"mobiledoc,omitempty"`
+	Lexical   string `json:"lexical,omitempty"`
+	CreatedAt string `json:"created_at,omitempty"`
+	UpdatedAt string `json:"updated_at,omitempty"`
+}
+
+// SetLexical serializes doc into the snippet's Lexical field
+func (s *Snippet) SetLexical(doc *lexical.Document) error {
+	serialized, err := doc.String()
+	if err != nil {
+		return err
+	}
+	s.Lexical = serialized
+	return nil
+}
+
+// LexicalDocument parses the snippet's Lexical field
+func (s Snippet) LexicalDocument() (*lexical.Document, error) {
+	if s.Lexical == "" {
+		return nil, fmt.Errorf("snippet %s has no lexical content", s.Name)
+	}
+	return lexical.Parse(s.Lexical)
+}
+
+// SetMobiledoc serializes doc into the snippet's MobileDoc field
+func (s *Snippet) SetMobiledoc(doc *mobiledoc.Document) error {
+	serialized, err := doc.String()
+	if err != nil {
+		return err
+	}
+	s.MobileDoc = serialized
+	return nil
+}
+
+// MobiledocDocument parses the snippet's MobileDoc field
+func (s Snippet) MobiledocDocument() (*mobiledoc.Document, error) {
+	if s.MobileDoc == "" {
+		return nil, fmt.Errorf("snippet %s has no mobiledoc content", s.Name)
+	}
+	return mobiledoc.Parse(s.MobileDoc)
+}
+
+// snippetFormats are the content formats requested for snippets
+func (g *Ghost) snippetFormats() string {
+	if capabilities, ok := g.knownCapabilities(); ok && !capabilities.Lexical {
+		return "mobiledoc"
+	}
+	return "mobiledoc,lexical"
+}
+
+func (g *Ghost) AdminGetSnippets() (Snippets, error) {
+	var snippets Snippets
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/snippets/?limit=all&formats=%s", g.url, g.snippetFormats())
+
+	if err := g.getJson(url, &snippets); err != nil {
+		return snippets, err
+	}
+	return snippets, nil
+}
+
+func (g *Ghost) AdminGetSnippet(snippetId string) (Snippets, error) {
+	var snippets Snippets
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/snippets/%s/?formats=%s", g.url, snippetId, g.snippetFormats())
+
+	if err := g.getJson(url, &snippets); err != nil {
+		return snippets, err
+	}
+	return snippets, nil
+}
+
+func (g *Ghost) AdminCreateSnippet(snippet Snippet) (Snippets, error) {
+	var snippets Snippets
+	if snippet.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return snippets, err
+		}
+	}
+
+	data, err := json.Marshal(&Snippets{Snippets: []Snippet{snippet}})
+	if err != nil {
+		return snippets, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/snippets/?formats=%s", g.url, g.snippetFormats())
+	if err := g.postJson(url, data, &snippets); err != nil {
+		return snippets, err
+	}
+	return snippets, nil
+}
+
+func (g *Ghost) AdminUpdateSnippet(snippet Snippet) (Snippets, error) {
+	var snippets Snippets
+	if snippet.Lexical != "" {
+		if err := g.require(capabilityLexical); err != nil {
+			return snippets, err
+		}
+	}
+
+	data, err := json.Marshal(&Snippets{Snippets: []Snippet{snippet}})
+	if err != nil {
+		return snippets, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/snippets/%s/?formats=%s", g.url, snippet.ID, g.snippetFormats())
+	if err := g.putJson(url, data, &snippets); err != nil {
+		return snippets, err
+	}
+	return snippets, nil
+}
+
+func (g *Ghost) AdminDeleteSnippet(snippetId string) error {
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/snippets/%s/", g.url, snippetId)
+	return g.deleteRequest(url)
+}
+
+// AdminPushSnippets makes the site's snippets match desired by name: missing snippets are
+// created and snippets with different content updated. Other snippets are left alone, so
+// editors can keep their own. Running it again with the same input is a no-op.
+func (g *Ghost) AdminPushSnippets(desired []Snippet) ([]Snippet, error) {
+	existing, err := g.AdminGetSnippets()
+	if err != nil {
+		return nil, err
+	}
+	existingByName := map[string]Snippet{}
+	for _, snippet := range existing.Snippets {
+		existingByName[snippet.Name] = snippet
+	}
+
+	var result []Snippet
+	for _, snippet := range desired {
+		current, ok := existingByName[snippet.Name]
+		if !ok {
+			snippet.ID = ""
+			created, err := g.AdminCreateSnippet(snippet)
+			if err != nil {
+				return result, fmt.Errorf("create snippet %q: %w", snippet.Name, err)
+			}
+			result = append(result, created.Snippets...)
+			continue
+		}
+
+		if sameSnippetContent(snippet.Lexical, current.Lexical) && sameSnippetContent(snippet.MobileDoc, current.MobileDoc) {
+			result = append(result, current)
+			continue
+		}
+		snippet.ID = current.ID
+		snippet.UpdatedAt = current.UpdatedAt
+		updated, err := g.AdminUpdateSnippet(snippet)
+		if err != nil {
+			return result, fmt.Errorf("update snippet %q: %w", snippet.Name, err)
+		}
+		result = append(result, updated.Snippets...)
+	}
+	return result, nil
+}
+
+// sameSnippetContent reports whether the desired content is already on the site, an empty
+// desired format is not compared
+func sameSnippetContent(desired, current string) bool {
+	return desired == "" || jsonEqual([]byte(desired), []byte(current))
+}
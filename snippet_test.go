@@ -0,0 +1,133 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// snippetServer keeps snippets by id and records the writes and the requested formats
+type snippetServer struct {
+	t        *testing.T
+	snippets []Snippet
+	writes   []string
+	formats  []string
+}
+
+func (s *snippetServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/ghost/api/v3/admin")
+	s.formats = append(s.formats, r.URL.Query().Get("formats"))
+	if r.Method == http.MethodGet && path == "/snippets/" {
+		_ = json.NewEncoder(w).Encode(Snippets{Snippets: s.snippets})
+		return
+	}
+
+	write := r.Method + " " + path
+	s.writes = append(s.writes, write)
+	var request Snippets
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Snippets) != 1 {
+		s.t.Errorf("Unexpected body of %s: %v", write, err)
+		return
+	}
+	snippet := request.Snippets[0]
+
+	switch {
+	case r.Method == http.MethodPost && path == "/snippets/":
+		snippet.ID = fmt.Sprintf("s%d", len(s.snippets)+1)
+		s.snippets = append(s.snippets, snippet)
+	case r.Method == http.MethodPut && path == "/snippets/"+snippet.ID+"/":
+		for i := range s.snippets {
+			if s.snippets[i].ID == snippet.ID {
+				s.snippets[i] = snippet
+			}
+		}
+	default:
+		s.t.Errorf("Unexpected request: %s", write)
+	}
+	_ = json.NewEncoder(w).Encode(Snippets{Snippets: []Snippet{snippet}})
+}
+
+func TestAdminPushSnippets(t *testing.T) {
+	s := &snippetServer{t: t, snippets: []Snippet{
+		{ID: "s1", Name: "Footer", Lexical: `{"root":{"children":[],"direction":null,"type":"root"}}`, MobileDoc: `{"version":"0.3.1","sections":[]}`},
+		{ID: "s2", Name: "Signature", Lexical: `{"root":{"children":[],"type":"root","version":1}}`},
+		{ID: "s3", Name: "Editor's own", Lexical: `{"root":{"children":[],"type":"root"}}`},
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	desired := []Snippet{
+		// same content in another key order and formatting, the mobiledoc isn't compared
+		{Name: "Footer", Lexical: `{ "root": { "type": "root", "direction": null, "children": [] } }`},
+		{Name: "Signature", Lexical: `{"root":{"children":[{"type":"paragraph"}],"type":"root","version":1}}`},
+		{Name: "Call to action", Lexical: `{"root":{"children":[],"type":"root"}}`},
+	}
+	result, err := g.AdminPushSnippets(desired)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if strings.Join(s.writes, ", ") != "PUT /snippets/s2/, POST /snippets/" {
+		t.Fatalf("Unexpected writes: %v", s.writes)
+	}
+	if len(result) != 3 || result[0].ID != "s1" || result[1].ID != "s2" || result[2].ID != "s4" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+	if len(s.snippets) != 4 || s.snippets[2].Name != "Editor's own" {
+		t.Fatalf("Unexpected snippets: %+v", s.snippets)
+	}
+	for _, formats := range s.formats {
+		if formats != "mobiledoc,lexical" {
+			t.Fatalf("Unexpected formats %s", formats)
+		}
+	}
+
+	// a second push finds everything in place
+	s.writes = nil
+	if _, err := g.AdminPushSnippets(desired); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(s.writes) != 0 {
+		t.Fatalf("Expected no writes, got %v", s.writes)
+	}
+}
+
+func TestAdminPushSnippetsGhost4(t *testing.T) {
+	s := &snippetServer{t: t, snippets: []Snippet{
+		{ID: "s1", Name: "Footer", MobileDoc: `{"version":"0.3.1","sections":[]}`},
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+	g.SetVersion(Version{Major: 4, Minor: 48})
+
+	// Ghost 4 only knows Mobiledoc
+	desired := []Snippet{
+		{Name: "Footer", MobileDoc: `{"version":"0.3.1","sections":[[1,"p",[]]]}`},
+		{Name: "Call to action", MobileDoc: `{"version":"0.3.1","sections":[]}`},
+	}
+	if _, err := g.AdminPushSnippets(desired); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if strings.Join(s.writes, ", ") != "PUT /snippets/s1/, POST /snippets/" {
+		t.Fatalf("Unexpected writes: %v", s.writes)
+	}
+	for _, formats := range s.formats {
+		if formats != "mobiledoc" {
+			t.Fatalf("Unexpected formats %s", formats)
+		}
+	}
+
+	// Lexical content is refused before anything is sent
+	s.writes = nil
+	_, err := g.AdminPushSnippets([]Snippet{{Name: "New", Lexical: `{"root":{"children":[],"type":"root"}}`}})
+	if err == nil || !strings.Contains(err.Error(), "lexical") {
+		t.Fatalf("Expected a lexical capability error, got %v", err)
+	}
+	if len(s.writes) != 0 {
+		t.Fatalf("Expected no writes, got %v", s.writes)
+	}
+}
@@ -0,0 +1,303 @@
+package ghost
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type Themes struct {
+	Themes []Theme `json:"themes"`
+}
+
+type Theme struct {
+	Name      string          `json:"name"`
+	Package   *ThemePackage   `json:"package,omitempty"`
+	Active    bool            `json:"active"`
+	Templates []ThemeTemplate `json:"templates,omitempty"`
+	// Errors and Warnings are set after an upload, errors here are not fatal
+	Errors   []ThemeValidationIssue `json:"errors,omitempty"`
+	Warnings []ThemeValidationIssue `json:"warnings,omitempty"`
+}
+
+// ThemePackage is the theme's package.json
+type ThemePackage struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Version     string            `json:"version"`
+	Engines     map[string]string `json:"engines,omitempty"`
+	Config      json.RawMessage   `json:"config,omitempty"`
+}
+
+// ThemeTemplate is a custom template offered in the post settings
+type ThemeTemplate struct {
+	Filename string   `json:"filename"`
+	Name     string   `json:"name"`
+	For      []string `json:"for,omitempty"`
+	Slug     string   `json:"slug,omitempty"`
+}
+
+// ThemeValidationIssue is a failed gscan rule
+type ThemeValidationIssue struct {
+	Fatal    bool                     `json:"fatal"`
+	Level    string                   `json:"level"` // "error", "warning" or "recommendation"
+	Code     string                   `json:"code"`  // e.g. "GS010-PJ-REQ"
+	Rule     string                   `json:"rule"`  // HTML
+	Details  string                   `json:"details,omitempty"`
+	Failures []ThemeValidationFailure `json:"failures,omitempty"`
+}
+
+type ThemeValidationFailure struct {
+	Ref     string `json:"ref"` // file
+	Message string `json:"message,omitempty"`
+}
+
+var htmlTags = regexp.MustCompile(`<[^>]+>`)
+
+// String formats the issue on one line: "error GS010-PJ-REQ: rule (package.json, ...)"
+func (i ThemeValidationIssue) String() string {
+	var b strings.Builder
+	b.WriteString(i.Level)
+	if i.Fatal {
+		b.WriteString(" (fatal)")
+	}
+	if i.Code != "" {
+		b.WriteString(" " + i.Code)
+	}
+	b.WriteString(": " + strings.TrimSpace(htmlTags.ReplaceAllString(i.Rule, "")))
+
+	var refs []string
+	for _, failure := range i.Failures {
+		ref := failure.Ref
+		if failure.Message != "" {
+			ref += " " + failure.Message
+		}
+		refs = append(refs, ref)
+	}
+	if len(refs) > 0 {
+		b.WriteString(" (" + strings.Join(refs, ", ") + ")")
+	}
+	return b.String()
+}
+
+// ThemeValidationError is returned when Ghost rejects a theme on upload or activation
+type ThemeValidationError struct {
+	Message  string
+	Errors   []ThemeValidationIssue
+	Warnings []ThemeValidationIssue
+}
+
+func (e *ThemeValidationError) Error() string {
+	lines := []string{e.Message}
+	for _, issue := range e.Errors {
+		lines = append(lines, "  "+issue.String())
+	}
+	for _, issue := range e.Warnings {
+		lines = append(lines, "  "+issue.String())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AdminGetThemes lists the installed themes
+func (g *Ghost) AdminGetThemes() (Themes, error) {
+	var themes Themes
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/themes/", g.url)
+
+	if err := g.getJson(url, &themes); err != nil {
+		return themes, err
+	}
+	return themes, nil
+}
+
+// AdminUploadTheme uploads a zipped theme. Ghost takes the theme name from the file name,
+// e.g. "my-theme.zip", and replaces an installed theme of the same name.
+func (g *Ghost) AdminUploadTheme(ctx context.Context, name string, r io.Reader) (Theme, error) {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/themes/upload/", g.url)
+	if !strings.HasSuffix(name, ".zip") {
+		name += ".zip"
+	}
+
+	resp, err := g.uploadRequest(ctx, url, []uploadFile{{field: "file", name: name, contentType: "application/zip", r: r}}, nil)
+	if err != nil {
+		return Theme{}, err
+	}
+	return parseThemeResponse(resp)
+}
+
+// AdminUploadThemeDir zips the theme in dir while uploading it. The theme is named after the directory,
+// dot files and node_modules are left out.
+func (g *Ghost) AdminUploadThemeDir(ctx context.Context, dir string) (Theme, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return Theme{}, err
+	}
+	if _, err := os.Stat(absDir); err != nil {
+		return Theme{}, err
+	}
+
+	body, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(zipDir(pw, absDir))
+	}()
+	defer body.Close()
+
+	return g.AdminUploadTheme(ctx, filepath.Base(absDir), body)
+}
+
+func zipDir(w io.Writer, dir string) error {
+	archive := zip.NewWriter(w)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == dir {
+			return nil
+		}
+		if strings.HasPrefix(info.Name(), ".") || info.Name() == "node_modules" {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.IsDir() || !info.Mode().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(rel)
+		header.Method = zip.Deflate
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(entry, file)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return archive.Close()
+}
+
+// AdminActivateTheme makes the installed theme the active one
+func (g *Ghost) AdminActivateTheme(ctx context.Context, name string) (Theme, error) {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/themes/%s/activate/", g.url, name)
+
+	resp, err := g.adminRequest(ctx, http.MethodPut, url)
+	if err != nil {
+		return Theme{}, err
+	}
+	return parseThemeResponse(resp)
+}
+
+// AdminDownloadTheme writes the zipped theme to w
+func (g *Ghost) AdminDownloadTheme(ctx context.Context, name string, w io.Writer) error {
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/themes/%s/download/", g.url, name)
+	return g.download(ctx, url, w)
+}
+
+// AdminDeleteTheme removes an installed theme, the active theme can't be deleted
+func (g *Ghost) AdminDeleteTheme(name string) error {
+	return g.deleteRequest(fmt.Sprintf("%s/ghost/api/v3/admin/themes/%s/", g.url, name))
+}
+
+func (g *Ghost) adminRequest(ctx context.Context, method, url string) (*http.Response, error) {
+	if err := g.checkAndRenewJWT(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Ghost"+" "+g.jwtToken)
+	return g.client.Do(req)
+}
+
+// download copies the response body of a GET request to w
+func (g *Ghost) download(ctx context.Context, url string, w io.Writer) error {
+	resp, err := g.adminRequest(ctx, http.MethodGet, url)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		content, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(content))
+	}
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+// themeErrorResponse is Ghost's error body, gscan results are in the details of a ThemeValidationError
+type themeErrorResponse struct {
+	Errors []struct {
+		Message string `json:"message"`
+		Context string `json:"context"`
+		Type    string `json:"type"`
+		Details struct {
+			Errors   []ThemeValidationIssue `json:"errors"`
+			Warnings []ThemeValidationIssue `json:"warnings"`
+		} `json:"details"`
+	} `json:"errors"`
+}
+
+func parseThemeResponse(resp *http.Response) (Theme, error) {
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Theme{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		var errorResponse themeErrorResponse
+		if json.Unmarshal(content, &errorResponse) == nil {
+			for _, e := range errorResponse.Errors {
+				if e.Type == "ThemeValidationError" || len(e.Details.Errors) > 0 {
+					message := e.Message
+					if e.Context != "" {
+						message += " " + e.Context
+					}
+					return Theme{}, &ThemeValidationError{Message: message, Errors: e.Details.Errors, Warnings: e.Details.Warnings}
+				}
+			}
+		}
+		return Theme{}, fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(content))
+	}
+
+	var themes Themes
+	if err := json.Unmarshal(content, &themes); err != nil {
+		return Theme{}, err
+	}
+	if len(themes.Themes) == 0 {
+		return Theme{}, fmt.Errorf("no theme in response")
+	}
+	return themes.Themes[0], nil
+}
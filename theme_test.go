@@ -0,0 +1,130 @@
+package ghost
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAdminUploadThemeDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "my-theme")
+	files := map[string]string{
+		"package.json":                    `{"name":"my-theme","version":"1.0.0"}`,
+		"index.hbs":                       "{{!< default}}",
+		"partials/card.hbs":               "<article></article>",
+		".env":                            "SECRET=1",
+		".git/HEAD":                       "ref: refs/heads/main",
+		"node_modules/gscan/package.json": "{}",
+		"assets/.DS_Store":                "",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var entries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/ghost/api/v3/admin/themes/upload/" {
+			t.Errorf("Unexpected request: %s %s", r.Method, r.URL)
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Errorf("Missing file: %s", err)
+			return
+		}
+		if header.Filename != "my-theme.zip" || header.Header.Get("Content-Type") != "application/zip" {
+			t.Errorf("Unexpected file %s of type %s", header.Filename, header.Header.Get("Content-Type"))
+		}
+		data, _ := io.ReadAll(file)
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Errorf("Cannot read zip: %s", err)
+			return
+		}
+		for _, entry := range archive.File {
+			entries = append(entries, entry.Name)
+			if entry.Name == "partials/card.hbs" {
+				content, _ := entry.Open()
+				if data, _ := io.ReadAll(content); string(data) != files[entry.Name] {
+					t.Errorf("Unexpected content of %s: %q", entry.Name, data)
+				}
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = io.WriteString(w, `{"themes":[{"name":"my-theme","active":false,"package":{"name":"my-theme","version":"1.0.0"}}]}`)
+	}))
+	defer server.Close()
+
+	g := New(server.URL, "", testAdminKey)
+	theme, err := g.AdminUploadThemeDir(context.Background(), dir)
+	if err != nil {
+		t.Fatalf("Cannot upload theme: %s", err)
+	}
+	if theme.Name != "my-theme" || theme.Package == nil || theme.Package.Version != "1.0.0" {
+		t.Fatalf("Unexpected theme: %+v", theme)
+	}
+
+	sort.Strings(entries)
+	want := []string{"index.hbs", "package.json", "partials/card.hbs"}
+	if !reflect.DeepEqual(entries, want) {
+		t.Fatalf("Unexpected zip entries:\ngot  %v\nwant %v", entries, want)
+	}
+}
+
+func TestParseThemeResponseValidationError(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "theme-upload", "invalid.json"))
+	if err != nil {
+		t.Fatalf("Cannot read response: %s", err)
+	}
+	resp := &http.Response{StatusCode: http.StatusUnprocessableEntity, Body: io.NopCloser(bytes.NewReader(data))}
+
+	_, err = parseThemeResponse(resp)
+	var validationErr *ThemeValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected a ThemeValidationError, got %v", err)
+	}
+	if len(validationErr.Errors) != 2 || len(validationErr.Warnings) != 1 {
+		t.Fatalf("Unexpected issues: %+v", validationErr)
+	}
+	if first := validationErr.Errors[0]; !first.Fatal || first.Code != "GS010-PJ-REQ" || first.Failures[0].Ref != "package.json" {
+		t.Fatalf("Unexpected error: %+v", first)
+	}
+
+	lines := []string{
+		`Theme is not compatible or contains errors. The theme "broken.zip" is not compatible or contains errors.`,
+		"  error (fatal) GS010-PJ-REQ: package.json file should be present (package.json)",
+		"  error GS001-DEPR-AUTH: Replace {{author.bio}} with {{authors.[#].bio}} (post.hbs Please remove or replace {{author.bio}}, author.hbs)",
+		"  warning GS040-GH-REQ: The {{ghost_head}} helper should be present (default.hbs)",
+	}
+	if got := validationErr.Error(); got != strings.Join(lines, "\n") {
+		t.Fatalf("Unexpected message:\n%s", got)
+	}
+	if got := validationErr.Warnings[0].String(); got != strings.TrimSpace(lines[3]) {
+		t.Fatalf("Unexpected warning: %s", got)
+	}
+}
+
+func TestParseThemeResponseOtherError(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader(`{"errors":[{"message":"Theme does not exist.","type":"NotFoundError"}]}`))}
+
+	_, err := parseThemeResponse(resp)
+	var validationErr *ThemeValidationError
+	if err == nil || errors.As(err, &validationErr) || !strings.Contains(err.Error(), "404") {
+		t.Fatalf("Expected a plain error, got %v", err)
+	}
+}
@@ -0,0 +1,233 @@
+package ghost
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	themeIssueError   = "error"
+	themeIssueWarning = "warning"
+)
+
+const maxThemeCustomSettings = 20
+
+var (
+	themeNamePattern       = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	themeVersionPattern    = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+	themeSettingKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	hexColorPattern        = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+)
+
+// themePackageJSON is the part of package.json the local checks look at
+type themePackageJSON struct {
+	Name    *string           `json:"name"`
+	Version *string           `json:"version"`
+	Engines map[string]string `json:"engines"`
+	Config  *struct {
+		PostsPerPage interface{}                   `json:"posts_per_page"`
+		Custom       map[string]themeCustomSetting `json:"custom"`
+	} `json:"config"`
+}
+
+type themeCustomSetting struct {
+	Type        string      `json:"type"`
+	Options     []string    `json:"options"`
+	Default     interface{} `json:"default"`
+	Group       string      `json:"group"`
+	Description string      `json:"description"`
+}
+
+// ValidateThemeDir checks a theme directory before upload: package.json (name, version,
+// engines.ghost-api, config.posts_per_page and custom settings) and the required templates.
+// The issues use the codes of Ghost's gscan validator. err is a *ThemeValidationError if any
+// issue is an error, or the error reading the directory.
+func ValidateThemeDir(dir string) ([]ThemeValidationIssue, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	var issues []ThemeValidationIssue
+	for _, template := range []struct{ file, code string }{
+		{"index.hbs", "GS020-INDEX-REQ"},
+		{"post.hbs", "GS020-POST-REQ"},
+	} {
+		if _, err := os.Stat(filepath.Join(dir, template.file)); errors.Is(err, os.ErrNotExist) {
+			issues = append(issues, themeIssue(themeIssueError, true, template.code,
+				fmt.Sprintf("A template file called <code>%s</code> must be present", template.file), template.file))
+		} else if err != nil {
+			return issues, err
+		}
+	}
+
+	packageIssues, err := validateThemePackage(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return issues, err
+	}
+	issues = append(issues, packageIssues...)
+
+	return issues, themeIssuesError(issues)
+}
+
+func themeIssue(level string, fatal bool, code, rule, ref string) ThemeValidationIssue {
+	return ThemeValidationIssue{
+		Fatal:    fatal,
+		Level:    level,
+		Code:     code,
+		Rule:     rule,
+		Failures: []ThemeValidationFailure{{Ref: ref}},
+	}
+}
+
+func themeIssuesError(issues []ThemeValidationIssue) error {
+	validationErr := &ThemeValidationError{Message: "Theme is not compatible or contains errors."}
+	for _, issue := range issues {
+		if issue.Level == themeIssueError {
+			validationErr.Errors = append(validationErr.Errors, issue)
+		} else {
+			validationErr.Warnings = append(validationErr.Warnings, issue)
+		}
+	}
+	if len(validationErr.Errors) == 0 {
+		return nil
+	}
+	return validationErr
+}
+
+func validateThemePackage(path string) ([]ThemeValidationIssue, error) {
+	const ref = "package.json"
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []ThemeValidationIssue{themeIssue(themeIssueError, true, "GS010-PJ-REQ",
+			"<code>package.json</code> file should be present", ref)}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg themePackageJSON
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return []ThemeValidationIssue{themeIssue(themeIssueError, true, "GS010-PJ-PARSE",
+			"<code>package.json</code> file can be parsed: "+err.Error(), ref)}, nil
+	}
+
+	var issues []ThemeValidationIssue
+	add := func(level, code, rule string) {
+		issues = append(issues, themeIssue(level, false, code, rule, ref))
+	}
+
+	switch {
+	case pkg.Name == nil || *pkg.Name == "":
+		add(themeIssueError, "GS010-PJ-NAME-REQ", `<code>package.json</code> property <code>"name"</code> is required`)
+	case !themeNamePattern.MatchString(*pkg.Name):
+		add(themeIssueError, "GS010-PJ-NAME-LC", `<code>package.json</code> property <code>"name"</code> must be lowercase and hyphenated`)
+	}
+
+	switch {
+	case pkg.Version == nil || *pkg.Version == "":
+		add(themeIssueError, "GS010-PJ-VERSION-REQ", `<code>package.json</code> property <code>"version"</code> is required`)
+	case !themeVersionPattern.MatchString(*pkg.Version):
+		add(themeIssueError, "GS010-PJ-VERSION-SEM", `<code>package.json</code> property <code>"version"</code> must be semver compliant`)
+	}
+
+	if pkg.Engines["ghost-api"] == "" {
+		add(themeIssueError, "GS010-PJ-GHOST-API", `<code>package.json</code> property <code>"engines.ghost-api"</code> is required`)
+	}
+
+	if pkg.Config == nil || pkg.Config.PostsPerPage == nil {
+		add(themeIssueWarning, "GS010-PJ-CONF-PPP", `<code>package.json</code> property <code>"config.posts_per_page"</code> is recommended`)
+	} else if perPage, ok := pkg.Config.PostsPerPage.(float64); !ok || perPage < 1 || perPage != float64(int(perPage)) {
+		add(themeIssueError, "GS010-PJ-CONF-PPP-INT", `<code>package.json</code> property <code>"config.posts_per_page"</code> must be a number above 0`)
+	}
+
+	if pkg.Config != nil {
+		issues = append(issues, validateThemeCustomSettings(pkg.Config.Custom)...)
+	}
+	return issues, nil
+}
+
+func validateThemeCustomSettings(settings map[string]themeCustomSetting) []ThemeValidationIssue {
+	const ref = "package.json"
+
+	var issues []ThemeValidationIssue
+	add := func(code, key, rule string) {
+		issue := themeIssue(themeIssueError, false, code, rule, ref)
+		issue.Failures[0].Message = fmt.Sprintf("config.custom.%s", key)
+		issues = append(issues, issue)
+	}
+
+	if len(settings) > maxThemeCustomSettings {
+		issues = append(issues, themeIssue(themeIssueError, false, "GS010-PJ-CUST-THEME-TOTAL-SETTINGS",
+			fmt.Sprintf(`<code>package.json</code> objects defined in <code>"config.custom"</code> should not number more than %d`, maxThemeCustomSettings), ref))
+	}
+
+	var keys []string
+	for key := range settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		setting := settings[key]
+		if !themeSettingKeyPattern.MatchString(key) {
+			add("GS010-PJ-CUST-THEME-SETTINGS-CASE", key, "Custom setting keys must be snake_case")
+		}
+
+		switch setting.Group {
+		case "", "homepage", "post":
+		default:
+			add("GS010-PJ-CUST-THEME-SETTINGS-GROUP", key, `Custom setting <code>"group"</code> must be one of homepage or post`)
+		}
+
+		switch setting.Type {
+		case "select":
+			if len(setting.Options) < 2 {
+				add("GS010-PJ-CUST-THEME-SETTINGS-SELECT-OPTIONS", key, "Select settings need at least 2 options")
+			}
+			defaultValue, ok := setting.Default.(string)
+			if !ok || !containsString(setting.Options, defaultValue) {
+				add("GS010-PJ-CUST-THEME-SETTINGS-SELECT-DEFAULT", key, "Select settings need a default that is one of the options")
+			}
+		case "boolean":
+			if _, ok := setting.Default.(bool); !ok {
+				add("GS010-PJ-CUST-THEME-SETTINGS-BOOLEAN-DEFAULT", key, "Boolean settings need a default of true or false")
+			}
+		case "color":
+			if color, ok := setting.Default.(string); !ok || !hexColorPattern.MatchString(color) {
+				add("GS010-PJ-CUST-THEME-SETTINGS-COLOR-DEFAULT", key, "Color settings need a hex color default, e.g. #15171a")
+			}
+		case "image":
+			if setting.Default != nil {
+				add("GS010-PJ-CUST-THEME-SETTINGS-IMAGE-DEFAULT", key, "Image settings can't have a default")
+			}
+		case "text":
+			if _, ok := setting.Default.(string); setting.Default != nil && !ok {
+				add("GS010-PJ-CUST-THEME-SETTINGS-TEXT-DEFAULT", key, "Text settings need a text default")
+			}
+		default:
+			add("GS010-PJ-CUST-THEME-SETTINGS-TYPE", key,
+				fmt.Sprintf(`Custom setting type %q must be one of %s`, setting.Type, strings.Join([]string{"select", "boolean", "color", "image", "text"}, ", ")))
+		}
+	}
+	return issues
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
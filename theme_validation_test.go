@@ -0,0 +1,75 @@
+package ghost
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestValidateThemeDir(t *testing.T) {
+	tests := []struct {
+		theme    string
+		errors   []string
+		warnings []string
+	}{
+		{theme: "valid"},
+		{theme: "missing-templates", errors: []string{"GS020-INDEX-REQ", "GS020-POST-REQ"}},
+		{theme: "no-package", errors: []string{"GS010-PJ-REQ"}},
+		{theme: "broken-package", errors: []string{"GS010-PJ-PARSE"}},
+		{
+			theme:    "empty-package",
+			errors:   []string{"GS010-PJ-GHOST-API", "GS010-PJ-NAME-REQ", "GS010-PJ-VERSION-REQ"},
+			warnings: []string{"GS010-PJ-CONF-PPP"},
+		},
+		{theme: "bad-package", errors: []string{"GS010-PJ-CONF-PPP-INT", "GS010-PJ-NAME-LC", "GS010-PJ-VERSION-SEM"}},
+		{theme: "bad-settings", errors: []string{
+			"GS010-PJ-CUST-THEME-SETTINGS-BOOLEAN-DEFAULT",
+			"GS010-PJ-CUST-THEME-SETTINGS-CASE",
+			"GS010-PJ-CUST-THEME-SETTINGS-COLOR-DEFAULT",
+			"GS010-PJ-CUST-THEME-SETTINGS-GROUP",
+			"GS010-PJ-CUST-THEME-SETTINGS-IMAGE-DEFAULT",
+			"GS010-PJ-CUST-THEME-SETTINGS-SELECT-DEFAULT",
+			"GS010-PJ-CUST-THEME-SETTINGS-SELECT-OPTIONS",
+			"GS010-PJ-CUST-THEME-SETTINGS-TEXT-DEFAULT",
+			"GS010-PJ-CUST-THEME-SETTINGS-TYPE",
+		}},
+		{theme: "too-many-settings", errors: []string{"GS010-PJ-CUST-THEME-TOTAL-SETTINGS"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.theme, func(t *testing.T) {
+			issues, err := ValidateThemeDir(filepath.Join("testdata", "themes", test.theme))
+
+			var errorCodes, warningCodes []string
+			for _, issue := range issues {
+				if issue.Level == themeIssueError {
+					errorCodes = append(errorCodes, issue.Code)
+				} else {
+					warningCodes = append(warningCodes, issue.Code)
+				}
+			}
+			sort.Strings(errorCodes)
+			sort.Strings(warningCodes)
+			if !reflect.DeepEqual(errorCodes, test.errors) || !reflect.DeepEqual(warningCodes, test.warnings) {
+				t.Fatalf("Unexpected issues: %+v", issues)
+			}
+
+			var validationErr *ThemeValidationError
+			if len(test.errors) == 0 {
+				if err != nil {
+					t.Fatalf("Unexpected error: %s", err)
+				}
+			} else if !errors.As(err, &validationErr) || len(validationErr.Errors) != len(test.errors) {
+				t.Fatalf("Expected a *ThemeValidationError with %d errors, got %v", len(test.errors), err)
+			}
+		})
+	}
+}
+
+func TestValidateThemeDirNotFound(t *testing.T) {
+	if _, err := ValidateThemeDir(filepath.Join("testdata", "themes", "missing")); err == nil {
+		t.Fatal("Expected an error for a missing directory")
+	}
+}
@@ -0,0 +1,63 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type Tiers struct {
+	Tiers []Tier     `json:"tiers"`
+	Meta  Pagination `json:"meta,omitempty"`
+}
+
+type Tier struct {
+	Id             string     `json:"id,omitempty"`
+	Name           string     `json:"name"`
+	Slug           string     `json:"slug,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	Type           string     `json:"type,omitempty"` // "free" or "paid"
+	Active         bool       `json:"active,omitempty"`
+	Visibility     string     `json:"visibility,omitempty"` // "public" or "none"
+	WelcomePageURL string     `json:"welcome_page_url,omitempty"`
+	MonthlyPrice   int        `json:"monthly_price,omitempty"` // in the smallest unit of the currency, e.g. cents
+	YearlyPrice    int        `json:"yearly_price,omitempty"`
+	Currency       string     `json:"currency,omitempty"` // e.g. "usd"
+	TrialDays      int        `json:"trial_days,omitempty"`
+	Benefits       []string   `json:"benefits,omitempty"`
+	ExpiryAt       *time.Time `json:"expiry_at,omitempty"` // only set for complimentary access
+}
+
+// AdminGetTiers returns all tiers including archived ones
+func (g *Ghost) AdminGetTiers() (Tiers, error) {
+	var tiers Tiers
+	if err := g.require(capabilityTiers); err != nil {
+		return tiers, err
+	}
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/tiers/?limit=all&include=monthly_price,yearly_price,benefits", g.url)
+
+	if err := g.getJson(url, &tiers); err != nil {
+		return tiers, err
+	}
+	return tiers, nil
+}
+
+// AdminCreateTier creates a tier, prices of paid tiers are synced to Stripe by Ghost
+func (g *Ghost) AdminCreateTier(tier Tier) (Tiers, error) {
+	var tiers Tiers
+	if err := g.require(capabilityTiers); err != nil {
+		return tiers, err
+	}
+
+	tier.Id, tier.ExpiryAt = "", nil
+	data, err := json.Marshal(map[string][]Tier{"tiers": {tier}})
+	if err != nil {
+		return tiers, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/tiers/", g.url)
+	if err := g.postJson(url, data, &tiers); err != nil {
+		return tiers, err
+	}
+	return tiers, nil
+}
@@ -0,0 +1,145 @@
+package ghost
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"path/filepath"
+)
+
+// uploadFile is a file part of a multipart upload
+type uploadFile struct {
+	field       string
+	name        string
+	contentType string
+	r           io.Reader
+}
+
+// newUploadFile detects the content type unless one is given
+func newUploadFile(field, name, contentType string, r io.Reader) (uploadFile, error) {
+	if contentType == "" {
+		var err error
+		if contentType, r, err = detectContentType(name, r); err != nil {
+			return uploadFile{}, err
+		}
+	}
+	return uploadFile{field: field, name: name, contentType: contentType, r: r}, nil
+}
+
+// upload streams files and fields as multipart body to url without buffering the files in memory
+func (g *Ghost) upload(ctx context.Context, url string, files []uploadFile, fields map[string]string, target interface{}) error {
+	return g.uploadMethod(ctx, http.MethodPost, url, files, fields, target)
+}
+
+// uploadMethod uploads like upload with another HTTP method, e.g. PUT for media thumbnails
+func (g *Ghost) uploadMethod(ctx context.Context, method, url string, files []uploadFile, fields map[string]string, target interface{}) error {
+	resp, err := g.uploadRequestMethod(ctx, method, url, files, fields)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			fmt.Printf("Error closing body: %v\n", err)
+		}
+	}(resp.Body)
+
+	return parsePostResponse(resp, nil, target)
+}
+
+// uploadDiscard uploads like upload for endpoints whose response has no useful body
+func (g *Ghost) uploadDiscard(ctx context.Context, url string, files []uploadFile, fields map[string]string) error {
+	resp, err := g.uploadRequest(ctx, url, files, fields)
+	if err != nil {
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		_ = Body.Close()
+	}(resp.Body)
+
+	content, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("unexpected status code %d: %s", resp.StatusCode, string(content))
+	}
+	return nil
+}
+
+// uploadRequest sends the multipart request, the caller must close the response body
+func (g *Ghost) uploadRequest(ctx context.Context, url string, files []uploadFile, fields map[string]string) (*http.Response, error) {
+	return g.uploadRequestMethod(ctx, http.MethodPost, url, files, fields)
+}
+
+// uploadRequestMethod sends the multipart request with method
+func (g *Ghost) uploadRequestMethod(ctx context.Context, method, url string, files []uploadFile, fields map[string]string) (*http.Response, error) {
+	if err := g.checkAndRenewJWT(); err != nil {
+		return nil, err
+	}
+
+	body, pw := io.Pipe()
+	writer := multipart.NewWriter(pw)
+	go func() {
+		pw.CloseWithError(writeMultipart(writer, files, fields))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		// unblocks the writer
+		_ = body.Close()
+		return nil, err
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Ghost"+" "+g.jwtToken)
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		_ = body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func writeMultipart(writer *multipart.Writer, files []uploadFile, fields map[string]string) error {
+	for _, file := range files {
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Type", file.contentType)
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(file.field), escapeQuotes(file.name)))
+		part, err := writer.CreatePart(h)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(part, file.r); err != nil {
+			return err
+		}
+	}
+	for key, val := range fields {
+		if err := writer.WriteField(key, val); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// detectContentType uses the file extension, or sniffs the first bytes of r if the extension is unknown.
+// The returned reader yields the complete content.
+func detectContentType(name string, r io.Reader) (string, io.Reader, error) {
+	if contentType := mime.TypeByExtension(filepath.Ext(name)); contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			return mediaType, r, nil
+		}
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", nil, err
+	}
+	head = head[:n]
+	mediaType, _, _ := mime.ParseMediaType(http.DetectContentType(head))
+	return mediaType, io.MultiReader(bytes.NewReader(head), r), nil
+}
@@ -0,0 +1,34 @@
+package ghost
+
+import "fmt"
+
+type Users struct {
+	Users []User     `json:"users"`
+	Meta  Pagination `json:"meta,omitempty"`
+}
+
+// User is a staff user, users can only be invited from Ghost Admin and not created with the API
+type User struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Slug         string `json:"slug"`
+	Email        string `json:"email"`
+	ProfileImage string `json:"profile_image,omitempty"`
+	CoverImage   string `json:"cover_image,omitempty"`
+	Bio          string `json:"bio,omitempty"`
+	Website      string `json:"website,omitempty"`
+	Location     string `json:"location,omitempty"`
+	Status       string `json:"status"` // "active", "inactive" or "locked"
+	URL          string `json:"url,omitempty"`
+}
+
+// AdminGetUsers returns all staff users
+func (g *Ghost) AdminGetUsers() (Users, error) {
+	var users Users
+	var url = fmt.Sprintf("%s/ghost/api/v3/admin/users/?limit=all", g.url)
+
+	if err := g.getJson(url, &users); err != nil {
+		return users, err
+	}
+	return users, nil
+}
@@ -0,0 +1,148 @@
+package ghost
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsupported is returned when the detected Ghost version lacks a feature
+var ErrUnsupported = errors.New("not supported by this Ghost version")
+
+// Version is a Ghost version as reported by the site endpoint, e.g. "5.82"
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+// ParseVersion parses "5", "5.82" or "5.82.1", a pre-release suffix like "-rc.0" is ignored
+func ParseVersion(s string) (Version, error) {
+	var v Version
+	core := strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(core, "-+"); i >= 0 {
+		core = core[:i]
+	}
+
+	parts := strings.Split(core, ".")
+	if len(parts) > 3 {
+		return v, fmt.Errorf("invalid Ghost version %q", s)
+	}
+	numbers := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return v, fmt.Errorf("invalid Ghost version %q", s)
+		}
+		*numbers[i] = n
+	}
+	return v, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// AtLeast reports whether v is major.minor or newer
+func (v Version) AtLeast(major, minor int) bool {
+	return v.Major > major || v.Major == major && v.Minor >= minor
+}
+
+// Capabilities are the features of a Ghost version that change the API
+type Capabilities struct {
+	Version     Version
+	Lexical     bool // lexical content format, Ghost 5.54
+	Newsletters bool // multiple newsletters, Ghost 5
+	Tiers       bool // tiers instead of products, Ghost 5
+	Comments    bool // native comments, Ghost 5.9
+	Media       bool // audio, video and file uploads, Ghost 5
+}
+
+type capability struct {
+	name         string
+	major, minor int
+	has          func(Capabilities) bool
+}
+
+var (
+	capabilityLexical     = capability{"lexical", 5, 54, func(c Capabilities) bool { return c.Lexical }}
+	capabilityNewsletters = capability{"newsletters", 5, 0, func(c Capabilities) bool { return c.Newsletters }}
+	capabilityTiers       = capability{"tiers", 5, 0, func(c Capabilities) bool { return c.Tiers }}
+	capabilityMedia       = capability{"media uploads", 5, 0, func(c Capabilities) bool { return c.Media }}
+)
+
+// CapabilitiesOf returns the capabilities of Ghost version v
+func CapabilitiesOf(v Version) Capabilities {
+	return Capabilities{
+		Version:     v,
+		Lexical:     v.AtLeast(capabilityLexical.major, capabilityLexical.minor),
+		Newsletters: v.AtLeast(capabilityNewsletters.major, capabilityNewsletters.minor),
+		Tiers:       v.AtLeast(capabilityTiers.major, capabilityTiers.minor),
+		Comments:    v.AtLeast(5, 9), // informational, no API of this package depends on comments
+		Media:       v.AtLeast(capabilityMedia.major, capabilityMedia.minor),
+	}
+}
+
+// DetectVersion asks the site endpoint for the Ghost version and caches it. Until the version
+// is known, either by DetectVersion, Capabilities or SetVersion, the client assumes a current Ghost.
+func (g *Ghost) DetectVersion() (Version, error) {
+	site, err := g.AdminGetSite()
+	if err != nil {
+		return Version{}, err
+	}
+	version, err := ParseVersion(site.Version)
+	if err != nil {
+		return Version{}, err
+	}
+	g.SetVersion(version)
+	return version, nil
+}
+
+// SetVersion sets the Ghost version without asking the server
+func (g *Ghost) SetVersion(v Version) {
+	g.versionMu.Lock()
+	defer g.versionMu.Unlock()
+	g.version = &v
+}
+
+// Capabilities returns the capabilities of the server, the version is detected on the first call
+func (g *Ghost) Capabilities() (Capabilities, error) {
+	if capabilities, ok := g.knownCapabilities(); ok {
+		return capabilities, nil
+	}
+	version, err := g.DetectVersion()
+	if err != nil {
+		return Capabilities{}, err
+	}
+	return CapabilitiesOf(version), nil
+}
+
+// knownCapabilities returns the capabilities of the cached version without a request
+func (g *Ghost) knownCapabilities() (Capabilities, bool) {
+	g.versionMu.Lock()
+	defer g.versionMu.Unlock()
+	if g.version == nil {
+		return Capabilities{}, false
+	}
+	return CapabilitiesOf(*g.version), true
+}
+
+// require returns ErrUnsupported if the known version lacks the capability. An unknown version
+// passes on purpose: the client assumes a current Ghost instead of asking the site endpoint before
+// every call, and an older server rejects the request itself. Call DetectVersion first to fail early.
+func (g *Ghost) require(c capability) error {
+	capabilities, ok := g.knownCapabilities()
+	if !ok || c.has(capabilities) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s needs Ghost %d.%d, server runs %s", ErrUnsupported, c.name, c.major, c.minor, capabilities.Version)
+}
+
+// adminFormats are the content formats requested for posts and pages
+func (g *Ghost) adminFormats() string {
+	if capabilities, ok := g.knownCapabilities(); ok && !capabilities.Lexical {
+		return "html,mobiledoc"
+	}
+	return "html,lexical,mobiledoc"
+}
@@ -0,0 +1,87 @@
+package ghost
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		err  bool
+	}{
+		{in: "5", want: Version{Major: 5}},
+		{in: "5.82", want: Version{Major: 5, Minor: 82}},
+		{in: "5.82.1", want: Version{Major: 5, Minor: 82, Patch: 1}},
+		{in: " 5.82.1\n", want: Version{Major: 5, Minor: 82, Patch: 1}},
+		{in: "v5.82.1", want: Version{Major: 5, Minor: 82, Patch: 1}},
+		{in: "5.82.1-rc.0", want: Version{Major: 5, Minor: 82, Patch: 1}},
+		{in: "v6.0.0-alpha.1", want: Version{Major: 6}},
+		{in: "5.82.1+build.7", want: Version{Major: 5, Minor: 82, Patch: 1}},
+		{in: "", err: true},
+		{in: "v", err: true},
+		{in: "five", err: true},
+		{in: "5.x", err: true},
+		{in: "5.82.", err: true},
+		{in: "5.82.1.0", err: true},
+		{in: "-rc.0", err: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseVersion(test.in)
+		if test.err {
+			if err == nil {
+				t.Errorf("ParseVersion(%q): expected an error, got %+v", test.in, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseVersion(%q) = %+v, %v; want %+v", test.in, got, err, test.want)
+		}
+	}
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		version Version
+		want    Capabilities
+	}{
+		{Version{Major: 4, Minor: 48}, Capabilities{}},
+		{Version{Major: 5}, Capabilities{Newsletters: true, Tiers: true, Media: true}},
+		{Version{Major: 5, Minor: 9}, Capabilities{Newsletters: true, Tiers: true, Media: true, Comments: true}},
+		{Version{Major: 5, Minor: 53, Patch: 9}, Capabilities{Newsletters: true, Tiers: true, Media: true, Comments: true}},
+		{Version{Major: 5, Minor: 54}, Capabilities{Lexical: true, Newsletters: true, Tiers: true, Media: true, Comments: true}},
+		{Version{Major: 6}, Capabilities{Lexical: true, Newsletters: true, Tiers: true, Media: true, Comments: true}},
+	}
+
+	for _, test := range tests {
+		test.want.Version = test.version
+		if got := CapabilitiesOf(test.version); got != test.want {
+			t.Errorf("CapabilitiesOf(%s) = %+v, want %+v", test.version, got, test.want)
+		}
+	}
+}
+
+func TestRequire(t *testing.T) {
+	g := New("https://example.com", "", "")
+
+	// nothing is checked until the version is known
+	if err := g.require(capabilityLexical); err != nil {
+		t.Fatalf("Unexpected error for an unknown version: %s", err)
+	}
+	if formats := g.adminFormats(); formats != "html,lexical,mobiledoc" {
+		t.Fatalf("Unexpected formats for an unknown version: %s", formats)
+	}
+
+	g.SetVersion(Version{Major: 5, Minor: 40})
+	if err := g.require(capabilityTiers); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if err := g.require(capabilityLexical); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Expected ErrUnsupported, got %v", err)
+	}
+	if formats := g.adminFormats(); formats != "html,mobiledoc" {
+		t.Fatalf("Unexpected formats for Ghost 5.40: %s", formats)
+	}
+}
@@ -0,0 +1,196 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// WebhookEvent is the site event a webhook is triggered by
+type WebhookEvent string
+
+const (
+	WebhookSiteChanged WebhookEvent = "site.changed"
+
+	WebhookPostAdded           WebhookEvent = "post.added"
+	WebhookPostDeleted         WebhookEvent = "post.deleted"
+	WebhookPostEdited          WebhookEvent = "post.edited"
+	WebhookPostPublished       WebhookEvent = "post.published"
+	WebhookPostPublishedEdited WebhookEvent = "post.published.edited"
+	WebhookPostUnpublished     WebhookEvent = "post.unpublished"
+	WebhookPostScheduled       WebhookEvent = "post.scheduled"
+	WebhookPostUnscheduled     WebhookEvent = "post.unscheduled"
+	WebhookPostRescheduled     WebhookEvent = "post.rescheduled"
+	WebhookPostTagAttached     WebhookEvent = "post.tag.attached"
+	WebhookPostTagDetached     WebhookEvent = "post.tag.detached"
+	WebhookPageAdded           WebhookEvent = "page.added"
+	WebhookPageDeleted         WebhookEvent = "page.deleted"
+	WebhookPageEdited          WebhookEvent = "page.edited"
+	WebhookPagePublished       WebhookEvent = "page.published"
+	WebhookPagePublishedEdited WebhookEvent = "page.published.edited"
+	WebhookPageUnpublished     WebhookEvent = "page.unpublished"
+	WebhookPageScheduled       WebhookEvent = "page.scheduled"
+	WebhookPageUnscheduled     WebhookEvent = "page.unscheduled"
+	WebhookPageRescheduled     WebhookEvent = "page.rescheduled"
+	WebhookPageTagAttached     WebhookEvent = "page.tag.attached"
+	WebhookPageTagDetached     WebhookEvent = "page.tag.detached"
+	WebhookTagAdded            WebhookEvent = "tag.added"
+	WebhookTagEdited           WebhookEvent = "tag.edited"
+	WebhookTagDeleted          WebhookEvent = "tag.deleted"
+	WebhookMemberAdded         WebhookEvent = "member.added"
+	WebhookMemberEdited        WebhookEvent = "member.edited"
+	WebhookMemberDeleted       WebhookEvent = "member.deleted"
+)
+
+type Webhooks struct {
+	Webhooks []Webhook `json:"webhooks"`
+}
+
+type Webhook struct {
+	Id              string       `json:"id,omitempty"`
+	Event           WebhookEvent `json:"event,omitempty"`
+	TargetURL       string       `json:"target_url,omitempty"`
+	Name            string       `json:"name,omitempty"`
+	Secret          string       `json:"secret,omitempty"`
+	ApiVersion      string       `json:"api_version,omitempty"`
+	IntegrationId   string       `json:"integration_id,omitempty"`
+	Status          string       `json:"status,omitempty"`
+	LastTriggeredAt string       `json:"last_triggered_at,omitempty"`
+	CreatedAt       string       `json:"created_at,omitempty"`
+	UpdatedAt       string       `json:"updated_at,omitempty"`
+}
+
+type integrations struct {
+	Integrations []struct {
+		Id       string    `json:"id"`
+		Name     string    `json:"name"`
+		Webhooks []Webhook `json:"webhooks"`
+	} `json:"integrations"`
+}
+
+// AdminCreateWebhook registers a webhook. IntegrationId is only needed when the API key
+// belongs to a different integration than the one the webhook should be attached to.
+func (g *Ghost) AdminCreateWebhook(webhook Webhook) (Webhooks, error) {
+	var webhooks Webhooks
+
+	data, err := json.Marshal(&Webhooks{Webhooks: []Webhook{webhook}})
+	if err != nil {
+		return webhooks, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/webhooks/", g.url)
+	if err := g.postJson(url, data, &webhooks); err != nil {
+		return webhooks, err
+	}
+	return webhooks, nil
+}
+
+// webhookUpdate always sends the secret, so an update can remove it
+type webhookUpdate struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+// AdminUpdateWebhook replaces the webhook's settings, an empty Secret removes the secret
+func (g *Ghost) AdminUpdateWebhook(webhook Webhook) (Webhooks, error) {
+	var webhooks Webhooks
+
+	update := map[string][]webhookUpdate{"webhooks": {{Webhook: webhook, Secret: webhook.Secret}}}
+	data, err := json.Marshal(&update)
+	if err != nil {
+		return webhooks, err
+	}
+
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/webhooks/%s/", g.url, webhook.Id)
+	if err := g.putJson(url, data, &webhooks); err != nil {
+		return webhooks, err
+	}
+	return webhooks, nil
+}
+
+func (g *Ghost) AdminDeleteWebhook(webhookId string) error {
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/webhooks/%s/", g.url, webhookId)
+	return g.deleteRequest(url)
+}
+
+// AdminGetIntegrationWebhooks returns the webhooks attached to an integration
+func (g *Ghost) AdminGetIntegrationWebhooks(integrationId string) ([]Webhook, error) {
+	var result integrations
+	url := fmt.Sprintf("%s/ghost/api/v3/admin/integrations/%s/?include=webhooks", g.url, integrationId)
+
+	if err := g.getJson(url, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Integrations) == 0 {
+		return nil, fmt.Errorf("integration %s not found", integrationId)
+	}
+	return result.Integrations[0].Webhooks, nil
+}
+
+// AdminReconcileWebhooks makes the integration's webhooks match desired. Webhooks are matched by
+// event and target URL: missing ones are created, changed ones updated and all others deleted,
+// including duplicates of a matched webhook. Running it again with the same input is a no-op.
+// Two desired webhooks with the same event and target URL are rejected.
+func (g *Ghost) AdminReconcileWebhooks(integrationId string, desired []Webhook) ([]Webhook, error) {
+	webhookKey := func(w Webhook) string {
+		return string(w.Event) + " " + w.TargetURL
+	}
+	desiredKeys := map[string]bool{}
+	for _, webhook := range desired {
+		if desiredKeys[webhookKey(webhook)] {
+			return nil, fmt.Errorf("webhook %s is desired more than once", webhookKey(webhook))
+		}
+		desiredKeys[webhookKey(webhook)] = true
+	}
+
+	existing, err := g.AdminGetIntegrationWebhooks(integrationId)
+	if err != nil {
+		return nil, err
+	}
+
+	existingByKey := map[string][]Webhook{}
+	for _, webhook := range existing {
+		existingByKey[webhookKey(webhook)] = append(existingByKey[webhookKey(webhook)], webhook)
+	}
+
+	var result []Webhook
+	kept := map[string]bool{}
+	for _, webhook := range desired {
+		webhook.IntegrationId = integrationId
+
+		matches := existingByKey[webhookKey(webhook)]
+		if len(matches) == 0 {
+			created, err := g.AdminCreateWebhook(webhook)
+			if err != nil {
+				return result, fmt.Errorf("cannot create webhook %s: %w", webhookKey(webhook), err)
+			}
+			result = append(result, created.Webhooks...)
+			continue
+		}
+		current := matches[0]
+		kept[current.Id] = true
+
+		if current.Name == webhook.Name && current.Secret == webhook.Secret &&
+			(webhook.ApiVersion == "" || current.ApiVersion == webhook.ApiVersion) {
+			result = append(result, current)
+			continue
+		}
+
+		webhook.Id = current.Id
+		updated, err := g.AdminUpdateWebhook(webhook)
+		if err != nil {
+			return result, fmt.Errorf("cannot update webhook %s: %w", webhookKey(webhook), err)
+		}
+		result = append(result, updated.Webhooks...)
+	}
+
+	for _, webhook := range existing {
+		if kept[webhook.Id] {
+			continue
+		}
+		if err := g.AdminDeleteWebhook(webhook.Id); err != nil {
+			return result, fmt.Errorf("cannot delete webhook %s: %w", webhookKey(webhook), err)
+		}
+	}
+
+	return result, nil
+}
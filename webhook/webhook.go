@@ -0,0 +1,342 @@
+// Package webhook receives Ghost webhooks, verifies their signature and dispatches
+// the decoded payloads to typed callbacks.
+//
+// Ghost does not tell the receiver which event triggered a request, so every webhook
+// must be registered with a target URL that names its event. By default the handler
+// reads it from the "event" query parameter:
+//
+//	https://example.com/ghost-hook?event=post.published
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sklinkert/ghost"
+)
+
+// SignatureHeader is the header Ghost puts the payload signature in
+const SignatureHeader = "X-Ghost-Signature"
+
+// DefaultTolerance is the maximum accepted age of a signed request
+const DefaultTolerance = 5 * time.Minute
+
+const maxBodySize = 10 << 20
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrExpiredSignature = errors.New("webhook signature timestamp outside tolerance")
+	ErrReplayed         = errors.New("webhook request was already received")
+)
+
+type PostFunc func(ctx context.Context, current, previous ghost.Post) error
+type PageFunc func(ctx context.Context, current, previous ghost.Page) error
+type TagFunc func(ctx context.Context, current, previous ghost.Tag) error
+type MemberFunc func(ctx context.Context, current, previous ghost.Member) error
+type SiteFunc func(ctx context.Context) error
+
+// Handler is an http.Handler for Ghost webhooks. Register callbacks before serving requests.
+type Handler struct {
+	secret    []byte
+	tolerance time.Duration
+	now       func() time.Time
+	eventFunc func(r *http.Request) ghost.WebhookEvent
+
+	posts   map[ghost.WebhookEvent]PostFunc
+	pages   map[ghost.WebhookEvent]PageFunc
+	tags    map[ghost.WebhookEvent]TagFunc
+	members map[ghost.WebhookEvent]MemberFunc
+	site    SiteFunc
+
+	mu   sync.Mutex
+	seen map[string]time.Time // signatures received within the tolerance window
+}
+
+type Option func(h *Handler)
+
+// WithTolerance sets how old a signature may be before the request is rejected as a replay
+func WithTolerance(tolerance time.Duration) Option {
+	return func(h *Handler) {
+		h.tolerance = tolerance
+	}
+}
+
+// WithEventFunc overrides how the event name is derived from a request, e.g. from the URL path
+func WithEventFunc(eventFunc func(r *http.Request) ghost.WebhookEvent) Option {
+	return func(h *Handler) {
+		h.eventFunc = eventFunc
+	}
+}
+
+// WithClock replaces time.Now, mainly for tests
+func WithClock(now func() time.Time) Option {
+	return func(h *Handler) {
+		h.now = now
+	}
+}
+
+// NewHandler creates a handler verifying requests with the secret configured on the webhooks
+func NewHandler(secret string, opts ...Option) *Handler {
+	h := &Handler{
+		secret:    []byte(secret),
+		tolerance: DefaultTolerance,
+		now:       time.Now,
+		eventFunc: func(r *http.Request) ghost.WebhookEvent {
+			return ghost.WebhookEvent(r.URL.Query().Get("event"))
+		},
+		posts:   map[ghost.WebhookEvent]PostFunc{},
+		pages:   map[ghost.WebhookEvent]PageFunc{},
+		tags:    map[ghost.WebhookEvent]TagFunc{},
+		members: map[ghost.WebhookEvent]MemberFunc{},
+		seen:    map[string]time.Time{},
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+func (h *Handler) OnPost(event ghost.WebhookEvent, fn PostFunc)     { h.posts[event] = fn }
+func (h *Handler) OnPage(event ghost.WebhookEvent, fn PageFunc)     { h.pages[event] = fn }
+func (h *Handler) OnTag(event ghost.WebhookEvent, fn TagFunc)       { h.tags[event] = fn }
+func (h *Handler) OnMember(event ghost.WebhookEvent, fn MemberFunc) { h.members[event] = fn }
+func (h *Handler) OnSiteChanged(fn SiteFunc)                        { h.site = fn }
+
+func (h *Handler) OnPostAdded(fn PostFunc)       { h.OnPost(ghost.WebhookPostAdded, fn) }
+func (h *Handler) OnPostEdited(fn PostFunc)      { h.OnPost(ghost.WebhookPostEdited, fn) }
+func (h *Handler) OnPostDeleted(fn PostFunc)     { h.OnPost(ghost.WebhookPostDeleted, fn) }
+func (h *Handler) OnPostPublished(fn PostFunc)   { h.OnPost(ghost.WebhookPostPublished, fn) }
+func (h *Handler) OnPostUnpublished(fn PostFunc) { h.OnPost(ghost.WebhookPostUnpublished, fn) }
+func (h *Handler) OnPageAdded(fn PageFunc)       { h.OnPage(ghost.WebhookPageAdded, fn) }
+func (h *Handler) OnPageEdited(fn PageFunc)      { h.OnPage(ghost.WebhookPageEdited, fn) }
+func (h *Handler) OnPageDeleted(fn PageFunc)     { h.OnPage(ghost.WebhookPageDeleted, fn) }
+func (h *Handler) OnPagePublished(fn PageFunc)   { h.OnPage(ghost.WebhookPagePublished, fn) }
+func (h *Handler) OnPageUnpublished(fn PageFunc) { h.OnPage(ghost.WebhookPageUnpublished, fn) }
+func (h *Handler) OnTagAdded(fn TagFunc)         { h.OnTag(ghost.WebhookTagAdded, fn) }
+func (h *Handler) OnTagEdited(fn TagFunc)        { h.OnTag(ghost.WebhookTagEdited, fn) }
+func (h *Handler) OnTagDeleted(fn TagFunc)       { h.OnTag(ghost.WebhookTagDeleted, fn) }
+func (h *Handler) OnMemberAdded(fn MemberFunc)   { h.OnMember(ghost.WebhookMemberAdded, fn) }
+func (h *Handler) OnMemberEdited(fn MemberFunc)  { h.OnMember(ghost.WebhookMemberEdited, fn) }
+func (h *Handler) OnMemberDeleted(fn MemberFunc) { h.OnMember(ghost.WebhookMemberDeleted, fn) }
+
+// payload - Ghost sends {"post": {"current": {...}, "previous": {...}}}, the key depends on the resource
+type payload struct {
+	Post   *snapshot `json:"post"`
+	Page   *snapshot `json:"page"`
+	Tag    *snapshot `json:"tag"`
+	Member *snapshot `json:"member"`
+}
+
+type snapshot struct {
+	Current  json.RawMessage `json:"current"`
+	Previous json.RawMessage `json:"previous"`
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.verify(r.Header.Get(SignatureHeader), body); err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event := h.eventFunc(r)
+	if event == "" {
+		h.forget(r.Header.Get(SignatureHeader))
+		http.Error(w, "unknown webhook event", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), event, body); err != nil {
+		// Ghost retries failed deliveries with the same signature
+		h.forget(r.Header.Get(SignatureHeader))
+		var decodeErr *decodeError
+		if errors.As(err, &decodeErr) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string { return "cannot decode payload: " + e.err.Error() }
+func (e *decodeError) Unwrap() error { return e.err }
+
+func (h *Handler) dispatch(ctx context.Context, event ghost.WebhookEvent, body []byte) error {
+	if event == ghost.WebhookSiteChanged {
+		if h.site == nil {
+			return nil
+		}
+		return h.site(ctx)
+	}
+
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return &decodeError{err}
+	}
+
+	switch {
+	case p.Post != nil:
+		fn := h.posts[event]
+		if fn == nil {
+			return nil
+		}
+		var current, previous ghost.Post
+		if err := p.Post.decode(&current, &previous); err != nil {
+			return err
+		}
+		return fn(ctx, current, previous)
+	case p.Page != nil:
+		fn := h.pages[event]
+		if fn == nil {
+			return nil
+		}
+		var current, previous ghost.Page
+		if err := p.Page.decode(&current, &previous); err != nil {
+			return err
+		}
+		return fn(ctx, current, previous)
+	case p.Tag != nil:
+		fn := h.tags[event]
+		if fn == nil {
+			return nil
+		}
+		var current, previous ghost.Tag
+		if err := p.Tag.decode(&current, &previous); err != nil {
+			return err
+		}
+		return fn(ctx, current, previous)
+	case p.Member != nil:
+		fn := h.members[event]
+		if fn == nil {
+			return nil
+		}
+		var current, previous ghost.Member
+		if err := p.Member.decode(&current, &previous); err != nil {
+			return err
+		}
+		return fn(ctx, current, previous)
+	}
+
+	return &decodeError{fmt.Errorf("no post, page, tag or member in payload for %s", event)}
+}
+
+func (s *snapshot) decode(current, previous interface{}) error {
+	for _, part := range []struct {
+		raw    json.RawMessage
+		target interface{}
+	}{{s.Current, current}, {s.Previous, previous}} {
+		if len(part.raw) == 0 || string(part.raw) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(part.raw, part.target); err != nil {
+			return &decodeError{err}
+		}
+	}
+	return nil
+}
+
+func (h *Handler) verify(header string, body []byte) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+	now := h.now()
+	if err := VerifySignature(h.secret, header, body, now, h.tolerance); err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for signature, receivedAt := range h.seen {
+		if now.Sub(receivedAt) > h.tolerance {
+			delete(h.seen, signature)
+		}
+	}
+	if _, found := h.seen[header]; found {
+		return ErrReplayed
+	}
+	h.seen[header] = now
+	return nil
+}
+
+// forget releases a signature recorded by verify, so the request can be delivered again
+func (h *Handler) forget(header string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.seen, header)
+}
+
+// VerifySignature checks a "sha256=<hex>, t=<unix millis>" signature header. Ghost signs the
+// body followed by the timestamp with HMAC-SHA256. Signatures older or newer than tolerance are rejected.
+func VerifySignature(secret []byte, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var signature, timestamp string
+	for _, part := range strings.Split(header, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(part), "=")
+		if !found {
+			continue
+		}
+		switch key {
+		case "sha256":
+			signature = value
+		case "t":
+			timestamp = value
+		}
+	}
+	if signature == "" || timestamp == "" {
+		return ErrInvalidSignature
+	}
+
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	signedAt := time.Unix(0, millis*int64(time.Millisecond))
+	if age := now.Sub(signedAt); age > tolerance || age < -tolerance {
+		return ErrExpiredSignature
+	}
+
+	expected, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if !hmac.Equal(expected, Sign(secret, body, timestamp)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Sign computes the raw signature Ghost sends for body and timestamp (unix millis as string)
+func Sign(secret, body []byte, timestamp string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	mac.Write([]byte(timestamp))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,191 @@
+package webhook
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sklinkert/ghost"
+)
+
+const testSecret = "topsecret"
+
+func signedRequest(t *testing.T, event, body string, signedAt time.Time) *http.Request {
+	t.Helper()
+	timestamp := fmt.Sprintf("%d", signedAt.UnixNano()/int64(time.Millisecond))
+	signature := hex.EncodeToString(Sign([]byte(testSecret), []byte(body), timestamp))
+
+	req := httptest.NewRequest(http.MethodPost, "/hook?event="+event, strings.NewReader(body))
+	req.Header.Set(SignatureHeader, fmt.Sprintf("sha256=%s, t=%s", signature, timestamp))
+	return req
+}
+
+func TestPostPublished(t *testing.T) {
+	now := time.Now()
+	h := NewHandler(testSecret, WithClock(func() time.Time { return now }))
+
+	var gotCurrent, gotPrevious ghost.Post
+	h.OnPostPublished(func(ctx context.Context, current, previous ghost.Post) error {
+		gotCurrent, gotPrevious = current, previous
+		return nil
+	})
+
+	body := `{"post":{"current":{"id":"1","title":"Hello","status":"published"},"previous":{"status":"draft"}}}`
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "post.published", body, now))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Unexpected status %d: %s", rec.Code, rec.Body.String())
+	}
+	if gotCurrent.Title != "Hello" || gotCurrent.Status != ghost.StatusPublished {
+		t.Fatalf("Unexpected current post: %+v", gotCurrent)
+	}
+	if gotPrevious.Status != "draft" {
+		t.Fatalf("Unexpected previous post: %+v", gotPrevious)
+	}
+}
+
+func TestMemberAndTagPayloads(t *testing.T) {
+	now := time.Now()
+	h := NewHandler(testSecret, WithClock(func() time.Time { return now }))
+
+	var member ghost.Member
+	h.OnMemberAdded(func(ctx context.Context, current, previous ghost.Member) error {
+		member = current
+		return nil
+	})
+	var deletedTag ghost.Tag
+	h.OnTagDeleted(func(ctx context.Context, current, previous ghost.Tag) error {
+		deletedTag = previous
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "member.added", `{"member":{"current":{"email":"a@b.c"},"previous":{}}}`, now))
+	if rec.Code != http.StatusOK || member.Email != "a@b.c" {
+		t.Fatalf("Member not dispatched: %d %+v", rec.Code, member)
+	}
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "tag.deleted", `{"tag":{"current":{},"previous":{"slug":"news"}}}`, now))
+	if rec.Code != http.StatusOK || deletedTag.Slug != "news" {
+		t.Fatalf("Tag not dispatched: %d %+v", rec.Code, deletedTag)
+	}
+}
+
+func TestRejectsInvalidSignature(t *testing.T) {
+	now := time.Now()
+	h := NewHandler("other-secret", WithClock(func() time.Time { return now }))
+	h.OnPostPublished(func(ctx context.Context, current, previous ghost.Post) error {
+		t.Fatal("Callback must not be called")
+		return nil
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "post.published", `{"post":{"current":{}}}`, now))
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/hook?event=post.published", strings.NewReader(`{}`))
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected 401 without signature, got %d", rec.Code)
+	}
+}
+
+func TestRejectsReplays(t *testing.T) {
+	now := time.Now()
+	h := NewHandler(testSecret, WithClock(func() time.Time { return now }))
+	body := `{"post":{"current":{"id":"1"}}}`
+
+	old := signedRequest(t, "post.published", body, now.Add(-10*time.Minute))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, old)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected expired signature to be rejected, got %d", rec.Code)
+	}
+
+	first := signedRequest(t, "post.published", body, now)
+	second := signedRequest(t, "post.published", body, now)
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, first)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected first request to pass, got %d", rec.Code)
+	}
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, second)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("Expected replay to be rejected, got %d", rec.Code)
+	}
+}
+
+func TestCallbackError(t *testing.T) {
+	now := time.Now()
+	h := NewHandler(testSecret, WithClock(func() time.Time { return now }))
+	h.OnPageEdited(func(ctx context.Context, current, previous ghost.Page) error {
+		return errors.New("cache purge failed")
+	})
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, signedRequest(t, "page.edited", `{"page":{"current":{"id":"1"}}}`, now))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+}
+
+func TestRetryAfterCallbackError(t *testing.T) {
+	now := time.Now()
+	h := NewHandler(testSecret, WithClock(func() time.Time { return now }))
+	var calls int
+	h.OnPostPublished(func(ctx context.Context, current, previous ghost.Post) error {
+		calls++
+		if calls == 1 {
+			return errors.New("database unavailable")
+		}
+		return nil
+	})
+
+	body := `{"post":{"current":{"id":"1"}}}`
+	first := signedRequest(t, "post.published", body, now)
+	retry := signedRequest(t, "post.published", body, now)
+	replay := signedRequest(t, "post.published", body, now)
+
+	for _, step := range []struct {
+		req  *http.Request
+		code int
+	}{{first, http.StatusInternalServerError}, {retry, http.StatusOK}, {replay, http.StatusUnauthorized}} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, step.req)
+		if rec.Code != step.code {
+			t.Fatalf("Expected %d, got %d", step.code, rec.Code)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("Expected 2 callback calls, got %d", calls)
+	}
+}
+
+func TestVerifySignature(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	body := []byte(`{"post":{}}`)
+	timestamp := "1700000000000"
+	header := "sha256=" + hex.EncodeToString(Sign([]byte(testSecret), body, timestamp)) + ", t=" + timestamp
+
+	if err := VerifySignature([]byte(testSecret), header, body, now, time.Minute); err != nil {
+		t.Fatalf("Expected valid signature, got %s", err)
+	}
+	if err := VerifySignature([]byte(testSecret), header, []byte(`{}`), now, time.Minute); err != ErrInvalidSignature {
+		t.Fatalf("Expected ErrInvalidSignature for tampered body, got %v", err)
+	}
+	if err := VerifySignature([]byte(testSecret), "garbage", body, now, time.Minute); err != ErrInvalidSignature {
+		t.Fatalf("Expected ErrInvalidSignature for malformed header, got %v", err)
+	}
+}
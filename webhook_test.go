@@ -0,0 +1,155 @@
+package ghost
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// webhookServer keeps the webhooks of integration i1 and records the writes
+type webhookServer struct {
+	t        *testing.T
+	webhooks []Webhook
+	writes   []string
+	bodies   map[string]string
+	nextId   int
+}
+
+func (s *webhookServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/ghost/api/v3/admin")
+	if r.Method == http.MethodGet && path == "/integrations/i1/" {
+		var response integrations
+		response.Integrations = append(response.Integrations, struct {
+			Id       string    `json:"id"`
+			Name     string    `json:"name"`
+			Webhooks []Webhook `json:"webhooks"`
+		}{Id: "i1", Name: "CDN", Webhooks: s.webhooks})
+		_ = json.NewEncoder(w).Encode(response)
+		return
+	}
+
+	write := r.Method + " " + path
+	s.writes = append(s.writes, write)
+	if r.Method == http.MethodDelete {
+		for i, webhook := range s.webhooks {
+			if path == "/webhooks/"+webhook.Id+"/" {
+				s.webhooks = append(s.webhooks[:i], s.webhooks[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		}
+		s.t.Errorf("Unknown webhook: %s", write)
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
+		s.t.Errorf("Cannot decode %s: %s", write, err)
+	}
+	s.bodies[write] = string(raw)
+	var request Webhooks
+	if err := json.Unmarshal(raw, &request); err != nil || len(request.Webhooks) != 1 {
+		s.t.Errorf("Unexpected body of %s: %s", write, raw)
+		return
+	}
+	webhook := request.Webhooks[0]
+
+	switch {
+	case r.Method == http.MethodPost && path == "/webhooks/":
+		s.nextId++
+		webhook.Id = fmt.Sprintf("new%d", s.nextId)
+		s.webhooks = append(s.webhooks, webhook)
+	case r.Method == http.MethodPut:
+		found := false
+		for i := range s.webhooks {
+			if path == "/webhooks/"+s.webhooks[i].Id+"/" {
+				webhook.Id = s.webhooks[i].Id
+				s.webhooks[i] = webhook
+				found = true
+			}
+		}
+		if !found {
+			s.t.Errorf("Unknown webhook: %s", write)
+		}
+	default:
+		s.t.Errorf("Unexpected request: %s", write)
+	}
+	_ = json.NewEncoder(w).Encode(Webhooks{Webhooks: []Webhook{webhook}})
+}
+
+func TestAdminReconcileWebhooks(t *testing.T) {
+	s := &webhookServer{t: t, bodies: map[string]string{}, webhooks: []Webhook{
+		{Id: "w1", Event: WebhookPostPublished, TargetURL: "https://cdn.example.com/purge", Name: "Purge", Secret: "s3cr3t", IntegrationId: "i1"},
+		{Id: "w2", Event: WebhookPostPublished, TargetURL: "https://cdn.example.com/purge", Name: "Purge copy", IntegrationId: "i1"},
+		{Id: "w3", Event: WebhookSiteChanged, TargetURL: "https://cdn.example.com/purge", Name: "Old name", Secret: "old", IntegrationId: "i1"},
+		{Id: "w4", Event: WebhookPostPublished, TargetURL: "https://old.example.com/hook", IntegrationId: "i1"},
+	}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	desired := []Webhook{
+		{Event: WebhookPostPublished, TargetURL: "https://cdn.example.com/purge", Name: "Purge", Secret: "s3cr3t"},
+		{Event: WebhookSiteChanged, TargetURL: "https://cdn.example.com/purge", Name: "Site changed"},
+		{Event: WebhookPostPublished, TargetURL: "https://search.example.com/index", Name: "Index"},
+	}
+	result, err := g.AdminReconcileWebhooks("i1", desired)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expectedWrites := []string{
+		"PUT /webhooks/w3/",
+		"POST /webhooks/",
+		"DELETE /webhooks/w2/",
+		"DELETE /webhooks/w4/",
+	}
+	if strings.Join(s.writes, ", ") != strings.Join(expectedWrites, ", ") {
+		t.Fatalf("Unexpected writes: %v", s.writes)
+	}
+	if len(result) != 3 || result[0].Id != "w1" || result[1].Id != "w3" || result[2].Id != "new1" {
+		t.Fatalf("Unexpected result: %+v", result)
+	}
+
+	// the update clears the secret and the new webhook belongs to the integration
+	if body := s.bodies["PUT /webhooks/w3/"]; !strings.Contains(body, `"secret":""`) {
+		t.Fatalf("Update does not clear the secret: %s", body)
+	}
+	if body := s.bodies["POST /webhooks/"]; !strings.Contains(body, `"integration_id":"i1"`) {
+		t.Fatalf("Webhook created without the integration: %s", body)
+	}
+	if len(s.webhooks) != 3 || s.webhooks[1].Secret != "" || s.webhooks[1].Name != "Site changed" {
+		t.Fatalf("Unexpected webhooks: %+v", s.webhooks)
+	}
+
+	// a second run finds everything in place
+	s.writes = nil
+	if _, err := g.AdminReconcileWebhooks("i1", desired); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(s.writes) != 0 {
+		t.Fatalf("Expected no writes, got %v", s.writes)
+	}
+}
+
+func TestAdminReconcileWebhooksDuplicateDesired(t *testing.T) {
+	s := &webhookServer{t: t, bodies: map[string]string{}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	g := New(server.URL, "", testAdminKey)
+
+	desired := []Webhook{
+		{Event: WebhookPostPublished, TargetURL: "https://cdn.example.com/purge", Name: "Purge"},
+		{Event: WebhookPostPublished, TargetURL: "https://cdn.example.com/purge", Name: "Purge again"},
+	}
+	if _, err := g.AdminReconcileWebhooks("i1", desired); err == nil {
+		t.Fatal("Expected an error for duplicate webhooks")
+	}
+	if len(s.writes) != 0 {
+		t.Fatalf("Expected no writes, got %v", s.writes)
+	}
+}